@@ -6,11 +6,17 @@ import (
 	"fmt"
 	"log"
 	"time"
+
+	"hytale-launcher/internal/keyring"
 )
 
 // keyName is the keyring key name used for encrypting account data.
 const keyName = "3CA80030-8679-41AD-9E5C-09705C233580"
 
+func init() {
+	keyring.RegisterKey(keyName)
+}
+
 // Token represents OAuth authentication tokens for a user.
 type Token struct {
 	// AccessToken is the OAuth access token string.
@@ -9,19 +9,32 @@ import (
 
 // ReadFile reads and decrypts an account file from the given path.
 // The file is expected to be encrypted with the account encryption key.
+// If the file is corrupt, its backup is loaded instead.
 // Returns the deserialized Account and any error encountered.
 func ReadFile(filePath string) (*Account, error) {
-	data, err := crypto.ReadFile(filePath, keyName)
+	var acct *Account
+	err := crypto.LoadFile(filePath, keyName, func(data []byte) error {
+		loaded, err := Decode(filePath, data)
+		if err != nil {
+			return err
+		}
+		acct = loaded
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	acct := newAccount(filePath)
+	return acct, nil
+}
 
+// Decode parses plaintext account data, such as data exported from another
+// machine. The account is saved to filePath.
+func Decode(filePath string, data []byte) (*Account, error) {
+	acct := newAccount(filePath)
 	if err := json.Unmarshal(data, acct); err != nil {
 		return nil, fmt.Errorf("could not unmarshal account data: %w", err)
 	}
-
 	return acct, nil
 }
 
@@ -10,6 +10,7 @@ import (
 	"os"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/getsentry/sentry-go"
@@ -19,8 +20,21 @@ import (
 	"hytale-launcher/internal/appstate"
 	"hytale-launcher/internal/auth"
 	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/integrations"
 	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/javart"
+	"hytale-launcher/internal/jvmopts"
+	"hytale-launcher/internal/logview"
+	"hytale-launcher/internal/media"
+	"hytale-launcher/internal/mods"
 	"hytale-launcher/internal/net"
+	"hytale-launcher/internal/notifications"
+	"hytale-launcher/internal/playerprofile"
+	"hytale-launcher/internal/serverout"
+	"hytale-launcher/internal/servers"
+	"hytale-launcher/internal/settings"
+	"hytale-launcher/internal/skins"
+	"hytale-launcher/internal/snapshot"
 	"hytale-launcher/internal/throttle"
 	"hytale-launcher/internal/update"
 	"hytale-launcher/internal/updater"
@@ -55,6 +69,83 @@ type App struct {
 
 	// selectedChannel holds the name of the currently selected update channel.
 	selectedChannel *string
+
+	// servers is the stored list of multiplayer servers.
+	servers *servers.List
+
+	// pinger probes the stored servers for reachability and latency.
+	pinger *servers.Pinger
+
+	// serverPinger periodically runs the server status pinger.
+	serverPinger *throttle.Refresher
+
+	// Settings is the persistent launcher settings store.
+	Settings *settings.Store
+
+	// javaRuntimes holds user-added Java runtimes and the runtime selected for
+	// each target and client profile.
+	javaRuntimes *javart.Manager
+
+	// jvmOptions holds the user's per-target JVM memory and flag overrides.
+	jvmOptions *jvmopts.Store
+
+	// mods is the mod library and the mod sets of each target and client profile.
+	mods *mods.Manager
+
+	// snapshots stores deduplicated snapshots of UserData.
+	snapshots *snapshot.Store
+
+	// media indexes screenshots and recordings in UserData.
+	media *media.Index
+
+	// mediaWatcher polls the media folders for new captures.
+	mediaWatcher *throttle.Refresher
+
+	// logs indexes the launcher, server and client log files.
+	logs *logview.Service
+
+	// logTail emits new log entries while the log viewer is open.
+	logTail *throttle.Refresher
+
+	// logTailMu protects logTail.
+	logTailMu sync.Mutex
+
+	// playerProfiles holds the offline player profiles.
+	playerProfiles *playerprofile.Manager
+
+	// skins is the library of skins and cosmetic presets.
+	skins *skins.Library
+
+	// notifier routes notifications to the desktop and webhook backends.
+	notifier *notifications.Router
+
+	// integrations are the configured chat integrations.
+	integrations *integrations.Store
+
+	// outbox queues integration messages until they are delivered.
+	outbox *integrations.Outbox
+
+	// dispatcher delivers server events to the integrations.
+	dispatcher *integrations.Dispatcher
+
+	// outputParser parses the launcher-managed server's output.
+	outputParser *serverout.Parser
+
+	// outputMu protects outputParser.
+	outputMu sync.RWMutex
+
+	// players lists the players online on the launcher-managed server.
+	players *serverout.Players
+
+	// recovered lists the files loaded from their backup during this run.
+	recovered []RecoveredFile
+
+	// recoveredMu protects recovered.
+	recoveredMu sync.Mutex
+
+	// quitting is set once the launcher has asked Wails to quit, so the
+	// close-to-minimize behaviour does not swallow the quit.
+	quitting atomic.Bool
 }
 
 // New creates a new App instance.
@@ -73,12 +164,34 @@ func (a *App) init() error {
 		return fmt.Errorf("unable to create storage directory: %w", err)
 	}
 
+	// Report files that had to be restored from their backup.
+	a.initStorageRecovery()
+
+	// Load and apply the launcher settings.
+	a.initSettings()
+	a.initNotifications()
+
 	// Initialize the authentication controller.
 	a.Auth = new(auth.Controller)
 	if err := a.Auth.Init(); err != nil {
 		return fmt.Errorf("unable to initialize auth controller: %w", err)
 	}
 
+	// Load the Java runtime selections.
+	a.initJavaRuntimes()
+	a.initJVMOptions()
+	a.initPlayerProfiles()
+	a.initSkins()
+	a.initMods()
+	a.initSnapshots()
+	a.initMedia()
+	a.initLogs()
+
+	// Load the server list and start probing it.
+	a.initServerList()
+	a.initIntegrations()
+	a.initServerOutput()
+
 	// If user is already logged in, initialize their session.
 	// TODO: Temporarily disabled
 	// if profile := a.getCurrentProfile(); profile != nil {
@@ -124,6 +237,14 @@ func (a *App) Startup(ctx context.Context) {
 	}
 }
 
+// Shutdown is called by Wails when the application is closing.
+// It stops integration delivery so that queued messages are saved.
+func (a *App) Shutdown(ctx context.Context) {
+	if a.dispatcher != nil {
+		a.dispatcher.Stop()
+	}
+}
+
 // Emit sends an event to the frontend with the given name and arguments.
 // Events named "update:status" are not logged to avoid log spam.
 func (a *App) Emit(name string, args ...any) {
@@ -131,6 +252,10 @@ func (a *App) Emit(name string, args ...any) {
 		slog.Debug("emitting event", "name", name, "args", args)
 	}
 
+	// Nothing listens before Wails has started the frontend, as in tests.
+	if a.ctx == nil {
+		return
+	}
 	runtime.EventsEmit(a.ctx, name, args...)
 }
 
@@ -39,6 +39,14 @@ func (a *App) loadEnv(channel string) *appstate.State {
 		slog.Error("failed to load channel", "channel", channel, "error", err)
 	}
 
+	// A newer launcher's state is left untouched; tell the user to update.
+	if errors.Is(err, appstate.ErrNewerSchema) {
+		a.Emit("state:unsupported", map[string]interface{}{
+			"channel": channel,
+			"error":   err.Error(),
+		})
+	}
+
 	// If state doesn't exist, create a new one.
 	if state == nil {
 		state = appstate.New(channel)
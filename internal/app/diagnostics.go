@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	"hytale-launcher/internal/appstate"
+	"hytale-launcher/internal/build"
+	"hytale-launcher/internal/buildscan"
+	"hytale-launcher/internal/diag"
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/javart"
+	"hytale-launcher/internal/jvmopts"
+	"hytale-launcher/internal/logview"
+)
+
+// keepDiagnosticBundles is how many diagnostic bundles are kept on disk.
+const keepDiagnosticBundles = 5
+
+// SystemInfo is the system summary included in a diagnostic bundle.
+type SystemInfo struct {
+	OS              string            `json:"os"`
+	Arch            string            `json:"arch"`
+	GoOS            string            `json:"go_os"`
+	GoArch          string            `json:"go_arch"`
+	CPUs            int               `json:"cpus"`
+	LauncherVersion string            `json:"launcher_version"`
+	LauncherRelease string            `json:"launcher_release"`
+	LauncherBuild   int               `json:"launcher_build"`
+	GameVersion     string            `json:"game_version,omitempty"`
+	StorageFree     uint64            `json:"storage_free_bytes"`
+	Memory          *jvmopts.Memory   `json:"memory,omitempty"`
+	Java            map[string]string `json:"java"`
+}
+
+// CreateDiagnosticBundle writes a zip of logs, redacted launcher state,
+// installed builds and system information for support, and returns its path.
+func (a *App) CreateDiagnosticBundle() (string, error) {
+	dir := hytale.InStorageDir("diagnostics")
+	if err := ioutil.MkdirAll(dir); err != nil {
+		return "", fmt.Errorf("unable to create diagnostics directory: %w", err)
+	}
+	dest := filepath.Join(dir, "hytale-diagnostics-"+time.Now().Format("20060102-150405")+".zip")
+
+	slog.Info("creating diagnostic bundle", "path", dest)
+
+	bundle, err := diag.Create(dest, build.Version)
+	if err != nil {
+		return "", err
+	}
+	if err := a.collectDiagnostics(bundle); err != nil {
+		bundle.Abort()
+		slog.Error("failed to create diagnostic bundle", "error", err)
+		sentry.CaptureException(err)
+		return "", err
+	}
+	if err := bundle.Close(); err != nil {
+		slog.Error("failed to create diagnostic bundle", "error", err)
+		sentry.CaptureException(err)
+		return "", err
+	}
+
+	pruneDiagnosticBundles(dir)
+
+	manifest := bundle.Manifest()
+	slog.Info("created diagnostic bundle",
+		"path", dest,
+		"collected", len(manifest.Collected),
+		"omitted", len(manifest.Omitted),
+	)
+	a.Emit("diagnostics:created", map[string]interface{}{
+		"path":     dest,
+		"manifest": manifest,
+	})
+	return dest, nil
+}
+
+// collectDiagnostics adds everything to a diagnostic bundle.
+func (a *App) collectDiagnostics(bundle *diag.Bundle) error {
+	if err := collectLogs(bundle, a.logSources()); err != nil {
+		return err
+	}
+
+	for _, channel := range hytale.KnownChannels() {
+		name := path.Join("state", channel+".json")
+		state, err := appstate.Read(channel)
+		switch {
+		case errors.Is(err, appstate.ErrNotFound):
+			bundle.Omit(name, "no state for this channel")
+		case err != nil:
+			bundle.Omit(name, "failed to read state: "+err.Error())
+		default:
+			if err := bundle.AddJSON(name, state); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := bundle.AddJSON("builds.json", buildscan.ScanInstalledGames(true)); err != nil {
+		return err
+	}
+	return bundle.AddJSON("system.json", a.systemInfo())
+}
+
+// collectLogs adds the launcher, server, client and crash logs.
+func collectLogs(bundle *diag.Bundle, sources []logview.Source) error {
+	found := make(map[string]bool)
+	for _, src := range sources {
+		found[src.Kind] = true
+		name := strings.TrimSuffix(src.Name, ".gz")
+		if err := bundle.AddFile(path.Join("logs", src.Kind, name), src.Path); err != nil {
+			return err
+		}
+	}
+
+	for _, kind := range []string{logview.KindLauncher, logview.KindServer, logview.KindClient} {
+		if !found[kind] {
+			bundle.Omit(path.Join("logs", kind), "no log files found")
+		}
+	}
+	return nil
+}
+
+// systemInfo gathers the system summary for a diagnostic bundle.
+func (a *App) systemInfo() SystemInfo {
+	info := SystemInfo{
+		OS:              build.OS(),
+		Arch:            build.Arch(),
+		GoOS:            runtime.GOOS,
+		GoArch:          runtime.GOARCH,
+		CPUs:            runtime.NumCPU(),
+		LauncherVersion: build.Version,
+		LauncherRelease: build.Release,
+		LauncherBuild:   build.BuildNumber,
+		GameVersion:     a.GetGameVersion(),
+		Java:            make(map[string]string),
+	}
+
+	if free, err := ioutil.FreeSpace(hytale.StorageDir()); err == nil {
+		info.StorageFree = free
+	}
+	if mem, err := jvmopts.SystemMemory(); err == nil {
+		info.Memory = &mem
+	}
+
+	for _, target := range []string{javart.TargetClient, javart.TargetServer} {
+		rt, err := javart.Probe(context.Background(), a.javaPath(target), "")
+		if err != nil {
+			info.Java[target] = "unavailable: " + err.Error()
+			continue
+		}
+		info.Java[target] = strings.TrimSpace(fmt.Sprintf("%s (%s %s)", rt.Version, rt.Vendor, rt.Arch))
+	}
+	return info
+}
+
+// pruneDiagnosticBundles deletes all but the newest bundles in dir.
+func pruneDiagnosticBundles(dir string) {
+	matches, _ := filepath.Glob(filepath.Join(dir, "hytale-diagnostics-*.zip"))
+	if len(matches) <= keepDiagnosticBundles {
+		return
+	}
+
+	// Names embed the creation time, so they sort oldest first.
+	sort.Strings(matches)
+	for _, old := range matches[:len(matches)-keepDiagnosticBundles] {
+		if err := os.Remove(old); err != nil {
+			slog.Warn("failed to delete old diagnostic bundle", "path", old, "error", err)
+		}
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -20,14 +20,21 @@ import (
 	"hytale-launcher/internal/build"
 	"hytale-launcher/internal/buildscan"
 	"hytale-launcher/internal/deletex"
+	"hytale-launcher/internal/events"
 	"hytale-launcher/internal/extract"
 	"hytale-launcher/internal/hytale"
 	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/javart"
+	"hytale-launcher/internal/jvmopts"
+	"hytale-launcher/internal/mods"
 	"hytale-launcher/internal/net"
+	"hytale-launcher/internal/notifications"
 	"hytale-launcher/internal/pkg"
 	"hytale-launcher/internal/playerprofile"
 	"hytale-launcher/internal/repair"
+	"hytale-launcher/internal/serverout"
 	"hytale-launcher/internal/session"
+	"hytale-launcher/internal/snapshot"
 )
 
 // updatingMu protects the updating flag.
@@ -38,6 +45,9 @@ var updating bool
 var serverProcess *os.Process
 var serverMu sync.RWMutex
 
+// serverStopRequested is set by StopServer so the exit is not reported as a crash
+var serverStopRequested bool
+
 // isUpdating returns true if an update is currently in progress.
 func (a *App) isUpdating() bool {
 	updatingMu.RLock()
@@ -119,6 +129,34 @@ func (a *App) GetInstalledGameDirSizes() map[string]int64 {
 	return sizes
 }
 
+// GetBuildScan returns a detailed scan of the installed builds and of the
+// leftovers that CollectGarbage would remove.
+func (a *App) GetBuildScan() *buildscan.Report {
+	return buildscan.Scan()
+}
+
+// CollectGarbage removes orphaned builds, stale downloads and leftover
+// staging directories. With dryRun it only reports what would be removed.
+// It is refused during an update, which may be writing any of them.
+func (a *App) CollectGarbage(dryRun bool) (*buildscan.GCResult, error) {
+	if a.isUpdating() {
+		return nil, errors.New("cannot collect garbage while updating")
+	}
+
+	result := buildscan.CollectGarbage(dryRun)
+
+	slog.Info("collected storage garbage",
+		"dryRun", dryRun,
+		"removed", len(result.Removed),
+		"freed", result.Freed,
+		"failed", len(result.Failed),
+	)
+	if !dryRun {
+		a.Emit("storage:gc", result)
+	}
+	return result, nil
+}
+
 // getGameSession returns the current game session or creates a new one.
 func (a *App) getGameSession() *session.GameSession {
 	// In a real implementation, this would fetch the session from the API
@@ -265,7 +303,8 @@ func (a *App) CanDeleteUserData() bool {
 	return !a.isUpdating()
 }
 
-// DeleteUserData deletes all user data from the storage directory.
+// DeleteUserData deletes all user data from the storage directory, except
+// the UserData snapshots.
 func (a *App) DeleteUserData() error {
 	if !a.CanDeleteUserData() {
 		return errors.New("cannot delete user data while updating")
@@ -288,7 +327,9 @@ func (a *App) DeleteUserData() error {
 		})
 	}
 
-	if err := deletex.Dir(storageDir, reporter); err != nil {
+	// Keep the snapshots, so that deleted worlds can still be restored
+	keep := []string{hytale.InStorageDir(snapshotsDir)}
+	if err := deletex.DirExcept(storageDir, keep, reporter); err != nil {
 		sentry.CaptureException(err)
 		return err
 	}
@@ -305,9 +346,15 @@ func (a *App) GetLaunchAuthMode() string {
 	return "online"
 }
 
+// joinServerArg is the client argument that connects straight to a server.
+const joinServerArg = "--server"
+
 // LaunchGameRequest contains parameters for launching the game.
 type LaunchGameRequest struct {
 	PlayerName string `json:"playerName"`
+
+	// ServerID optionally selects a stored server to join on startup.
+	ServerID string `json:"serverId,omitempty"`
 }
 
 // LaunchGame launches the Hytale game with offline mode.
@@ -318,6 +365,16 @@ func (a *App) LaunchGame(req LaunchGameRequest) error {
 		return errors.New("player name is required")
 	}
 
+	// Get or create the profile and UUID for this player (deterministic based on player name)
+	profile, err := a.launchProfile(playerName)
+	if err != nil {
+		return err
+	}
+	playerUUID := profile.UUID
+
+	// Profiles match names case-insensitively; play as the profile's spelling.
+	playerName = profile.Name
+
 	// Save player name for next time
 	if err := a.savePlayerName(playerName); err != nil {
 		slog.Warn("failed to save player name", "error", err)
@@ -326,24 +383,29 @@ func (a *App) LaunchGame(req LaunchGameRequest) error {
 	// Define paths
 	gameExe := hytale.InStorageDir("package/game/latest/Client/HytaleClient.exe")
 	appDir := hytale.InStorageDir("package/game/latest")
-	userDir := hytale.InStorageDir("UserData")
-	javaExe := hytale.InStorageDir("package/jre/latest/bin/java.exe")
+	userDir := playerUserDir(profile)
+	javaExe := a.javaPath(javart.Key(javart.TargetClient, profile.Name))
 
 	// Create UserData folder if missing
 	if err := ioutil.MkdirAll(userDir); err != nil {
 		return err
 	}
 
-	// Generate or retrieve unique UUID for this player
-	profileManager := playerprofile.New(hytale.InStorageDir("player_profiles.json"))
-	if err := profileManager.Load(); err != nil {
-		slog.Warn("failed to load player profiles", "error", err)
+	// Protect worlds and settings before the game touches them
+	a.autoSnapshot(userDirectory{Profile: profile.Name, Rel: profile.UserDir}, snapshot.ReasonLaunch)
+
+	// Install the profile's mods, into its own user directory if it has one
+	modDir := hytale.InStorageDir(modDirs[mods.TargetClient])
+	if profile.UserDir != "" {
+		modDir = filepath.Join(userDir, "Mods")
+	}
+	if err := a.deployModsTo(mods.Key(mods.TargetClient, profile.Name), modDir); err != nil {
+		return fmt.Errorf("failed to install mods: %w", err)
 	}
 
-	// Get or create UUID for this player (deterministic based on player name)
-	playerUUID, err := profileManager.GetUUID(playerName)
-	if err != nil {
-		return fmt.Errorf("failed to generate player UUID: %w", err)
+	// Put the profile's skin where the client picks it up
+	if err := a.installProfileSkin(profile, userDir); err != nil {
+		slog.Warn("failed to install player skin", "player", playerName, "error", err)
 	}
 
 	// Build command arguments
@@ -356,15 +418,26 @@ func (a *App) LaunchGame(req LaunchGameRequest) error {
 		"--name", playerName,
 	}
 
+	// Join a stored server directly instead of landing in the main menu
+	if req.ServerID != "" {
+		server, err := a.servers.Get(req.ServerID)
+		if err != nil {
+			return fmt.Errorf("failed to find server %q: %w", req.ServerID, err)
+		}
+		args = append(args, joinServerArg, server.Address())
+	}
+
 	slog.Info("launching Hytale",
 		"exe", gameExe,
 		"playerName", playerName,
 		"userDir", userDir,
+		"server", req.ServerID,
 	)
 
 	// Create the command
 	cmd := exec.Command(gameExe, args...)
 	cmd.Dir = appDir
+	cmd.Env = javaOptionsEnviron(a.jvmArgs(jvmopts.Key(jvmopts.TargetClient, profile.Name)))
 
 	// Hide console window on Windows
 	cmd.SysProcAttr = &syscall.SysProcAttr{
@@ -379,17 +452,55 @@ func (a *App) LaunchGame(req LaunchGameRequest) error {
 
 	slog.Info("game process started successfully", "pid", cmd.Process.Pid)
 
+	// Only a game that actually started counts as joining the server
+	if req.ServerID != "" {
+		if err := a.servers.MarkJoined(req.ServerID); err != nil {
+			slog.Warn("failed to record server join", "server", req.ServerID, "error", err)
+		}
+	}
+
+	// Remember when this install was last played
+	if a.State != nil {
+		a.State.MarkLaunched("game", time.Now())
+		a.State.Save("game_launched")
+	}
+
 	// Emit event to frontend that game has launched
 	a.Emit("game:launched")
 
-	// Minimize launcher window after game starts
-	a.minimizeLauncher()
+	// Minimize or close the launcher window as configured
+	a.afterGameLaunch()
 
 	return nil
 }
 
-// GetPlayerName returns the saved player name.
+// launchProfile returns the profile to launch as playerName, creating it if
+// needed. Only new names must pass ValidateName: profiles and saved names
+// from before the name rules keep working.
+func (a *App) launchProfile(playerName string) (*playerprofile.PlayerProfile, error) {
+	if profile := a.playerProfiles.GetProfile(playerName); profile != nil {
+		return profile, nil
+	}
+
+	if saved, _ := a.loadPlayerName(); saved != playerName {
+		if err := playerprofile.ValidateName(playerName); err != nil {
+			return nil, err
+		}
+	}
+
+	profile, err := a.playerProfiles.GetOrCreateProfile(playerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate player UUID: %w", err)
+	}
+	return profile, nil
+}
+
+// GetPlayerName returns the default player profile's name, or the name
+// saved at the last launch.
 func (a *App) GetPlayerName() string {
+	if profile := a.playerProfiles.Default(); profile != nil {
+		return profile.Name
+	}
 	name, _ := a.loadPlayerName()
 	return name
 }
@@ -397,17 +508,23 @@ func (a *App) GetPlayerName() string {
 // savePlayerName saves the player name to a file.
 func (a *App) savePlayerName(name string) error {
 	configPath := hytale.InStorageDir("player.txt")
-	return os.WriteFile(configPath, []byte(name), 0644)
+	return ioutil.WriteFileAtomic(configPath, []byte(name), 0644)
 }
 
-// loadPlayerName loads the player name from a file.
+// loadPlayerName loads the player name from a file, falling back to its
+// backup if the file is missing or empty.
 func (a *App) loadPlayerName() (string, error) {
 	configPath := hytale.InStorageDir("player.txt")
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return "", err
-	}
-	return string(data), nil
+
+	var name string
+	err := ioutil.LoadWithBackup(configPath, func(data []byte) error {
+		if len(data) == 0 {
+			return errors.New("player name file is empty")
+		}
+		name = string(data)
+		return nil
+	})
+	return name, err
 }
 
 // IsGameInstalled checks if the game is installed.
@@ -419,15 +536,12 @@ func (a *App) IsGameInstalled() bool {
 
 // findGameArchive finds the game ZIP archive next to the launcher executable.
 func (a *App) findGameArchive() (string, error) {
-	// Get launcher executable path
-	exePath, err := os.Executable()
+	// Get directory containing the launcher
+	launcherDir, err := hytale.ExecutableDir()
 	if err != nil {
 		return "", err
 	}
 
-	// Get directory containing the launcher
-	launcherDir := filepath.Dir(exePath)
-
 	// Look for .zip file in the launcher directory
 	files, err := os.ReadDir(launcherDir)
 	if err != nil {
@@ -453,6 +567,9 @@ func (a *App) InstallGame() error {
 
 	slog.Info("installing game from archive", "archive", archivePath)
 
+	// Protect worlds and settings before the game files change
+	a.autoSnapshotAll(snapshot.ReasonUpdate)
+
 	// Get destination directory
 	destDir := hytale.StorageDir()
 
@@ -496,7 +613,7 @@ func (a *App) StartServer() error {
 	serverJar := hytale.InStorageDir("package/game/latest/Server/HytaleServer.jar")
 	serverDir := hytale.InStorageDir("package/game/latest/Server")
 	assetsZip := "../Assets.zip"
-	javaExe := hytale.InStorageDir("package/jre/latest/bin/java.exe")
+	javaExe := a.javaPath(javart.TargetServer)
 	logFilePath := hytale.InStorageDir("server.log")
 
 	// Check if server exists
@@ -509,12 +626,18 @@ func (a *App) StartServer() error {
 		return errors.New("Java runtime not found")
 	}
 
-	// Build command arguments
-	args := []string{
+	// Install the enabled mods
+	if err := a.deployMods(mods.TargetServer); err != nil {
+		return fmt.Errorf("failed to install mods: %w", err)
+	}
+
+	// Build command arguments, JVM options first
+	args := a.jvmArgs(jvmopts.TargetServer)
+	args = append(args,
 		"-jar", serverJar,
 		"--assets", assetsZip,
 		"--auth-mode", "offline",
-	}
+	)
 
 	slog.Info("starting Hytale server",
 		"jar", serverJar,
@@ -549,10 +672,13 @@ func (a *App) StartServer() error {
 	}
 
 	serverProcess = cmd.Process
+	serverStopRequested = false
 	slog.Info("server process started", "pid", serverProcess.Pid)
 
 	// Emit "starting" event
+	a.resetServerPlayers()
 	a.Emit("server:starting")
+	a.publish(events.ServerStarting, nil)
 
 	// Monitor server output in background
 	go a.monitorServerOutput(stdout, stderr, logFilePath, cmd)
@@ -587,12 +713,15 @@ func (a *App) monitorServerOutput(stdout, stderr io.ReadCloser, logFilePath stri
 			if logFile != nil {
 				logFile.WriteString(line + "\n")
 			}
+			entry, _ := a.parseServerLine(line, "stdout")
 
 			// Check if server has booted
-			if !serverBooted && strings.Contains(line, "Hytale Server Booted!") {
+			if !serverBooted && entry.Event == serverout.EventReady {
 				serverBooted = true
 				slog.Info("server has fully booted")
 				a.Emit("server:ready")
+				a.publish(events.ServerReady, nil)
+				a.notify(events.ServerReady, notifications.TypeSuccess, "Server ready", "The Hytale server has finished booting.")
 				bootCheckDone <- true
 			}
 		}
@@ -608,6 +737,7 @@ func (a *App) monitorServerOutput(stdout, stderr io.ReadCloser, logFilePath stri
 			if logFile != nil {
 				logFile.WriteString("[ERROR] " + line + "\n")
 			}
+			a.parseServerLine(line, "stderr")
 		}
 	}()
 
@@ -621,6 +751,8 @@ func (a *App) monitorServerOutput(stdout, stderr io.ReadCloser, logFilePath stri
 			if !serverBooted {
 				slog.Warn("server boot timeout - server may not have started properly")
 				a.Emit("server:boot_timeout")
+				a.publish(events.ServerBootTimeout, nil)
+				a.notify(events.ServerBootTimeout, notifications.TypeWarning, "Server not ready", "The Hytale server has not finished booting after 60 seconds.")
 			}
 		}
 	}()
@@ -630,13 +762,18 @@ func (a *App) monitorServerOutput(stdout, stderr io.ReadCloser, logFilePath stri
 
 	serverMu.Lock()
 	serverProcess = nil
+	stopRequested := serverStopRequested
 	serverMu.Unlock()
 
+	a.resetServerPlayers()
+
 	if err != nil {
 		slog.Error("server process error", "error", err)
 		a.Emit("server:stopped", map[string]interface{}{
 			"error": err.Error(),
 		})
+		a.notify(events.ServerCrashed, notifications.TypeError, "Server crashed", err.Error())
+		a.publish(events.ServerCrashed, map[string]string{"error": err.Error()})
 	} else {
 		exitCode := 0
 		if state != nil {
@@ -646,6 +783,13 @@ func (a *App) monitorServerOutput(stdout, stderr io.ReadCloser, logFilePath stri
 		a.Emit("server:stopped", map[string]interface{}{
 			"exitCode": exitCode,
 		})
+		if exitCode != 0 && !stopRequested {
+			a.notify(events.ServerCrashed, notifications.TypeError, "Server crashed",
+				fmt.Sprintf("The Hytale server exited with code %d. See server.log for details.", exitCode))
+			a.publish(events.ServerCrashed, map[string]string{"exit_code": strconv.Itoa(exitCode)})
+		} else {
+			a.publish(events.ServerStopped, nil)
+		}
 	}
 }
 
@@ -661,6 +805,7 @@ func (a *App) StopServer() error {
 	slog.Info("stopping server process", "pid", serverProcess.Pid)
 
 	// Kill the server process
+	serverStopRequested = true
 	if err := serverProcess.Kill(); err != nil {
 		return fmt.Errorf("failed to stop server: %w", err)
 	}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/integrations"
+)
+
+// initIntegrations loads the chat integrations and starts delivering their
+// outbox.
+func (a *App) initIntegrations() {
+	a.integrations = integrations.NewStore(hytale.InStorageDir("integrations.json"))
+	if err := a.integrations.Load(); err != nil {
+		slog.Warn("failed to load integrations", "error", err)
+	}
+
+	outbox := integrations.NewOutbox(hytale.InStorageDir("integrations_outbox.json"))
+	if err := outbox.Load(); err != nil {
+		slog.Warn("failed to load integration outbox", "error", err)
+	}
+	a.outbox = outbox
+
+	a.dispatcher = integrations.NewDispatcher(a.integrations, outbox, nil)
+	a.dispatcher.Start()
+}
+
+// publish hands a server event to the integrations.
+func (a *App) publish(event string, fields map[string]string) {
+	if a.dispatcher == nil {
+		return
+	}
+	a.dispatcher.Publish(integrations.NewEvent(event, fields))
+}
+
+// GetIntegrations returns the configured chat integrations.
+func (a *App) GetIntegrations() []integrations.Integration {
+	return a.integrations.List()
+}
+
+// AddIntegration adds a chat integration.
+func (a *App) AddIntegration(i integrations.Integration) (integrations.Integration, error) {
+	added, err := a.integrations.Add(i)
+	if err != nil {
+		return integrations.Integration{}, err
+	}
+
+	slog.Info("integration added", "id", added.ID, "kind", added.Kind, "name", added.Name)
+	return added, nil
+}
+
+// UpdateIntegration replaces a chat integration's settings.
+func (a *App) UpdateIntegration(i integrations.Integration) (integrations.Integration, error) {
+	return a.integrations.Update(i)
+}
+
+// RemoveIntegration removes a chat integration. Its queued messages are
+// dropped.
+func (a *App) RemoveIntegration(id string) error {
+	slog.Info("removing integration", "id", id)
+	return a.integrations.Remove(id)
+}
+
+// TestIntegration posts a sample message to an integration right away.
+func (a *App) TestIntegration(id string) error {
+	return a.dispatcher.Test(context.Background(), id)
+}
+
+// GetIntegrationOutbox returns the messages still waiting for delivery.
+func (a *App) GetIntegrationOutbox() []integrations.Message {
+	return a.outbox.List()
+}
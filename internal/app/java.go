@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/javart"
+)
+
+// initJavaRuntimes loads the user's Java runtime configuration.
+func (a *App) initJavaRuntimes() {
+	a.javaRuntimes = javart.NewManager(hytale.InStorageDir("java_runtimes.json"))
+	if err := a.javaRuntimes.Load(); err != nil {
+		slog.Warn("failed to load java runtime config", "error", err)
+	}
+}
+
+// bundledJavaPath returns the java executable of the bundled runtime.
+func bundledJavaPath() string {
+	return javart.BinaryIn(hytale.InStorageDir("package/jre/latest"))
+}
+
+// javaPath returns the java executable to use for a launch target or
+// profile, given as a javart selection key. It falls back to the bundled
+// runtime when nothing is selected or the selected runtime has disappeared.
+func (a *App) javaPath(key string) string {
+	if selected := a.javaRuntimes.Selected(key); selected != "" {
+		if _, err := os.Stat(selected); err == nil {
+			return selected
+		}
+		slog.Warn("selected java runtime is missing, using bundled runtime",
+			"selection", key,
+			"java", selected,
+		)
+	}
+	return bundledJavaPath()
+}
+
+// GetJavaRuntimes discovers installed Java runtimes, including the bundled
+// one and any added by the user, and checks each against the game's requirements.
+func (a *App) GetJavaRuntimes() []javart.Runtime {
+	extra := map[string]string{
+		bundledJavaPath(): javart.SourceBundled,
+	}
+	for _, bin := range a.javaRuntimes.Custom() {
+		extra[bin] = javart.SourceCustom
+	}
+
+	return javart.Discover(context.Background(), extra)
+}
+
+// AddJavaRuntime adds a runtime by java executable or Java home directory.
+func (a *App) AddJavaRuntime(path string) (*javart.Runtime, error) {
+	rt, err := a.javaRuntimes.AddCustom(context.Background(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("added java runtime", "bin", rt.Path, "version", rt.Version, "compatible", rt.Compatible)
+	return rt, nil
+}
+
+// RemoveJavaRuntime forgets a runtime added by the user.
+func (a *App) RemoveJavaRuntime(bin string) error {
+	return a.javaRuntimes.RemoveCustom(bin)
+}
+
+// GetJavaRuntimeSelections returns the runtime selected for each launch
+// target, keyed "client" and "server", and for each client profile with a
+// runtime of its own, keyed "client:<profile>".
+func (a *App) GetJavaRuntimeSelections() map[string]string {
+	return a.javaRuntimes.Selections()
+}
+
+// GetJavaRuntime returns the java executable a launch of target, or of a
+// client profile if profile is set, will use.
+func (a *App) GetJavaRuntime(target, profile string) (string, error) {
+	name, err := a.launchProfileName(target, profile)
+	if err != nil {
+		return "", err
+	}
+	return a.javaPath(javart.Key(target, name)), nil
+}
+
+// SelectJavaRuntime selects the runtime used for a launch target such as
+// "client" or "server", or for a client profile if profile is set. An empty
+// bin makes a profile use its target's runtime again, and a target the
+// bundled runtime.
+func (a *App) SelectJavaRuntime(target, profile, bin string) error {
+	name, err := a.launchProfileName(target, profile)
+	if err != nil {
+		return err
+	}
+	if err := a.javaRuntimes.Select(context.Background(), javart.Key(target, name), bin); err != nil {
+		return err
+	}
+
+	slog.Info("selected java runtime", "target", target, "profile", name, "bin", bin)
+	return nil
+}
@@ -0,0 +1,286 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	"hytale-launcher/internal/appstate"
+	"hytale-launcher/internal/build"
+	"hytale-launcher/internal/configexport"
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/settings"
+)
+
+// ExportConfigRequest contains parameters for exporting the launcher
+// configuration.
+type ExportConfigRequest struct {
+	// Path is the export file to write.
+	Path string `json:"path"`
+
+	// Passphrase protects the export.
+	Passphrase string `json:"passphrase"`
+
+	// IncludeAccounts adds the signed-in account, including its tokens.
+	IncludeAccounts bool `json:"includeAccounts"`
+}
+
+// ImportConfigRequest contains parameters for importing the launcher
+// configuration.
+type ImportConfigRequest struct {
+	// Path is the export file to import.
+	Path string `json:"path"`
+
+	// Passphrase the export was protected with.
+	Passphrase string `json:"passphrase"`
+
+	// Conflict is "keep" or "replace".
+	Conflict string `json:"conflict"`
+
+	// IncludeAccounts imports the account, if the export contains one.
+	IncludeAccounts bool `json:"includeAccounts"`
+}
+
+// ExportLauncherConfig writes the app state of every channel, the player
+// profiles, the settings, the server list and optionally the account to a
+// passphrase-encrypted file for moving to another machine.
+func (a *App) ExportLauncherConfig(req ExportConfigRequest) error {
+	if !strings.EqualFold(filepath.Ext(req.Path), configexport.Extension) {
+		return fmt.Errorf("launcher config export must have a %s extension", configexport.Extension)
+	}
+	if err := configexport.ValidatePassphrase(req.Passphrase); err != nil {
+		return err
+	}
+
+	payload, err := a.collectLauncherConfig(req.IncludeAccounts)
+	if err != nil {
+		return err
+	}
+	if err := configexport.Write(req.Path, req.Passphrase, payload); err != nil {
+		sentry.CaptureException(err)
+		return err
+	}
+
+	slog.Info("exported launcher config",
+		"path", req.Path,
+		"channels", len(payload.States),
+		"account", payload.Account != nil,
+	)
+	return nil
+}
+
+// collectLauncherConfig gathers the configuration to export.
+func (a *App) collectLauncherConfig(includeAccounts bool) (*configexport.Payload, error) {
+	payload := &configexport.Payload{
+		CreatedAt:       time.Now(),
+		LauncherVersion: build.Version,
+		States:          make(map[string]json.RawMessage),
+		Servers:         a.servers.List(),
+	}
+
+	for _, channel := range hytale.KnownChannels() {
+		state, err := appstate.Load(channel)
+		if errors.Is(err, appstate.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load state for channel %s: %w", channel, err)
+		}
+		data, err := json.Marshal(state)
+		if err != nil {
+			return nil, err
+		}
+		payload.States[channel] = data
+	}
+
+	payload.PlayerProfiles = a.playerProfiles.ListProfiles()
+	payload.PlayerName, _ = a.loadPlayerName()
+
+	current := a.Settings.Get()
+	payload.Settings = &current
+
+	if includeAccounts {
+		if acct := a.Auth.GetAccount(); acct != nil {
+			data, err := json.Marshal(acct)
+			if err != nil {
+				return nil, err
+			}
+			payload.Account = data
+		}
+	}
+	return payload, nil
+}
+
+// ImportLauncherConfig imports a launcher config export made on another
+// machine. Secrets are re-encrypted with this machine's keyring. Entries that
+// clash with local configuration are resolved by req.Conflict and reported in
+// the result.
+func (a *App) ImportLauncherConfig(req ImportConfigRequest) (*configexport.Result, error) {
+	if req.Conflict == "" {
+		req.Conflict = configexport.ConflictKeep
+	}
+	if !configexport.ValidConflict(req.Conflict) {
+		return nil, fmt.Errorf("unknown conflict policy %q", req.Conflict)
+	}
+	if a.isUpdating() {
+		return nil, errors.New("cannot import launcher config while updating")
+	}
+
+	payload, err := configexport.Read(req.Path, req.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+	replace := req.Conflict == configexport.ConflictReplace
+
+	result := configexport.NewResult()
+	a.importStates(payload, replace, result)
+	a.importPlayers(payload, replace, result)
+	a.importSettings(payload, replace, result)
+
+	if len(payload.Servers) > 0 {
+		conflicts, err := a.servers.Import(payload.Servers, replace)
+		if err != nil {
+			result.Fail(configexport.ItemServers, err)
+		} else {
+			result.Import(configexport.ItemServers)
+			result.Conflict(configexport.ItemServers, replace, conflicts...)
+		}
+	}
+
+	if req.IncludeAccounts && payload.Account != nil {
+		a.importAccount(payload.Account, replace, result)
+	}
+
+	slog.Info("imported launcher config",
+		"path", req.Path,
+		"imported", result.Imported,
+		"conflicts", len(result.Conflicts),
+		"failed", result.Failed,
+	)
+	a.ReloadLauncher("config_imported")
+	return result, nil
+}
+
+// importStates imports the app state of each channel.
+func (a *App) importStates(payload *configexport.Payload, replace bool, result *configexport.Result) {
+	for channel, data := range payload.States {
+		if !slices.Contains(hytale.KnownChannels(), channel) {
+			continue
+		}
+
+		_, err := appstate.Load(channel)
+		exists := !errors.Is(err, appstate.ErrNotFound)
+		if exists {
+			result.Conflict(configexport.ItemState, replace, channel)
+			if !replace {
+				continue
+			}
+		}
+
+		if _, err := appstate.Import(channel, data); err != nil {
+			slog.Warn("failed to import channel state", "channel", channel, "error", err)
+			result.Fail(configexport.ItemState+":"+channel, err)
+			continue
+		}
+		result.Import(configexport.ItemState + ":" + channel)
+
+		if a.State != nil && a.State.Channel == channel {
+			a.State = a.loadEnv(channel)
+		}
+	}
+}
+
+// importAccount imports the signed-in account.
+func (a *App) importAccount(data json.RawMessage, replace bool, result *configexport.Result) {
+	if a.Auth.IsLoggedIn() {
+		result.Conflict(configexport.ItemAccount, replace, "")
+		if !replace {
+			return
+		}
+	}
+
+	if err := a.Auth.ImportAccount(data); err != nil {
+		result.Fail(configexport.ItemAccount, err)
+		return
+	}
+	result.Import(configexport.ItemAccount)
+	a.selectDefaultProfile()
+}
+
+// importPlayers imports the offline player profiles and player name.
+func (a *App) importPlayers(payload *configexport.Payload, replace bool, result *configexport.Result) {
+	if len(payload.PlayerProfiles) > 0 {
+		if conflicts, err := a.playerProfiles.Import(payload.PlayerProfiles, replace); err != nil {
+			result.Fail(configexport.ItemPlayerProfiles, err)
+		} else {
+			result.Import(configexport.ItemPlayerProfiles)
+			result.Conflict(configexport.ItemPlayerProfiles, replace, conflicts...)
+		}
+	}
+
+	if payload.PlayerName == "" {
+		return
+	}
+	local, _ := a.loadPlayerName()
+	if local == payload.PlayerName {
+		return
+	}
+	if local != "" {
+		result.Conflict(configexport.ItemPlayerName, replace, local)
+		if !replace {
+			return
+		}
+	}
+	if err := a.savePlayerName(payload.PlayerName); err != nil {
+		result.Fail(configexport.ItemPlayerName, err)
+		return
+	}
+	result.Import(configexport.ItemPlayerName)
+}
+
+// importSettings imports the launcher settings. The storage directory and
+// keyring mode describe this machine and are never imported.
+func (a *App) importSettings(payload *configexport.Payload, replace bool, result *configexport.Result) {
+	if payload.Settings == nil {
+		return
+	}
+
+	local := a.Settings.Get()
+	imported := *payload.Settings
+	imported.StorageDir = local.StorageDir
+	imported.Keyring = local.Keyring
+	imported.Version = local.Version
+	// Exported before these settings existed.
+	if imported.OfflineUUIDScheme == "" {
+		imported.OfflineUUIDScheme = local.OfflineUUIDScheme
+	}
+	if imported.NotificationRoutes == "" {
+		imported.NotificationRoutes = local.NotificationRoutes
+	}
+	if imported == local {
+		return
+	}
+
+	defaults := settings.Defaults()
+	defaults.StorageDir = local.StorageDir
+	defaults.Keyring = local.Keyring
+	if local != defaults {
+		result.Conflict(configexport.ItemSettings, replace, "")
+		if !replace {
+			return
+		}
+	}
+
+	if err := a.Settings.Update(imported); err != nil {
+		result.Fail(configexport.ItemSettings, err)
+		return
+	}
+	result.Import(configexport.ItemSettings)
+}
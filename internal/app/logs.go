@@ -0,0 +1,129 @@
+package app
+
+import (
+	"path/filepath"
+	"time"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/logging"
+	"hytale-launcher/internal/logview"
+	"hytale-launcher/internal/throttle"
+)
+
+// logTailInterval is how often the log files are checked while tailing.
+const logTailInterval = time.Second
+
+// rotatedLauncherLogs is how many rotated launcher logs are listed. The
+// newest holds the previous launch, which is usually the one that failed.
+const rotatedLauncherLogs = 2
+
+// initLogs creates the log service over the launcher, server and client logs.
+func (a *App) initLogs() {
+	a.logs = logview.NewService(a.logSources)
+}
+
+// logSources returns the log files currently on disk.
+func (a *App) logSources() []logview.Source {
+	var sources []logview.Source
+	if src, ok := logview.File(logview.KindLauncher, logging.Path()); ok {
+		sources = append(sources, src)
+	}
+	rotated := logging.RotatedLogs()
+	for _, path := range rotated[:min(len(rotated), rotatedLauncherLogs)] {
+		if src, ok := logview.File(logview.KindLauncher, path); ok {
+			sources = append(sources, src)
+		}
+	}
+	if src, ok := logview.File(logview.KindServer, hytale.InStorageDir("server.log")); ok {
+		sources = append(sources, src)
+	}
+	for _, dir := range a.userDirs() {
+		sources = append(sources, clientLogSources(dir)...)
+	}
+
+	// The JVM writes fatal error reports to its working directory.
+	for _, dir := range []string{"package/game/latest/Server", "package/game/latest/Client", "UserData"} {
+		sources = append(sources, logview.Glob(logview.KindCrash, filepath.Join(hytale.InStorageDir(dir), "hs_err_pid*.log"))...)
+	}
+	return sources
+}
+
+// clientLogSources returns the client logs in a user directory. Logs in a
+// profile's own directory are named after the profile, so they do not clash
+// with the shared UserData logs.
+func clientLogSources(dir userDirectory) []logview.Source {
+	sources := logview.Glob(logview.KindClient, filepath.Join(dir.Path(), "Logs", "*.log"))
+	if label := dir.Label(); label != "" {
+		for i := range sources {
+			sources[i].Name = label + "/" + sources[i].Name
+			sources[i].ID = sources[i].Kind + ":" + sources[i].Name
+		}
+	}
+	return sources
+}
+
+// GetLogSources returns the available log files.
+func (a *App) GetLogSources() []logview.Source {
+	return a.logs.Sources()
+}
+
+// QueryLogs returns the log entries matching filter.
+func (a *App) QueryLogs(filter logview.Filter) ([]logview.Entry, error) {
+	return a.logs.Query(filter)
+}
+
+// GetLogIncidents returns the stack traces and crashes found in the given
+// log sources, or in all of them if none are given.
+func (a *App) GetLogIncidents(sources []string) ([]logview.Incident, error) {
+	return a.logs.Incidents(sources)
+}
+
+// OpenLogFile opens a log file with its default application.
+func (a *App) OpenLogFile(id string) error {
+	path, err := a.logs.Path(id)
+	if err != nil {
+		return err
+	}
+	return ioutil.OpenFile(path)
+}
+
+// StartLogTail starts emitting "logs:entries" for entries appended to the
+// log files, and "logs:incidents" for new stack traces and crashes.
+func (a *App) StartLogTail() {
+	a.logTailMu.Lock()
+	defer a.logTailMu.Unlock()
+
+	if a.logTail != nil {
+		return
+	}
+
+	// Skip what is already on disk; the frontend reads it with QueryLogs.
+	a.logs.Poll()
+
+	a.logTail = throttle.NewRefresher(a.tailLogs)
+	a.logTail.Start(logTailInterval)
+}
+
+// StopLogTail stops emitting log events.
+func (a *App) StopLogTail() {
+	a.logTailMu.Lock()
+	defer a.logTailMu.Unlock()
+
+	if a.logTail != nil {
+		a.logTail.Stop()
+		a.logTail = nil
+	}
+}
+
+// tailLogs emits the entries appended since the last poll.
+func (a *App) tailLogs() error {
+	entries, err := a.logs.Poll()
+	if len(entries) > 0 {
+		a.Emit("logs:entries", entries)
+	}
+	if incidents := logview.FindIncidents(entries); len(incidents) > 0 {
+		a.Emit("logs:incidents", incidents)
+	}
+	return err
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/keyring"
+)
+
+// TestMain points the storage directory and keyring at a temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "app")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("APPDATA", "")
+	os.Setenv("XDG_DATA_HOME", dir)
+	os.Setenv(keyring.PassphraseEnv, "test passphrase")
+	keyring.UseFile(filepath.Join(dir, "secrets.json"))
+	if err := ioutil.MkdirAll(hytale.StorageDir()); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
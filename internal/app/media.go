@@ -0,0 +1,131 @@
+package app
+
+import (
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/media"
+	"hytale-launcher/internal/throttle"
+)
+
+// mediaScanInterval is how often the media folders are rescanned for new
+// captures.
+const mediaScanInterval = 5 * time.Second
+
+// initMedia loads the media index and starts polling the media folders. The
+// folders are walked every mediaScanInterval rather than watched for file
+// system events, which would need a watch per user directory and folder.
+func (a *App) initMedia() {
+	a.media = media.NewIndex(a.userDirPaths, hytale.InStorageDir("media"))
+	if err := a.media.Load(); err != nil {
+		slog.Warn("failed to load media index", "error", err)
+	}
+
+	a.mediaWatcher = throttle.NewRefresher(a.scanMedia)
+	a.mediaWatcher.Start(mediaScanInterval)
+
+	go a.scanMedia()
+}
+
+// scanMedia updates the media index and notifies the frontend of changes.
+func (a *App) scanMedia() error {
+	changed, err := a.media.Scan(a.GetGameVersion())
+	if changed {
+		a.Emit("media:changed", nil)
+	}
+	return err
+}
+
+// GetMedia returns the indexed screenshots and recordings matching filter,
+// newest first. The index is refreshed by polling the media folders every
+// few seconds, and "media:changed" is emitted when a rescan finds new, changed
+// or removed files, so a capture shows up a few seconds after it is written.
+func (a *App) GetMedia(filter media.Filter) []media.Item {
+	return a.media.List(filter)
+}
+
+// GetMediaThumbnail returns an item's thumbnail as a data URL, or an empty
+// string if it has none.
+func (a *App) GetMediaThumbnail(id string) (string, error) {
+	item, err := a.media.Get(id)
+	if err != nil || item.Thumbnail == "" {
+		return "", err
+	}
+
+	data, err := os.ReadFile(item.Thumbnail)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// OpenMedia opens an item with its default application.
+func (a *App) OpenMedia(id string) error {
+	item, err := a.media.Get(id)
+	if err != nil {
+		return err
+	}
+	return ioutil.OpenFile(item.Path)
+}
+
+// userDirPaths returns the paths of every user directory.
+func (a *App) userDirPaths() []string {
+	var paths []string
+	for _, dir := range a.userDirs() {
+		paths = append(paths, dir.Path())
+	}
+	return paths
+}
+
+// OpenMediaFolder opens the folder holding a kind of media in the user
+// directory of profile, or in the shared UserData directory if profile is
+// empty.
+func (a *App) OpenMediaFolder(kind, profile string) error {
+	folder, ok := media.Folders[kind]
+	if !ok {
+		return errors.New("unknown media kind")
+	}
+	dir, err := a.profileUserDir(profile)
+	if err != nil {
+		return err
+	}
+	return ioutil.OpenDirectory(filepath.Join(dir.Path(), folder))
+}
+
+// DeleteMedia deletes the given items from disk.
+func (a *App) DeleteMedia(ids []string) error {
+	err := a.media.Delete(ids)
+	if err != nil {
+		slog.Error("failed to delete media", "error", err)
+	}
+	a.Emit("media:changed", nil)
+	return err
+}
+
+// ExportMedia writes the given items to a .zip archive at path.
+func (a *App) ExportMedia(ids []string, path string) error {
+	if len(ids) == 0 {
+		return errors.New("no media selected")
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		return errors.New("media archive must have a .zip extension")
+	}
+
+	if err := a.media.Export(ids, path); err != nil {
+		slog.Error("failed to export media", "path", path, "error", err)
+		sentry.CaptureException(err)
+		return err
+	}
+
+	slog.Info("exported media", "count", len(ids), "path", path)
+	return nil
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/jvmopts"
+)
+
+// javaOptionsEnv is read by the java launcher and prepended to its arguments.
+// The client has no option for JVM flags; it starts the JVM for local worlds
+// by running the java executable given with --java-exec, which is always a
+// runtime of at least javart.MinimumMajor, and so honours this variable
+// (JDK 9+), confirming it with a "Picked up JDK_JAVA_OPTIONS" note on
+// stderr. Being prepended, the options lose to any the client passes itself.
+const javaOptionsEnv = "JDK_JAVA_OPTIONS"
+
+// initJVMOptions loads the user's JVM overrides.
+func (a *App) initJVMOptions() {
+	a.jvmOptions = jvmopts.NewStore(hytale.InStorageDir("jvm_options.json"))
+	if err := a.jvmOptions.Load(); err != nil {
+		slog.Warn("failed to load jvm options", "error", err)
+	}
+}
+
+// memoryPlan computes the JVM configuration for a target or profile, given
+// as a jvmopts options key.
+func (a *App) memoryPlan(key string) *jvmopts.Plan {
+	mem, err := jvmopts.SystemMemory()
+	if err != nil {
+		slog.Warn("failed to read system memory", "error", err)
+	}
+	return jvmopts.NewPlan(jvmopts.TargetOf(key), mem, a.jvmOptions.Get(key))
+}
+
+// jvmArgs returns the JVM arguments for a launch, logging and emitting any
+// memory warnings. Warnings do not block the launch.
+func (a *App) jvmArgs(key string) []string {
+	plan := a.memoryPlan(key)
+	target := jvmopts.TargetOf(key)
+
+	slog.Info("planned jvm memory",
+		"options", key,
+		"xms", plan.InitialHeapMB,
+		"xmx", plan.MaxHeapMB,
+		"gc", plan.GC,
+		"presets", plan.Presets,
+	)
+
+	if len(plan.Warnings) > 0 {
+		slog.Warn("jvm memory warnings", "target", target, "warnings", plan.Warnings)
+		a.Emit("jvm:memory_warning", map[string]interface{}{
+			"target":   target,
+			"warnings": plan.Warnings,
+		})
+	}
+	return plan.Args
+}
+
+// javaOptionsEnviron returns the current environment with args added to
+// JDK_JAVA_OPTIONS, keeping any options the user already set.
+func javaOptionsEnviron(args []string) []string {
+	value := strings.Join(args, " ")
+	if existing := os.Getenv(javaOptionsEnv); existing != "" {
+		value = existing + " " + value
+	}
+
+	env := make([]string, 0, len(os.Environ())+1)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, javaOptionsEnv+"=") {
+			env = append(env, kv)
+		}
+	}
+	return append(env, javaOptionsEnv+"="+value)
+}
+
+// GetSystemMemory returns the total and available system memory.
+func (a *App) GetSystemMemory() (jvmopts.Memory, error) {
+	return jvmopts.SystemMemory()
+}
+
+// GetJVMPresets returns the curated JVM flag presets.
+func (a *App) GetJVMPresets() []jvmopts.Preset {
+	return jvmopts.Presets()
+}
+
+// jvmOptionsKey returns the jvmopts key of a target or client profile.
+func (a *App) jvmOptionsKey(target, profile string) (string, error) {
+	name, err := a.launchProfileName(target, profile)
+	if err != nil {
+		return "", err
+	}
+	return jvmopts.Key(target, name), nil
+}
+
+// GetJVMOptions returns the user's JVM overrides for "client" or "server",
+// or for a client profile if profile is set. A profile without options of
+// its own uses the client's.
+func (a *App) GetJVMOptions(target, profile string) (jvmopts.Options, error) {
+	key, err := a.jvmOptionsKey(target, profile)
+	if err != nil {
+		return jvmopts.Options{}, err
+	}
+	return a.jvmOptions.Get(key), nil
+}
+
+// GetMemoryPlan returns the heap sizes, collector and flags that the next
+// launch of target, or of a client profile, will use, with any warnings.
+func (a *App) GetMemoryPlan(target, profile string) (*jvmopts.Plan, error) {
+	key, err := a.jvmOptionsKey(target, profile)
+	if err != nil {
+		return nil, err
+	}
+	return a.memoryPlan(key), nil
+}
+
+// SetJVMOptions stores JVM overrides for a target or client profile and
+// returns the resulting plan. Zero options restore automatic sizing.
+func (a *App) SetJVMOptions(target, profile string, opts jvmopts.Options) (*jvmopts.Plan, error) {
+	key, err := a.jvmOptionsKey(target, profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.jvmOptions.Set(key, opts); err != nil {
+		return nil, err
+	}
+
+	slog.Info("jvm options changed", "options", key, "value", opts)
+	return a.memoryPlan(key), nil
+}
+
+// ResetProfileJVMOptions makes a client profile use the client's JVM options
+// again.
+func (a *App) ResetProfileJVMOptions(profile string) error {
+	key, err := a.jvmOptionsKey(jvmopts.TargetClient, profile)
+	if err != nil {
+		return err
+	}
+	return a.jvmOptions.Delete(key)
+}
@@ -0,0 +1,392 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/javart"
+	"hytale-launcher/internal/jvmopts"
+	"hytale-launcher/internal/modpack"
+	"hytale-launcher/internal/mods"
+	"hytale-launcher/internal/playerprofile"
+)
+
+// serverDataDir is the directory server modpack config paths are relative to.
+const serverDataDir = "package/game/latest/Server"
+
+// activeModpackName is the file next to a profile's user directory that
+// records the pack the profile was created from.
+const activeModpackName = "modpack.json"
+
+// errModpackReplacesServer is returned when a server modpack is imported
+// without confirming that it replaces the server's setup.
+var errModpackReplacesServer = errors.New("importing a server modpack replaces the server's mods, JVM options and config files; confirm to continue")
+
+// modpackDataDir returns the directory modpack config paths are relative to
+// for a target or client profile: the profile's user directory for the
+// client, and the server directory for the server.
+func (a *App) modpackDataDir(target, profile string) (string, error) {
+	name, err := a.launchProfileName(target, profile)
+	if err != nil {
+		return "", err
+	}
+	switch target {
+	case mods.TargetClient:
+		dir, err := a.profileUserDir(name)
+		if err != nil {
+			return "", err
+		}
+		return dir.Path(), nil
+	case mods.TargetServer:
+		return hytale.InStorageDir(serverDataDir), nil
+	default:
+		return "", fmt.Errorf("unknown target %q", target)
+	}
+}
+
+// activeModpackPath returns where the manifest of the pack a target or
+// client profile was set up from is kept. Client packs are recorded next to
+// the user directory of the profile created for them; an empty path means
+// the profile was not created from a pack.
+func (a *App) activeModpackPath(target, profile string) (string, error) {
+	name, err := a.launchProfileName(target, profile)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		return hytale.InStorageDir(filepath.Join("modpacks", target+".json")), nil
+	}
+
+	dir, err := a.profileUserDir(name)
+	if err != nil || dir.Rel == "" {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dir.Path()), activeModpackName), nil
+}
+
+// ExportModpackRequest contains parameters for exporting a modpack.
+type ExportModpackRequest struct {
+	// Name is the display name of the pack.
+	Name string `json:"name"`
+
+	// Description is an optional longer description.
+	Description string `json:"description,omitempty"`
+
+	// Target is "client" or "server".
+	Target string `json:"target"`
+
+	// Profile optionally selects a client profile to export instead of the
+	// client's defaults. Its configs are read from its user directory.
+	Profile string `json:"profile,omitempty"`
+
+	// Path is the .zip file to write.
+	Path string `json:"path"`
+
+	// EmbedMods embeds every mod, even those that can be downloaded.
+	EmbedMods bool `json:"embedMods"`
+
+	// Configs are config files to include, relative to the target's data directory.
+	Configs []string `json:"configs,omitempty"`
+}
+
+// ImportModpackRequest contains parameters for importing a modpack.
+type ImportModpackRequest struct {
+	// Path is the modpack .zip file.
+	Path string `json:"path"`
+
+	// Profile is the name of the client profile to create for a client
+	// pack. The profile gets its own user directory, mod set and JVM
+	// options, so the client's existing setup is left alone.
+	Profile string `json:"profile,omitempty"`
+
+	// ReplaceServer confirms that a server pack replaces the server's mod
+	// set, JVM options and config files. The server is a single instance,
+	// so a server pack cannot get a profile of its own.
+	ReplaceServer bool `json:"replaceServer,omitempty"`
+}
+
+// ModpackImportResult reports the outcome of a modpack import.
+type ModpackImportResult struct {
+	// Manifest is the imported pack's manifest.
+	Manifest *modpack.Manifest `json:"manifest"`
+
+	// Profile is the client profile created for the pack. Empty for server
+	// packs.
+	Profile string `json:"profile,omitempty"`
+
+	// Warnings describe pinned versions that do not match this installation.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// pinnedRuntime describes the Java runtime used by a target or profile, given
+// as a javart selection key.
+func (a *App) pinnedRuntime(key string) modpack.RuntimeRef {
+	rt, err := javart.Probe(context.Background(), a.javaPath(key), javart.SourceCustom)
+	if err != nil {
+		slog.Warn("failed to probe java runtime for modpack", "error", err)
+		return modpack.RuntimeRef{Major: javart.MinimumMajor}
+	}
+	return modpack.RuntimeRef{Version: rt.Version, Major: rt.Major, Vendor: rt.Vendor}
+}
+
+// installedGame describes the installed game build.
+func (a *App) installedGame() modpack.GameRef {
+	if a.State == nil {
+		return modpack.GameRef{}
+	}
+	dep := a.State.GetDependency("game")
+	if dep == nil {
+		return modpack.GameRef{}
+	}
+	return modpack.GameRef{Version: dep.Version, Build: dep.Build}
+}
+
+// ExportModpack writes the enabled mods, JVM options, selected config files
+// and pinned game and Java versions of a target or client profile to a
+// single .zip archive.
+func (a *App) ExportModpack(req ExportModpackRequest) (*modpack.Manifest, error) {
+	dataDir, err := a.modpackDataDir(req.Target, req.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(filepath.Ext(req.Path), ".zip") {
+		return nil, errors.New("modpack file must have a .zip extension")
+	}
+	profile, _ := a.launchProfileName(req.Target, req.Profile)
+
+	m := &modpack.Manifest{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Target:      req.Target,
+		CreatedAt:   time.Now().UTC(),
+		Game:        a.installedGame(),
+		JRE:         a.pinnedRuntime(javart.Key(req.Target, profile)),
+		JVM:         a.jvmOptions.Get(jvmopts.Key(req.Target, profile)),
+	}
+
+	src := modpack.Sources{
+		ModFiles:   make(map[string]string),
+		EmbedMods:  req.EmbedMods,
+		ConfigRoot: dataDir,
+	}
+
+	for _, mod := range a.mods.Enabled(mods.Key(req.Target, profile)) {
+		entry := modpack.ModEntry{
+			ID:       mod.ID,
+			Name:     mod.Name,
+			Version:  mod.Version,
+			FileName: mod.FileName,
+			SHA256:   mod.Hash,
+			Size:     mod.Size,
+		}
+		if strings.HasPrefix(mod.Source, "https://") || strings.HasPrefix(mod.Source, "http://") {
+			entry.URL = mod.Source
+		}
+		m.Mods = append(m.Mods, entry)
+
+		if file, ok := a.mods.Path(mod.Hash); ok {
+			src.ModFiles[mod.Hash] = file
+		}
+	}
+
+	for _, p := range req.Configs {
+		m.Configs = append(m.Configs, modpack.ConfigEntry{Path: filepath.ToSlash(p)})
+	}
+
+	if err := modpack.Export(req.Path, m, src); err != nil {
+		sentry.CaptureException(err)
+		return nil, err
+	}
+
+	slog.Info("exported modpack",
+		"name", m.Name,
+		"target", m.Target,
+		"profile", profile,
+		"mods", len(m.Mods),
+		"configs", len(m.Configs),
+		"path", req.Path,
+	)
+	return m, nil
+}
+
+// ImportModpack verifies a modpack archive and sets up a launch of it. A
+// client pack gets a new client profile with its own user directory, mod
+// set and JVM options; a server pack replaces the server's once confirmed
+// with ReplaceServer. Mods are added to the library, and those not embedded
+// in the archive are downloaded.
+func (a *App) ImportModpack(req ImportModpackRequest) (*ModpackImportResult, error) {
+	// Check the profile name before downloading anything.
+	if req.Profile != "" {
+		if err := playerprofile.ValidateName(req.Profile); err != nil {
+			return nil, err
+		}
+		if a.playerProfiles.GetProfile(req.Profile) != nil {
+			return nil, fmt.Errorf("%w: %s", playerprofile.ErrExists, req.Profile)
+		}
+	}
+
+	progress := func(stage string, current, total int) {
+		a.Emit("modpack:progress", map[string]interface{}{
+			"stage":   stage,
+			"current": current,
+			"total":   total,
+		})
+	}
+
+	pack, err := modpack.Open(context.Background(), req.Path, hytale.InStorageDir("cache"), a.mods.Path, progress)
+	if err != nil {
+		slog.Error("failed to open modpack", "path", req.Path, "error", err)
+		return nil, err
+	}
+	defer pack.Close()
+
+	m := pack.Manifest
+	switch {
+	case m.Target == mods.TargetClient && req.Profile == "":
+		return nil, errors.New("a profile name is required for a client modpack")
+	case m.Target == mods.TargetServer && req.Profile != "":
+		return nil, errors.New("server modpacks cannot be imported into a client profile")
+	case m.Target == mods.TargetServer && !req.ReplaceServer:
+		return nil, errModpackReplacesServer
+	}
+
+	slog.Info("importing modpack", "name", m.Name, "target", m.Target, "profile", req.Profile, "mods", len(m.Mods))
+
+	var hashes []string
+	for _, entry := range m.Mods {
+		source := entry.URL
+		if source == "" {
+			source = "modpack:" + m.Name
+		}
+
+		mod, err := a.mods.Import(pack.ModFiles[entry.SHA256], source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import mod %s: %w", entry.Name, err)
+		}
+		hashes = append(hashes, mod.Hash)
+	}
+
+	var profile string
+	if m.Target == mods.TargetClient {
+		created, err := a.CreatePlayerProfile(req.Profile)
+		if err != nil {
+			return nil, err
+		}
+		profile = created.Name
+
+		if err := a.setUpModpack(pack, profile, hashes); err != nil {
+			if err := a.DeletePlayerProfile(profile, true); err != nil {
+				slog.Warn("failed to remove profile of failed modpack import", "profile", profile, "error", err)
+			}
+			return nil, err
+		}
+	} else if err := a.setUpModpack(pack, "", hashes); err != nil {
+		return nil, err
+	}
+
+	result := &ModpackImportResult{
+		Manifest: m,
+		Profile:  profile,
+		Warnings: a.modpackWarnings(m, profile),
+	}
+
+	a.Emit("modpack:imported", result)
+	return result, nil
+}
+
+// setUpModpack applies an opened pack to the server, or to a client profile
+// created for it: the mods with the given library hashes are enabled in
+// order, the JVM options are applied and the config files are written.
+func (a *App) setUpModpack(pack *modpack.Pack, profile string, hashes []string) error {
+	m := pack.Manifest
+
+	if profile != "" {
+		if _, err := a.SetPlayerProfileUserDir(profile, true); err != nil {
+			return err
+		}
+	}
+
+	if err := a.mods.SetMods(mods.Key(m.Target, profile), hashes); err != nil {
+		return err
+	}
+	if err := a.jvmOptions.Set(jvmopts.Key(m.Target, profile), m.JVM); err != nil {
+		return err
+	}
+
+	dataDir, err := a.modpackDataDir(m.Target, profile)
+	if err != nil {
+		return err
+	}
+	for p, file := range pack.ConfigFiles {
+		if err := installConfig(file, filepath.Join(dataDir, filepath.FromSlash(p))); err != nil {
+			return fmt.Errorf("failed to install config %s: %w", p, err)
+		}
+	}
+
+	record, err := a.activeModpackPath(m.Target, profile)
+	if err == nil {
+		err = modpack.WriteManifest(record, m)
+	}
+	if err != nil {
+		slog.Warn("failed to record imported modpack", "error", err)
+	}
+	return nil
+}
+
+// modpackWarnings compares the pack's pinned versions with this installation
+// and the runtime the pack's target or profile will use.
+func (a *App) modpackWarnings(m *modpack.Manifest, profile string) []string {
+	var warnings []string
+
+	game := a.installedGame()
+	if m.Game.Version != "" && game.Version != m.Game.Version {
+		installed := game.Version
+		if installed == "" {
+			installed = "none"
+		}
+		warnings = append(warnings, fmt.Sprintf("The pack was made for game version %s, installed version is %s.", m.Game.Version, installed))
+	}
+
+	rt := a.pinnedRuntime(javart.Key(m.Target, profile))
+	if rt.Major < m.JRE.Major {
+		warnings = append(warnings, fmt.Sprintf("The pack requires Java %d or newer, the selected runtime is Java %d.", m.JRE.Major, rt.Major))
+	}
+
+	return warnings
+}
+
+// GetActiveModpack returns the manifest of the pack a client profile was
+// created from, or of the last pack imported for the server. It returns nil
+// if there is none.
+func (a *App) GetActiveModpack(target, profile string) *modpack.Manifest {
+	path, err := a.activeModpackPath(target, profile)
+	if err != nil || path == "" {
+		return nil
+	}
+	m, err := modpack.ReadManifest(path)
+	if err != nil {
+		return nil
+	}
+	return m
+}
+
+// installConfig copies a verified config file into place.
+func installConfig(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/jvmopts"
+	"hytale-launcher/internal/mods"
+	"hytale-launcher/internal/playerprofile"
+)
+
+// importTestMod writes a mod archive and adds it to the library.
+func importTestMod(t *testing.T, a *App, name string) *mods.Mod {
+	t.Helper()
+
+	p := filepath.Join(t.TempDir(), name+".jar")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(name + ".txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(name))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	mod, err := a.mods.Import(p, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mod
+}
+
+// enabledHashes returns the hashes of the enabled mods of a set.
+func enabledHashes(a *App, key string) []string {
+	var hashes []string
+	for _, mod := range a.mods.Enabled(key) {
+		hashes = append(hashes, mod.Hash)
+	}
+	return hashes
+}
+
+func TestImportClientModpackCreatesProfile(t *testing.T) {
+	a, alex := newProfileApp(t)
+	mod := importTestMod(t, a, "team")
+
+	// alex has the setup to share; the client's defaults differ.
+	if err := a.SetModEnabled(mods.TargetClient, "alex", mod.Hash, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SetJVMOptions(jvmopts.TargetClient, "alex", jvmopts.Options{MaxHeapMB: 4096}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.SetJVMOptions(jvmopts.TargetClient, "", jvmopts.Options{MaxHeapMB: 2048}); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(alex.Path(), "Settings", "game.json")
+	if err := os.MkdirAll(filepath.Dir(config), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config, []byte(`{"fov": 90}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "team.zip")
+	_, err := a.ExportModpack(ExportModpackRequest{
+		Name:    "Team",
+		Target:  mods.TargetClient,
+		Profile: "alex",
+		Path:    path,
+		Configs: []string{"Settings/game.json"},
+	})
+	if err != nil {
+		t.Fatalf("ExportModpack: %v", err)
+	}
+
+	if _, err := a.ImportModpack(ImportModpackRequest{Path: path}); err == nil {
+		t.Fatal("client pack imported without a profile")
+	}
+	if _, err := a.ImportModpack(ImportModpackRequest{Path: path, Profile: "alex"}); !errors.Is(err, playerprofile.ErrExists) {
+		t.Fatalf("import into an existing profile: %v, want ErrExists", err)
+	}
+
+	name := "TeamPack"
+	result, err := a.ImportModpack(ImportModpackRequest{Path: path, Profile: name})
+	if err != nil {
+		t.Fatalf("ImportModpack: %v", err)
+	}
+	t.Cleanup(func() { a.DeletePlayerProfile(name, true) })
+
+	if result.Profile != name {
+		t.Errorf("result profile = %q, want %q", result.Profile, name)
+	}
+	if got := enabledHashes(a, mods.Key(mods.TargetClient, name)); !slices.Equal(got, []string{mod.Hash}) {
+		t.Errorf("profile mods = %v, want the pack's", got)
+	}
+	if got := a.jvmOptions.Get(jvmopts.Key(jvmopts.TargetClient, name)).MaxHeapMB; got != 4096 {
+		t.Errorf("profile MaxHeapMB = %d, want the pack's 4096", got)
+	}
+
+	// The client's own setup is untouched.
+	if got := enabledHashes(a, mods.TargetClient); len(got) != 0 {
+		t.Errorf("client mods = %v, want none", got)
+	}
+	if got := a.jvmOptions.Get(jvmopts.TargetClient).MaxHeapMB; got != 2048 {
+		t.Errorf("client MaxHeapMB = %d, want 2048", got)
+	}
+
+	dir, err := a.profileUserDir(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir.Rel == "" {
+		t.Fatal("pack profile shares the UserData directory")
+	}
+	data, err := os.ReadFile(filepath.Join(dir.Path(), "Settings", "game.json"))
+	if err != nil || string(data) != `{"fov": 90}` {
+		t.Errorf("config in profile user directory = %q, %v", data, err)
+	}
+	if m := a.GetActiveModpack(mods.TargetClient, name); m == nil || m.Name != "Team" {
+		t.Errorf("active modpack = %+v, want Team", m)
+	}
+}
+
+func TestImportServerModpackNeedsConfirmation(t *testing.T) {
+	a, _ := newProfileApp(t)
+	packed := importTestMod(t, a, "packed")
+	current := importTestMod(t, a, "current")
+
+	if err := a.mods.SetMods(mods.TargetServer, []string{packed.Hash}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "server.zip")
+	if _, err := a.ExportModpack(ExportModpackRequest{Name: "Server", Target: mods.TargetServer, Path: path}); err != nil {
+		t.Fatalf("ExportModpack: %v", err)
+	}
+	if err := a.mods.SetMods(mods.TargetServer, []string{current.Hash}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(hytale.InStorageDir("modpacks")) })
+
+	if _, err := a.ImportModpack(ImportModpackRequest{Path: path}); !errors.Is(err, errModpackReplacesServer) {
+		t.Fatalf("unconfirmed server import: %v, want errModpackReplacesServer", err)
+	}
+	if got := enabledHashes(a, mods.TargetServer); !slices.Equal(got, []string{current.Hash}) {
+		t.Fatalf("server mods after refused import = %v, want unchanged", got)
+	}
+
+	if _, err := a.ImportModpack(ImportModpackRequest{Path: path, ReplaceServer: true}); err != nil {
+		t.Fatalf("confirmed server import: %v", err)
+	}
+	if got := enabledHashes(a, mods.TargetServer); !slices.Equal(got, []string{packed.Hash}) {
+		t.Fatalf("server mods = %v, want the pack's", got)
+	}
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/getsentry/sentry-go"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/mods"
+)
+
+// modDirs maps each launch target to the directory its mods are deployed to.
+var modDirs = map[string]string{
+	mods.TargetClient: "UserData/Mods",
+	mods.TargetServer: "package/game/latest/Server/mods",
+}
+
+// initMods loads the mod library.
+func (a *App) initMods() {
+	a.mods = mods.NewManager(hytale.InStorageDir("mods"))
+	if err := a.mods.Load(); err != nil {
+		slog.Warn("failed to load mod library", "error", err)
+	}
+}
+
+// modSet returns the key of the mod set used by a launch profile of target.
+// An empty profile is the target's default set.
+func (a *App) modSet(target, profile string) (string, error) {
+	name, err := a.launchProfileName(target, profile)
+	if err != nil {
+		return "", err
+	}
+	return mods.Key(target, name), nil
+}
+
+// deployMods installs the enabled mods of a target's default set before launch.
+func (a *App) deployMods(target string) error {
+	return a.deployModsTo(target, hytale.InStorageDir(modDirs[target]))
+}
+
+// deployModsTo installs the enabled mods of a set into dir.
+func (a *App) deployModsTo(key, dir string) error {
+	result, err := a.mods.Deploy(key, dir)
+	if err != nil {
+		slog.Error("failed to deploy mods", "set", key, "error", err)
+		sentry.CaptureException(err)
+		return err
+	}
+
+	slog.Info("deployed mods",
+		"set", key,
+		"deployed", len(result.Deployed),
+		"removed", len(result.Removed),
+		"conflicts", len(result.Conflicts),
+	)
+	a.Emit("mods:deployed", result)
+	return nil
+}
+
+// GetMods returns all mods in the library.
+func (a *App) GetMods() []mods.Mod {
+	return a.mods.List()
+}
+
+// ImportMod adds a .jar or .zip mod file to the library.
+func (a *App) ImportMod(path string) (*mods.Mod, error) {
+	mod, err := a.mods.Import(path, path)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("imported mod", "id", mod.ID, "version", mod.Version, "hash", mod.Hash)
+	return mod, nil
+}
+
+// RemoveMod deletes a mod from the library and every mod set.
+func (a *App) RemoveMod(hash string) error {
+	return a.mods.Remove(hash)
+}
+
+// GetEnabledMods returns the enabled mods of "client" or "server" in load
+// order. A non-empty profile selects the set of that client profile, which
+// is the default client set until the profile's set is changed.
+func (a *App) GetEnabledMods(target, profile string) ([]mods.Mod, error) {
+	key, err := a.modSet(target, profile)
+	if err != nil {
+		return nil, err
+	}
+	return a.mods.Enabled(key), nil
+}
+
+// HasProfileMods reports whether a client profile has a mod set of its own
+// rather than using the default client set.
+func (a *App) HasProfileMods(profile string) (bool, error) {
+	key, err := a.modSet(mods.TargetClient, profile)
+	if err != nil {
+		return false, err
+	}
+	return a.mods.HasOwnSet(key), nil
+}
+
+// ResetProfileMods makes a client profile use the default client set again.
+func (a *App) ResetProfileMods(profile string) error {
+	key, err := a.modSet(mods.TargetClient, profile)
+	if err != nil {
+		return err
+	}
+	if key == mods.TargetClient {
+		return errors.New("profile is required")
+	}
+	return a.mods.DeleteSet(key)
+}
+
+// SetModEnabled enables or disables a mod for a target or client profile.
+func (a *App) SetModEnabled(target, profile, hash string, enabled bool) error {
+	key, err := a.modSet(target, profile)
+	if err != nil {
+		return err
+	}
+	return a.mods.SetEnabled(key, hash, enabled)
+}
+
+// SetModOrder sets the load order of the enabled mods of a target or client
+// profile.
+func (a *App) SetModOrder(target, profile string, hashes []string) error {
+	key, err := a.modSet(target, profile)
+	if err != nil {
+		return err
+	}
+	return a.mods.SetOrder(key, hashes)
+}
+
+// GetModConflicts returns conflicts between the enabled mods of a target or
+// client profile.
+func (a *App) GetModConflicts(target, profile string) ([]mods.Conflict, error) {
+	key, err := a.modSet(target, profile)
+	if err != nil {
+		return nil, err
+	}
+	return a.mods.Conflicts(key)
+}
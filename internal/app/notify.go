@@ -0,0 +1,75 @@
+package app
+
+import (
+	"errors"
+	"log/slog"
+
+	"hytale-launcher/internal/notifications"
+	"hytale-launcher/internal/settings"
+)
+
+// initNotifications sets up the notification backends and routes them by
+// the notification settings.
+func (a *App) initNotifications() {
+	a.notifier = notifications.NewRouter()
+
+	desktop, err := notifications.NewDesktopNotifier()
+	if errors.Is(err, errors.ErrUnsupported) {
+		slog.Debug("desktop notifications are not supported on this platform")
+	} else if err != nil {
+		slog.Warn("desktop notifications unavailable", "error", err)
+	} else {
+		a.notifier.SetBackend(notifications.BackendDesktop, desktop)
+	}
+
+	a.applyNotificationSettings(a.Settings.Get())
+	a.Settings.Subscribe(func(old, new settings.Settings) {
+		if new.NotificationRoutes != old.NotificationRoutes || new.NotificationWebhookURL != old.NotificationWebhookURL {
+			a.applyNotificationSettings(new)
+		}
+	})
+
+	notifications.SetNotifier(a.notifier)
+}
+
+// applyNotificationSettings updates the routes and the webhook backend.
+func (a *App) applyNotificationSettings(s settings.Settings) {
+	routes, err := notifications.ParseRoutes(s.NotificationRoutes)
+	if err != nil {
+		slog.Warn("invalid notification routes", "error", err)
+	}
+	a.notifier.SetRoutes(routes)
+
+	if s.NotificationWebhookURL == "" {
+		a.notifier.SetBackend(notifications.BackendWebhook, nil)
+	} else {
+		a.notifier.SetBackend(notifications.BackendWebhook, notifications.NewWebhookNotifier(s.NotificationWebhookURL, nil))
+	}
+}
+
+// notify sends a notification for a launcher event in the background, so a
+// slow backend never holds up the event.
+func (a *App) notify(event string, t notifications.NotificationType, title, message string) {
+	n := notifications.Notification{
+		Title:   title,
+		Message: message,
+		Type:    t,
+		Event:   event,
+	}
+	go func() {
+		if err := notifications.Send(n); err != nil {
+			slog.Warn("failed to send notification", "event", event, "error", err)
+		}
+	}()
+}
+
+// SendTestNotification sends a notification for event through the configured
+// routes and returns any backend error, so the user can check the setup.
+func (a *App) SendTestNotification(event string) error {
+	return notifications.Send(notifications.Notification{
+		Title:   "Hytale Launcher",
+		Message: "This is a test notification.",
+		Type:    notifications.TypeInfo,
+		Event:   event,
+	})
+}
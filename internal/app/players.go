@@ -0,0 +1,315 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/javart"
+	"hytale-launcher/internal/jvmopts"
+	"hytale-launcher/internal/mods"
+	"hytale-launcher/internal/playerprofile"
+	"hytale-launcher/internal/settings"
+)
+
+// profileDataDir is the storage subdirectory holding per-profile user
+// directories.
+const profileDataDir = "profiles"
+
+// playerProfilesPath returns where the offline player profiles are kept.
+func playerProfilesPath() string {
+	return hytale.InStorageDir("player_profiles.json")
+}
+
+// initPlayerProfiles loads the offline player profiles.
+func (a *App) initPlayerProfiles() {
+	a.playerProfiles = playerprofile.New(playerProfilesPath())
+	if err := a.playerProfiles.Load(); err != nil {
+		slog.Warn("failed to load player profiles", "error", err)
+	}
+
+	a.playerProfiles.SetScheme(a.Settings.Get().OfflineUUIDScheme)
+	a.Settings.Subscribe(func(old, new settings.Settings) {
+		if new.OfflineUUIDScheme != old.OfflineUUIDScheme {
+			a.playerProfiles.SetScheme(new.OfflineUUIDScheme)
+		}
+	})
+}
+
+// playerUserDir returns the game user directory of a profile.
+func playerUserDir(profile *playerprofile.PlayerProfile) string {
+	if profile == nil {
+		return userDataDir()
+	}
+	return userDirPath(profile.UserDir)
+}
+
+// userDirPath returns the absolute path of a user directory given relative
+// to the storage directory. Empty is the shared UserData directory.
+func userDirPath(rel string) string {
+	if rel == "" {
+		return userDataDir()
+	}
+	return hytale.InStorageDir(rel)
+}
+
+// userDirectory is a game user directory the client writes worlds, captures and
+// logs to.
+type userDirectory struct {
+	// Profile is the player profile with this as its own user directory.
+	// Empty for the shared UserData directory.
+	Profile string
+
+	// Rel is the directory relative to the storage directory. Empty for the
+	// shared UserData directory.
+	Rel string
+}
+
+// Path returns the directory's absolute path.
+func (d userDirectory) Path() string {
+	return userDirPath(d.Rel)
+}
+
+// Label returns a short name for the directory that is safe to use in
+// file names: the profile name, or its directory if the name predates the
+// name rules.
+func (d userDirectory) Label() string {
+	if d.Profile == "" {
+		return ""
+	}
+	if playerprofile.ValidateName(d.Profile) == nil {
+		return d.Profile
+	}
+	return filepath.Base(filepath.Dir(d.Rel))
+}
+
+// userDirs returns the shared UserData directory followed by the user
+// directories of the profiles that have their own.
+func (a *App) userDirs() []userDirectory {
+	dirs := []userDirectory{{}}
+	if a.playerProfiles == nil {
+		return dirs
+	}
+	for _, profile := range a.playerProfiles.ListProfiles() {
+		if profile.UserDir != "" {
+			dirs = append(dirs, userDirectory{Profile: profile.Name, Rel: profile.UserDir})
+		}
+	}
+	return dirs
+}
+
+// profileUserDir returns the user directory of the named profile. An empty
+// name is the shared UserData directory.
+func (a *App) profileUserDir(name string) (userDirectory, error) {
+	if name == "" {
+		return userDirectory{}, nil
+	}
+	profile := a.playerProfiles.GetProfile(name)
+	if profile == nil {
+		return userDirectory{}, fmt.Errorf("%w: %s", playerprofile.ErrNotFound, name)
+	}
+	return userDirectory{Profile: profile.Name, Rel: profile.UserDir}, nil
+}
+
+// launchProfileName returns the stored spelling of the name of a launch
+// profile of target. Launch profiles are the client's player profiles; the
+// server is a single instance without profiles. An empty profile stays empty
+// and stands for the target's defaults.
+func (a *App) launchProfileName(target, profile string) (string, error) {
+	if profile == "" {
+		return "", nil
+	}
+	if target != mods.TargetClient {
+		return "", fmt.Errorf("the %s has no launch profiles", target)
+	}
+	p := a.playerProfiles.GetProfile(profile)
+	if p == nil {
+		return "", fmt.Errorf("%w: %s", playerprofile.ErrNotFound, profile)
+	}
+	return p.Name, nil
+}
+
+// GetPlayerProfiles returns the offline player profiles sorted by name.
+func (a *App) GetPlayerProfiles() []*playerprofile.PlayerProfile {
+	return a.playerProfiles.ListProfiles()
+}
+
+// CreatePlayerProfile validates name and creates an offline player profile.
+func (a *App) CreatePlayerProfile(name string) (*playerprofile.PlayerProfile, error) {
+	profile, err := a.playerProfiles.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("created player profile", "name", profile.Name, "uuid", profile.UUID)
+	a.Emit("players:changed")
+	return profile, nil
+}
+
+// RenamePlayerProfile renames an offline player profile, keeping its UUID.
+func (a *App) RenamePlayerProfile(oldName, newName string) (*playerprofile.PlayerProfile, error) {
+	existing := a.playerProfiles.GetProfile(oldName)
+	if existing == nil {
+		return nil, fmt.Errorf("%w: %s", playerprofile.ErrNotFound, oldName)
+	}
+	previous := existing.Name
+
+	profile, err := a.playerProfiles.Rename(oldName, newName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The profile's launch settings follow it to its new name.
+	from, to := mods.Key(mods.TargetClient, previous), mods.Key(mods.TargetClient, profile.Name)
+	if err := a.mods.MoveSet(from, to); err != nil {
+		slog.Warn("failed to move profile mod set", "from", previous, "to", profile.Name, "error", err)
+	}
+	from, to = javart.Key(javart.TargetClient, previous), javart.Key(javart.TargetClient, profile.Name)
+	if err := a.javaRuntimes.MoveSelection(from, to); err != nil {
+		slog.Warn("failed to move profile java runtime", "from", previous, "to", profile.Name, "error", err)
+	}
+	from, to = jvmopts.Key(jvmopts.TargetClient, previous), jvmopts.Key(jvmopts.TargetClient, profile.Name)
+	if err := a.jvmOptions.Move(from, to); err != nil {
+		slog.Warn("failed to move profile jvm options", "from", previous, "to", profile.Name, "error", err)
+	}
+
+	if saved, _ := a.loadPlayerName(); saved == oldName {
+		if err := a.savePlayerName(newName); err != nil {
+			slog.Warn("failed to save player name", "error", err)
+		}
+	}
+
+	slog.Info("renamed player profile", "from", oldName, "to", newName, "uuid", profile.UUID)
+	a.Emit("players:changed")
+	return profile, nil
+}
+
+// DeletePlayerProfile deletes an offline player profile. With deleteData its
+// own user directory, including its worlds and settings, is deleted too.
+func (a *App) DeletePlayerProfile(name string, deleteData bool) error {
+	profile := a.playerProfiles.GetProfile(name)
+	if profile == nil {
+		return fmt.Errorf("%w: %s", playerprofile.ErrNotFound, name)
+	}
+	userDir := profile.UserDir
+
+	if err := a.playerProfiles.DeleteProfile(name); err != nil {
+		return err
+	}
+
+	if err := a.mods.DeleteSet(mods.Key(mods.TargetClient, profile.Name)); err != nil {
+		slog.Warn("failed to delete profile mod set", "name", profile.Name, "error", err)
+	}
+	if err := a.javaRuntimes.Select(context.Background(), javart.Key(javart.TargetClient, profile.Name), ""); err != nil {
+		slog.Warn("failed to clear profile java runtime", "name", profile.Name, "error", err)
+	}
+	if err := a.jvmOptions.Delete(jvmopts.Key(jvmopts.TargetClient, profile.Name)); err != nil {
+		slog.Warn("failed to delete profile jvm options", "name", profile.Name, "error", err)
+	}
+
+	if deleteData && userDir != "" {
+		dir := filepath.Dir(hytale.InStorageDir(userDir))
+		if !strings.HasPrefix(dir, hytale.InStorageDir(profileDataDir)+string(filepath.Separator)) {
+			return fmt.Errorf("refusing to delete user directory outside %s: %s", profileDataDir, dir)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to delete player data: %w", err)
+		}
+	}
+
+	slog.Info("deleted player profile", "name", name, "deleteData", deleteData)
+	a.Emit("players:changed")
+	return nil
+}
+
+// SetDefaultPlayerProfile makes a profile the one preselected at launch.
+func (a *App) SetDefaultPlayerProfile(name string) error {
+	if err := a.playerProfiles.SetDefault(name); err != nil {
+		return err
+	}
+	if err := a.savePlayerName(name); err != nil {
+		slog.Warn("failed to save player name", "error", err)
+	}
+
+	a.Emit("players:changed")
+	return nil
+}
+
+// SetPlayerProfileUserDir gives a profile its own game user directory, so
+// that players sharing a computer keep separate settings and worlds.
+// Turning it off makes the profile use the shared UserData again; its own
+// directory is kept on disk.
+func (a *App) SetPlayerProfileUserDir(name string, separate bool) (*playerprofile.PlayerProfile, error) {
+	profile := a.playerProfiles.GetProfile(name)
+	if profile == nil {
+		return nil, fmt.Errorf("%w: %s", playerprofile.ErrNotFound, name)
+	}
+
+	var dir string
+	if separate {
+		dir = profile.UserDir
+		if dir == "" {
+			dir = filepath.Join(profileDataDir, profile.UUID, "UserData")
+		}
+		if err := ioutil.MkdirAll(hytale.InStorageDir(dir)); err != nil {
+			return nil, fmt.Errorf("unable to create player user directory: %w", err)
+		}
+	}
+
+	profile, err := a.playerProfiles.SetUserDir(name, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Emit("players:changed")
+	return profile, nil
+}
+
+// PreviewUUIDScheme reports which player profiles would get a different UUID
+// if they were moved to scheme. Nothing is changed.
+func (a *App) PreviewUUIDScheme(scheme string) ([]playerprofile.UUIDChange, error) {
+	return a.playerProfiles.PlanScheme(scheme)
+}
+
+// ApplyUUIDScheme moves the player profiles to scheme and makes it the
+// scheme for new profiles. Profiles with an imported UUID keep it.
+func (a *App) ApplyUUIDScheme(scheme string) ([]playerprofile.UUIDChange, error) {
+	changes, err := a.playerProfiles.ApplyScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Settings.Set("offline_uuid_scheme", scheme); err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		if change.Changes {
+			slog.Info("changed player UUID",
+				"name", change.Name,
+				"scheme", scheme,
+				"from", change.OldUUID,
+				"to", change.NewUUID,
+			)
+		}
+	}
+	a.Emit("players:changed")
+	return changes, nil
+}
+
+// SetPlayerProfileUUID gives a player profile an explicit UUID, such as one a
+// server already knows the player by.
+func (a *App) SetPlayerProfileUUID(name, id string) (*playerprofile.PlayerProfile, error) {
+	profile, err := a.playerProfiles.SetUUID(name, id)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("set player UUID", "name", name, "uuid", profile.UUID)
+	a.Emit("players:changed")
+	return profile, nil
+}
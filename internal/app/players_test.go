@@ -0,0 +1,205 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/javart"
+	"hytale-launcher/internal/jvmopts"
+	"hytale-launcher/internal/mods"
+	"hytale-launcher/internal/playerprofile"
+	"hytale-launcher/internal/settings"
+	"hytale-launcher/internal/snapshot"
+	"hytale-launcher/internal/worlds"
+)
+
+func TestLaunchProfileKeepsLegacyNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "player_profiles.json")
+	legacy := `{"Игрок": {"name": "Игрок", "uuid": "6f1b5c4e-1d2a-5b3c-9e8f-0a1b2c3d4e5f"}}`
+	if err := os.WriteFile(path, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a := &App{playerProfiles: playerprofile.New(path)}
+	if err := a.playerProfiles.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := a.launchProfile("Игрок")
+	if err != nil {
+		t.Fatalf("existing profile with a legacy name cannot launch: %v", err)
+	}
+	if profile.UUID != "6f1b5c4e-1d2a-5b3c-9e8f-0a1b2c3d4e5f" {
+		t.Fatalf("UUID = %s, want the stored one", profile.UUID)
+	}
+
+	if _, err := a.launchProfile("new player"); err == nil {
+		t.Fatal("new profile with an invalid name was created")
+	}
+
+	if err := a.savePlayerName("old player"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.launchProfile("old player"); err != nil {
+		t.Fatalf("saved legacy name cannot launch: %v", err)
+	}
+}
+
+// newProfileApp returns an App with a profile "alex" that has its own user
+// directory.
+func newProfileApp(t *testing.T) (*App, userDirectory) {
+	t.Helper()
+
+	dir := t.TempDir()
+	a := &App{
+		Settings:       settings.NewStore(filepath.Join(dir, "settings.json")),
+		playerProfiles: playerprofile.New(filepath.Join(dir, "player_profiles.json")),
+		snapshots:      snapshot.NewStore(filepath.Join(dir, "snapshots")),
+		mods:           mods.NewManager(filepath.Join(dir, "mods")),
+		javaRuntimes:   javart.NewManager(filepath.Join(dir, "java_runtimes.json")),
+		jvmOptions:     jvmopts.NewStore(filepath.Join(dir, "jvm_options.json")),
+	}
+	profile, err := a.playerProfiles.Create("alex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel := filepath.Join(profileDataDir, t.Name(), "UserData")
+	t.Cleanup(func() { os.RemoveAll(filepath.Dir(hytale.InStorageDir(rel))) })
+	if _, err := a.playerProfiles.SetUserDir(profile.Name, rel); err != nil {
+		t.Fatal(err)
+	}
+	return a, userDirectory{Profile: profile.Name, Rel: rel}
+}
+
+func TestWorldsInProfileUserDir(t *testing.T) {
+	a, dir := newProfileApp(t)
+	world := filepath.Join(dir.Path(), snapshot.SavesDir, "Adventure")
+	if err := os.MkdirAll(world, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(world, "level.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := a.GetWorlds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed bool
+	for _, w := range found {
+		if w.Name == "Adventure" && w.Profile == "alex" && w.Path == world {
+			listed = true
+		}
+	}
+	if !listed {
+		t.Fatalf("worlds = %+v, want Adventure of alex", found)
+	}
+
+	root, err := a.worldRoot(worlds.TargetClient, "alex")
+	if err != nil || root != filepath.Dir(world) {
+		t.Fatalf("worldRoot = %s, %v, want %s", root, err, filepath.Dir(world))
+	}
+}
+
+func TestSnapshotProfileUserDir(t *testing.T) {
+	a, dir := newProfileApp(t)
+	settingsFile := filepath.Join(dir.Path(), "Settings.json")
+	if err := os.MkdirAll(dir.Path(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settingsFile, []byte("before"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := a.CreateSnapshot("alex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Source != dir.Rel {
+		t.Fatalf("snapshot source = %q, want %q", snap.Source, dir.Rel)
+	}
+
+	if err := os.WriteFile(settingsFile, []byte("after"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RestoreSnapshot(snap.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(settingsFile); string(data) != "before" {
+		t.Fatalf("restored settings = %q, want the profile's snapshot", data)
+	}
+}
+
+func TestClientLogsInProfileUserDir(t *testing.T) {
+	a, dir := newProfileApp(t)
+	if err := os.MkdirAll(filepath.Join(dir.Path(), "Logs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir.Path(), "Logs", "client.log"), []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var found bool
+	for _, src := range a.logSources() {
+		if src.ID == "client:alex/client.log" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("sources = %+v, want alex's client log", a.logSources())
+	}
+}
+
+func TestProfileLaunchSettingsFollowProfile(t *testing.T) {
+	a, _ := newProfileApp(t)
+
+	java := filepath.Join(t.TempDir(), "java_runtimes.json")
+	if err := os.WriteFile(java, []byte(`{"selections": {"client:alex": "/jdk/bin/java"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a.javaRuntimes = javart.NewManager(java)
+	if err := a.javaRuntimes.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.SetModOrder(mods.TargetClient, "ALEX", nil); err != nil {
+		t.Fatal(err)
+	}
+	if own, err := a.HasProfileMods("alex"); err != nil || !own {
+		t.Fatalf("HasProfileMods = %v, %v after changing the set", own, err)
+	}
+	if _, err := a.GetEnabledMods(mods.TargetServer, "alex"); err == nil {
+		t.Fatal("server set with a profile accepted")
+	}
+
+	if _, err := a.SetJVMOptions(jvmopts.TargetClient, "alex", jvmopts.Options{MaxHeapMB: 3072}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.RenamePlayerProfile("alex", "sam"); err != nil {
+		t.Fatal(err)
+	}
+	if a.mods.HasOwnSet(mods.Key(mods.TargetClient, "alex")) || !a.mods.HasOwnSet(mods.Key(mods.TargetClient, "sam")) {
+		t.Fatal("mod set did not follow the renamed profile")
+	}
+	if got := a.javaRuntimes.Selected(javart.Key(javart.TargetClient, "sam")); got != "/jdk/bin/java" {
+		t.Fatalf("java runtime of the renamed profile = %q", got)
+	}
+	if opts, err := a.GetJVMOptions(jvmopts.TargetClient, "sam"); err != nil || opts.MaxHeapMB != 3072 {
+		t.Fatalf("JVM options of the renamed profile = %+v, %v", opts, err)
+	}
+
+	if err := a.DeletePlayerProfile("sam", false); err != nil {
+		t.Fatal(err)
+	}
+	if a.mods.HasOwnSet(mods.Key(mods.TargetClient, "sam")) {
+		t.Fatal("mod set of a deleted profile kept")
+	}
+	if _, ok := a.javaRuntimes.Selections()[javart.Key(javart.TargetClient, "sam")]; ok {
+		t.Fatal("java runtime of a deleted profile kept")
+	}
+	if opts := a.jvmOptions.Get(jvmopts.Key(jvmopts.TargetClient, "sam")); opts.MaxHeapMB != 0 {
+		t.Fatal("JVM options of a deleted profile kept")
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/getsentry/sentry-go"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/keyring"
+	"hytale-launcher/internal/settings"
+)
+
+// secretsFilePath returns the location of the encrypted secrets file.
+func secretsFilePath() string {
+	return hytale.InStorageDir("secrets.json")
+}
+
+// configureSecretStore selects the secret store backend at startup.
+// Portable mode always keeps the secrets file selected by main.
+func (a *App) configureSecretStore(mode string) {
+	if hytale.Portable() {
+		return
+	}
+
+	if err := keyring.Configure(mode, secretsFilePath()); err != nil {
+		sentry.CaptureException(err)
+		slog.Error("failed to configure secret store", "mode", mode, "error", err)
+	}
+}
+
+// onKeyringSetting migrates secrets when the keyring setting changes.
+// If the migration fails the setting is reverted, so that the next start does
+// not select a backend that lacks the secrets.
+func (a *App) onKeyringSetting(old, new settings.Settings) {
+	if old.Keyring == new.Keyring || hytale.Portable() {
+		return
+	}
+
+	if _, err := keyring.Migrate(new.Keyring); err != nil {
+		if !errors.Is(err, keyring.ErrSecretsWouldBeLost) {
+			sentry.CaptureException(err)
+		}
+		slog.Error("failed to migrate secrets", "to", new.Keyring, "error", err)
+		a.Emit("secrets:migration_failed", err.Error())
+		if err := a.Settings.Set("keyring", old.Keyring); err != nil {
+			slog.Warn("failed to revert keyring setting", "error", err)
+		}
+		return
+	}
+
+	a.Emit("secrets:migrated", keyring.CurrentStatus())
+}
+
+// GetSecretStoreStatus returns which backend currently holds launcher secrets.
+func (a *App) GetSecretStoreStatus() keyring.Status {
+	return keyring.CurrentStatus()
+}
+
+// MigrateSecretStore moves all launcher secrets to the given backend
+// ("auto", "system", "file" or "disabled") and makes it the active one.
+func (a *App) MigrateSecretStore(backend string) (*keyring.MigrationReport, error) {
+	if hytale.Portable() {
+		return nil, errors.New("secrets are always kept in a file in portable mode")
+	}
+
+	report, err := keyring.Migrate(backend)
+	if err != nil {
+		if !errors.Is(err, keyring.ErrSecretsWouldBeLost) {
+			sentry.CaptureException(err)
+		}
+		return nil, err
+	}
+
+	// Persist the choice; the settings subscriber sees the backend is
+	// already active and does not migrate again.
+	if err := a.Settings.Set("keyring", backend); err != nil {
+		slog.Warn("failed to save keyring setting", "error", err)
+	}
+	return report, nil
+}
@@ -2,6 +2,10 @@ package app
 
 import (
 	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/getsentry/sentry-go"
 
 	"hytale-launcher/internal/buildscan"
 	"hytale-launcher/internal/fork"
@@ -53,3 +57,24 @@ func (a *App) PopulateSentryExtra(extra map[string]any) {
 	}
 	extra["process"] = processInfo
 }
+
+// telemetryEnabled reports whether error reports may be sent to Sentry.
+var telemetryEnabled atomic.Bool
+
+// telemetryOnce ensures the telemetry event processor is only installed once.
+var telemetryOnce sync.Once
+
+// setTelemetryEnabled turns error reporting on or off.
+// When disabled, every Sentry event is dropped before it is sent.
+func setTelemetryEnabled(enabled bool) {
+	telemetryEnabled.Store(enabled)
+
+	telemetryOnce.Do(func() {
+		sentry.AddGlobalEventProcessor(func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+			if !telemetryEnabled.Load() {
+				return nil
+			}
+			return event
+		})
+	})
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"log/slog"
+
+	"hytale-launcher/internal/events"
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/serverout"
+)
+
+// serverOutputRulesPath returns where user rules for parsing server output
+// are kept.
+func serverOutputRulesPath() string {
+	return hytale.InStorageDir("server_output_rules.json")
+}
+
+// initServerOutput loads the server output rules and creates the player list.
+func (a *App) initServerOutput() {
+	a.players = serverout.NewPlayers()
+
+	rules, err := serverout.LoadRules(serverOutputRulesPath())
+	if err != nil {
+		slog.Warn("failed to load server output rules, using defaults", "error", err)
+		rules = serverout.DefaultRules()
+	}
+	if err := a.setOutputRules(rules); err != nil {
+		slog.Warn("invalid server output rules, using defaults", "error", err)
+		a.setOutputRules(serverout.DefaultRules())
+	}
+}
+
+// setOutputRules replaces the parser used for server output.
+func (a *App) setOutputRules(rules []serverout.Rule) error {
+	parser, err := serverout.NewParser(rules)
+	if err != nil {
+		return err
+	}
+
+	a.outputMu.Lock()
+	a.outputParser = parser
+	a.outputMu.Unlock()
+	return nil
+}
+
+// parseServerLine parses a line of server output, emits the event it
+// describes and hands it to the integrations. Lines matching no rule are
+// published as plain log lines. Boot completion is returned to the caller,
+// which reports it once.
+func (a *App) parseServerLine(line, stream string) (serverout.Entry, bool) {
+	a.outputMu.RLock()
+	parser := a.outputParser
+	a.outputMu.RUnlock()
+
+	entry, ok := parser.Parse(line)
+	if !ok {
+		a.publish(events.ServerLog, map[string]string{"line": line, "stream": stream})
+		return entry, false
+	}
+	if entry.Event == serverout.EventReady {
+		return entry, true
+	}
+
+	if a.players.Apply(entry) {
+		a.Emit("server:players", a.players.List())
+	}
+	a.Emit(entry.Event, entry)
+	a.publish(entry.Event, entry.Fields)
+	return entry, true
+}
+
+// resetServerPlayers empties the player list when the server starts or stops.
+func (a *App) resetServerPlayers() {
+	if a.players.Reset() {
+		a.Emit("server:players", a.players.List())
+	}
+}
+
+// GetServerPlayers returns the players online on the launcher-managed server.
+func (a *App) GetServerPlayers() []serverout.Player {
+	return a.players.List()
+}
+
+// GetServerOutputRules returns the rules used to parse server output.
+func (a *App) GetServerOutputRules() ([]serverout.Rule, error) {
+	return serverout.LoadRules(serverOutputRulesPath())
+}
+
+// GetDefaultServerOutputRules returns the built-in rules, as a starting
+// point for editing.
+func (a *App) GetDefaultServerOutputRules() []serverout.Rule {
+	return serverout.DefaultRules()
+}
+
+// SetServerOutputRules saves rules that replace the built-in ones, so the
+// parser can follow changes to the server log format. They apply at once.
+func (a *App) SetServerOutputRules(rules []serverout.Rule) error {
+	if err := serverout.SaveRules(serverOutputRulesPath(), rules); err != nil {
+		return err
+	}
+
+	slog.Info("server output rules changed", "rules", len(rules))
+	return a.setOutputRules(rules)
+}
+
+// ResetServerOutputRules goes back to the built-in rules.
+func (a *App) ResetServerOutputRules() error {
+	if err := ioutil.RemoveWithBackup(serverOutputRulesPath()); err != nil {
+		return err
+	}
+
+	slog.Info("server output rules reset")
+	return a.setOutputRules(serverout.DefaultRules())
+}
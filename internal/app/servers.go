@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/servers"
+	"hytale-launcher/internal/throttle"
+)
+
+// serverPingInterval is how often the stored servers are probed.
+const serverPingInterval = 30 * time.Second
+
+// initServerList loads the stored server list and starts the status pinger.
+func (a *App) initServerList() {
+	a.servers = servers.New(hytale.InStorageDir("servers.json"))
+	if err := a.servers.Load(); err != nil {
+		slog.Warn("failed to load server list", "error", err)
+	}
+
+	a.pinger = servers.NewPinger(a.servers, nil)
+	a.serverPinger = throttle.NewRefresher(a.pingServers)
+	a.serverPinger.Start(serverPingInterval)
+
+	go a.pingServers()
+}
+
+// pingServers probes all stored servers and notifies the frontend.
+func (a *App) pingServers() error {
+	statuses := a.pinger.PingAll(context.Background())
+	a.Emit("servers:status", statuses)
+	return nil
+}
+
+// GetServers returns the stored server list.
+func (a *App) GetServers() []servers.Server {
+	return a.servers.List()
+}
+
+// AddServer adds a server to the stored list.
+func (a *App) AddServer(s servers.Server) (servers.Server, error) {
+	added, err := a.servers.Add(s)
+	if err != nil {
+		return servers.Server{}, err
+	}
+
+	slog.Info("server added", "id", added.ID, "address", added.Address())
+	go a.pingServers()
+	return added, nil
+}
+
+// UpdateServer updates the name, address, notes or favourite flag of a stored server.
+func (a *App) UpdateServer(s servers.Server) (servers.Server, error) {
+	updated, err := a.servers.Update(s)
+	if err != nil {
+		return servers.Server{}, err
+	}
+
+	go a.pingServers()
+	return updated, nil
+}
+
+// RemoveServer removes a server from the stored list.
+func (a *App) RemoveServer(id string) error {
+	slog.Info("removing server", "id", id)
+	return a.servers.Remove(id)
+}
+
+// SetServerFavourite marks or unmarks a server as favourite.
+func (a *App) SetServerFavourite(id string, favourite bool) error {
+	return a.servers.SetFavourite(id, favourite)
+}
+
+// GetServerStatuses returns the most recent reachability results keyed by server ID.
+func (a *App) GetServerStatuses() map[string]servers.Status {
+	return a.pinger.Statuses()
+}
+
+// RefreshServerStatuses probes all stored servers immediately.
+func (a *App) RefreshServerStatuses() map[string]servers.Status {
+	statuses := a.pinger.PingAll(context.Background())
+	a.Emit("servers:status", statuses)
+	return statuses
+}
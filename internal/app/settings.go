@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"hytale-launcher/internal/download"
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/logging"
+	"hytale-launcher/internal/settings"
+)
+
+// settingsWatchInterval is how often the settings file is checked for external edits.
+const settingsWatchInterval = 5 * time.Second
+
+// initSettings loads the settings store, applies it and starts watching it.
+func (a *App) initSettings() {
+	a.Settings = settings.NewStore(hytale.InStorageDir("settings.json"))
+	if err := a.Settings.Load(); err != nil {
+		slog.Warn("failed to load settings, using defaults", "error", err)
+	}
+
+	a.applySettings(a.Settings.Get())
+	a.configureSecretStore(a.Settings.Get().Keyring)
+	a.Settings.Subscribe(func(old, new settings.Settings) {
+		slog.Info("settings changed")
+		a.applySettings(new)
+		a.Emit("settings:changed", new)
+	})
+	a.Settings.Subscribe(a.onKeyringSetting)
+	a.Settings.Subscribe(a.onStorageDirSetting)
+	a.Settings.Watch(settingsWatchInterval)
+}
+
+// applySettings pushes settings into the subsystems that use them.
+func (a *App) applySettings(s settings.Settings) {
+	err := logging.Configure(logging.Options{
+		Debug:     s.DebugLogging,
+		JSON:      s.LogFormat == settings.LogFormatJSON,
+		Levels:    s.LogLevels,
+		Retention: s.LogRetention,
+	})
+	if err != nil {
+		slog.Warn("failed to apply log settings", "error", err)
+	}
+	download.SetRateLimit(int64(s.DownloadLimitKBps) * 1024)
+	setTelemetryEnabled(s.Telemetry)
+}
+
+// GetSettings returns the current launcher settings.
+func (a *App) GetSettings() settings.Settings {
+	return a.Settings.Get()
+}
+
+// SetSetting changes a single setting identified by its JSON name.
+// A *settings.ValidationError is returned for invalid values.
+func (a *App) SetSetting(key string, value any) error {
+	slog.Debug("setting changed by user", "key", key, "value", value)
+	return a.Settings.Set(key, value)
+}
+
+// UpdateSettings replaces all settings at once.
+func (a *App) UpdateSettings(s settings.Settings) error {
+	return a.Settings.Update(s)
+}
+
+// ResetSettings restores all settings to their defaults.
+func (a *App) ResetSettings() error {
+	slog.Info("resetting launcher settings")
+	return a.Settings.Reset()
+}
+
+// BeforeClose is called by Wails when the window is about to close.
+// It minimizes the window instead when the user prefers that.
+func (a *App) BeforeClose(ctx context.Context) bool {
+	if !a.minimizeOnClose() {
+		return false
+	}
+
+	runtime.WindowMinimise(ctx)
+	return true
+}
+
+// minimizeOnClose returns true if closing the window should minimize it.
+// Wails routes runtime.Quit through BeforeClose as well, so a quit requested
+// by the launcher itself is never turned into a minimize.
+func (a *App) minimizeOnClose() bool {
+	if a.quitting.Load() {
+		return false
+	}
+	return a.Settings != nil && a.Settings.Get().CloseBehaviour == settings.CloseMinimize
+}
+
+// quit exits the launcher, bypassing the close behaviour.
+func (a *App) quit() {
+	a.quitting.Store(true)
+	if a.ctx != nil {
+		runtime.Quit(a.ctx)
+	}
+}
+
+// afterGameLaunch applies the configured launch behaviour to the launcher window.
+func (a *App) afterGameLaunch() {
+	switch a.Settings.Get().LaunchBehaviour {
+	case settings.LaunchKeepOpen:
+		return
+	case settings.LaunchClose:
+		slog.Info("closing launcher after game launch")
+		a.quit()
+	default:
+		a.minimizeLauncher()
+	}
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"hytale-launcher/internal/settings"
+)
+
+func TestCloseAfterLaunchBypassesMinimizeOnClose(t *testing.T) {
+	a := &App{Settings: settings.NewStore(filepath.Join(t.TempDir(), "settings.json"))}
+	if err := a.Settings.Set("close_behaviour", settings.CloseMinimize); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Settings.Set("launch_behaviour", settings.LaunchClose); err != nil {
+		t.Fatal(err)
+	}
+
+	if !a.minimizeOnClose() {
+		t.Fatal("closing the window does not minimize it")
+	}
+
+	a.afterGameLaunch()
+
+	if a.BeforeClose(context.Background()) {
+		t.Fatal("BeforeClose prevented the quit after game launch")
+	}
+}
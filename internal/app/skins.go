@@ -0,0 +1,139 @@
+package app
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/playerprofile"
+	"hytale-launcher/internal/skins"
+)
+
+// launchSkinDir is the directory in the game user directory that receives
+// the selected skin or cosmetic preset at launch. The client takes no
+// argument for a player's appearance, so this directory is the contract with
+// whatever applies it, such as a client mod: it is emptied at every launch
+// and then holds at most one file, skin.png (a texture that passed the
+// library's validation) or preset.json (a JSON object). No file means the
+// default appearance.
+const launchSkinDir = "LauncherSkin"
+
+// initSkins loads the skin library.
+func (a *App) initSkins() {
+	a.skins = skins.NewLibrary(hytale.InStorageDir("skins"))
+	if err := a.skins.Load(); err != nil {
+		slog.Warn("failed to load skin library", "error", err)
+	}
+}
+
+// GetSkins returns the skins and cosmetic presets in the library.
+func (a *App) GetSkins() []skins.Skin {
+	return a.skins.List()
+}
+
+// GetSkinPreview returns a skin's preview as a data URL, or an empty string
+// for cosmetic presets.
+func (a *App) GetSkinPreview(id string) (string, error) {
+	data, err := a.skins.Preview(id)
+	if err != nil || data == nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// ImportSkin adds a .png skin or .json cosmetic preset to the library.
+// An empty name uses the file name.
+func (a *App) ImportSkin(path, name string) (*skins.Skin, error) {
+	skin, err := a.skins.Import(path, name)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("imported skin", "id", skin.ID, "kind", skin.Kind, "name", skin.Name)
+	a.Emit("skins:changed")
+	return skin, nil
+}
+
+// DeleteSkin removes a skin from the library. Profiles that selected it go
+// back to the default appearance.
+func (a *App) DeleteSkin(id string) error {
+	if err := a.skins.Delete(id); err != nil {
+		return err
+	}
+
+	cleared, err := a.playerProfiles.ClearSkin(id)
+	if err != nil {
+		slog.Warn("failed to clear deleted skin from profiles", "id", id, "error", err)
+	}
+
+	slog.Info("deleted skin", "id", id, "profiles", cleared)
+	a.Emit("skins:changed")
+	if len(cleared) > 0 {
+		a.Emit("players:changed")
+	}
+	return nil
+}
+
+// SetPlayerProfileSkin selects a skin or cosmetic preset for a player
+// profile. An empty id restores the default appearance.
+func (a *App) SetPlayerProfileSkin(name, id string) (*playerprofile.PlayerProfile, error) {
+	if id != "" {
+		if _, err := a.skins.Get(id); err != nil {
+			return nil, err
+		}
+	}
+
+	profile, err := a.playerProfiles.SetSkin(name, id)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Emit("players:changed")
+	return profile, nil
+}
+
+// installProfileSkin copies the profile's selected skin into the game user
+// directory, replacing whatever a previous launch left there.
+func (a *App) installProfileSkin(profile *playerprofile.PlayerProfile, userDir string) error {
+	dir := filepath.Join(userDir, launchSkinDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to clear previous skin: %w", err)
+	}
+	if profile.Skin == "" {
+		return nil
+	}
+
+	skin, err := a.skins.Get(profile.Skin)
+	if errors.Is(err, skins.ErrNotFound) {
+		slog.Warn("selected skin is no longer in the library", "player", profile.Name, "skin", profile.Skin)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	src, err := a.skins.Path(skin.ID)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read skin: %w", err)
+	}
+	if err := ioutil.MkdirAll(dir); err != nil {
+		return err
+	}
+
+	dst := filepath.Join(dir, skin.Kind+filepath.Ext(src))
+	if err := ioutil.WriteFileAtomic(dst, data, 0644); err != nil {
+		return fmt.Errorf("failed to install skin: %w", err)
+	}
+
+	slog.Debug("installed player skin", "player", profile.Name, "skin", skin.ID, "path", dst)
+	return nil
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hytale-launcher/internal/skins"
+)
+
+func TestInstallProfileSkin(t *testing.T) {
+	a, _ := newProfileApp(t)
+	a.skins = skins.NewLibrary(filepath.Join(t.TempDir(), "skins"))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 64, 64))); err != nil {
+		t.Fatal(err)
+	}
+	src := t.TempDir()
+	skinPath := filepath.Join(src, "steve.png")
+	presetPath := filepath.Join(src, "outfit.json")
+	if err := os.WriteFile(skinPath, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(presetPath, []byte(`{"hair": "braids"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	skin, err := a.skins.Import(skinPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	preset, err := a.skins.Import(presetPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userDir := t.TempDir()
+	dir := filepath.Join(userDir, launchSkinDir)
+	install := func(id string, want string) {
+		t.Helper()
+		profile, err := a.playerProfiles.SetSkin("alex", id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := a.installProfileSkin(profile, userDir); err != nil {
+			t.Fatalf("installProfileSkin(%q): %v", id, err)
+		}
+
+		entries, _ := os.ReadDir(dir)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		if want == "" && len(names) != 0 || want != "" && (len(names) != 1 || names[0] != want) {
+			t.Fatalf("%s holds %v, want %q", launchSkinDir, names, want)
+		}
+	}
+
+	// Each launch replaces what the previous one installed.
+	install(skin.ID, "skin.png")
+	data, err := os.ReadFile(filepath.Join(dir, "skin.png"))
+	if err != nil || !bytes.Equal(data, buf.Bytes()) {
+		t.Fatalf("installed skin differs: %v", err)
+	}
+	install(preset.ID, "preset.json")
+	install("", "")
+}
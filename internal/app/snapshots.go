@@ -0,0 +1,140 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/getsentry/sentry-go"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/snapshot"
+)
+
+// snapshotsDir is the storage subdirectory holding the snapshot store. It
+// survives DeleteUserData so that deleted worlds can still be restored.
+const snapshotsDir = "snapshots"
+
+// initSnapshots opens the UserData snapshot store.
+func (a *App) initSnapshots() {
+	a.snapshots = snapshot.NewStore(hytale.InStorageDir(snapshotsDir))
+}
+
+// userDataDir returns the client's UserData directory.
+func userDataDir() string {
+	return hytale.InStorageDir("UserData")
+}
+
+// autoSnapshot takes an automatic snapshot of a user directory unless
+// snapshots are disabled. Failures are reported but never block the caller.
+func (a *App) autoSnapshot(dir userDirectory, reason string) {
+	retention := a.Settings.Get().SnapshotRetention
+	if retention == 0 {
+		return
+	}
+
+	if _, err := a.takeSnapshot(dir, reason, retention); err != nil {
+		slog.Warn("failed to snapshot user data", "reason", reason, "profile", dir.Profile, "error", err)
+		sentry.CaptureException(err)
+		a.Emit("snapshots:failed", map[string]interface{}{
+			"reason":  reason,
+			"profile": dir.Profile,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// autoSnapshotAll takes an automatic snapshot of every user directory.
+func (a *App) autoSnapshotAll(reason string) {
+	for _, dir := range a.userDirs() {
+		a.autoSnapshot(dir, reason)
+	}
+}
+
+// takeSnapshot snapshots a user directory and prunes its old snapshots
+// beyond retention. A retention of zero keeps all snapshots.
+func (a *App) takeSnapshot(dir userDirectory, reason string, retention int) (*snapshot.Snapshot, error) {
+	snap, err := a.snapshots.Create(dir.Path(), dir.Rel, reason, a.GetGameVersion())
+	if err != nil || snap == nil {
+		return nil, err
+	}
+
+	slog.Info("snapshotted user data",
+		"id", snap.ID,
+		"reason", reason,
+		"profile", dir.Profile,
+		"files", snap.FileCount,
+		"size", snap.Size,
+	)
+
+	if retention > 0 {
+		removed, err := a.snapshots.Prune(dir.Rel, retention)
+		if err != nil {
+			slog.Warn("failed to prune snapshots", "error", err)
+		} else if removed > 0 {
+			slog.Info("pruned old snapshots", "removed", removed)
+		}
+	}
+
+	summary := *snap
+	summary.Files = nil
+	a.Emit("snapshots:created", summary)
+	return &summary, nil
+}
+
+// GetSnapshots returns UserData snapshots matching filter, newest first. A
+// snapshot's source is the user_dir of the profile it was taken of, or empty
+// for the shared UserData directory.
+func (a *App) GetSnapshots(filter snapshot.Filter) ([]snapshot.Snapshot, error) {
+	return a.snapshots.List(filter)
+}
+
+// GetSnapshot returns a snapshot including its file list.
+func (a *App) GetSnapshot(id string) (*snapshot.Snapshot, error) {
+	return a.snapshots.Get(id)
+}
+
+// CreateSnapshot takes a snapshot of a player profile's user directory now.
+// An empty profile snapshots the shared UserData directory.
+func (a *App) CreateSnapshot(profile string) (*snapshot.Snapshot, error) {
+	dir, err := a.profileUserDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	return a.takeSnapshot(dir, snapshot.ReasonManual, a.Settings.Get().SnapshotRetention)
+}
+
+// DeleteSnapshot deletes a snapshot.
+func (a *App) DeleteSnapshot(id string) error {
+	return a.snapshots.Delete(id)
+}
+
+// RestoreSnapshot restores a snapshot into the user directory it was taken
+// of. If world is non-empty only that world is restored. The current state
+// is snapshotted first so that the restore can be undone.
+func (a *App) RestoreSnapshot(id, world string) error {
+	snap, err := a.snapshots.Get(id)
+	if err != nil {
+		return err
+	}
+	if snap.Source != "" && !filepath.IsLocal(snap.Source) {
+		return fmt.Errorf("snapshot %s has an invalid source %q", id, snap.Source)
+	}
+	dir := userDirectory{Rel: snap.Source}
+
+	if _, err := a.takeSnapshot(dir, snapshot.ReasonRestore, 0); err != nil {
+		return err
+	}
+
+	slog.Info("restoring snapshot", "id", id, "world", world, "source", snap.Source)
+	if err := a.snapshots.Restore(id, dir.Path(), world); err != nil {
+		sentry.CaptureException(err)
+		return err
+	}
+
+	a.Emit("snapshots:restored", map[string]interface{}{
+		"id":    id,
+		"world": world,
+	})
+	return nil
+}
@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	"hytale-launcher/internal/fork"
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/relocate"
+	"hytale-launcher/internal/settings"
+)
+
+// GetStorageDir returns the storage directory currently in use.
+func (a *App) GetStorageDir() string {
+	return hytale.StorageDir()
+}
+
+// IsPortable returns true if the launcher keeps its data next to the executable.
+func (a *App) IsPortable() bool {
+	return hytale.Portable()
+}
+
+// IsStorageMovePending returns true if the storage directory has been moved
+// and the launcher must restart to use the new location.
+func (a *App) IsStorageMovePending() bool {
+	return relocate.Pending()
+}
+
+// MoveStorage moves the whole storage directory to target.
+// Progress is reported through "storage:move_progress" events. Once the move
+// is committed the launcher restarts, since anything it wrote to the old
+// directory afterwards would be lost when that directory is removed.
+func (a *App) MoveStorage(target string) error {
+	if hytale.Portable() {
+		return errors.New("cannot move storage in portable mode")
+	}
+	if a.isUpdating() {
+		return errors.New("cannot move storage while updating")
+	}
+	if a.IsServerRunning() {
+		return errors.New("cannot move storage while the server is running")
+	}
+
+	a.markAsUpdating(true)
+	defer a.markAsUpdating(false)
+
+	reporter := func(stage string, done, total int64) {
+		var progress float64
+		if total > 0 {
+			progress = float64(done) / float64(total)
+		}
+		a.Emit("storage:move_progress", map[string]interface{}{
+			"stage":    stage,
+			"done":     done,
+			"total":    total,
+			"progress": progress,
+		})
+	}
+
+	if err := relocate.Move(context.Background(), hytale.StorageDir(), target, reporter); err != nil {
+		sentry.CaptureException(err)
+		a.Emit("storage:move_failed", err.Error())
+		return err
+	}
+
+	a.Emit("storage:moved", map[string]interface{}{
+		"path":             target,
+		"restart_required": true,
+	})
+	a.restartLauncher("storage_moved")
+	return nil
+}
+
+// restartLauncher starts a new launcher process and exits this one without
+// writing anything further. If the new process cannot be started the
+// launcher still exits, so the user starts it again by hand.
+func (a *App) restartLauncher(cause string) {
+	slog.Info("restarting launcher", "cause", cause)
+
+	exe, err := os.Executable()
+	if err == nil {
+		_, err = fork.RunAsUser(exe)
+	}
+	if err != nil {
+		sentry.CaptureException(err)
+		slog.Error("failed to restart launcher, exiting", "error", err)
+	}
+	os.Exit(0)
+}
+
+// onStorageDirSetting starts a storage move when the storage_dir setting changes.
+// Clearing the setting moves the storage back to the OS default directory.
+// If the move fails the setting is reverted.
+func (a *App) onStorageDirSetting(old, new settings.Settings) {
+	if new.StorageDir == old.StorageDir {
+		return
+	}
+
+	revert := func() {
+		if err := a.Settings.Set("storage_dir", old.StorageDir); err != nil {
+			slog.Warn("failed to revert storage_dir setting", "error", err)
+		}
+	}
+
+	target := new.StorageDir
+	if target == "" {
+		dir, err := hytale.DefaultStorageDir()
+		if err != nil {
+			slog.Error("failed to find the default storage directory", "error", err)
+			revert()
+			return
+		}
+		target = dir
+	}
+	if filepath.Clean(target) == filepath.Clean(hytale.StorageDir()) {
+		return
+	}
+
+	go func() {
+		if err := a.MoveStorage(target); err != nil {
+			slog.Error("failed to move storage directory", "target", target, "error", err)
+			revert()
+		}
+	}()
+}
+
+// RecoveredFile describes a launcher file that was corrupt or missing and was
+// loaded from its backup instead.
+type RecoveredFile struct {
+	Path        string    `json:"path"`
+	Error       string    `json:"error"`
+	RecoveredAt time.Time `json:"recovered_at"`
+}
+
+// initStorageRecovery emits a "storage:recovered" warning event whenever a
+// file is loaded from its backup.
+func (a *App) initStorageRecovery() {
+	ioutil.OnRecovered(func(path string, cause error) {
+		file := RecoveredFile{
+			Path:        path,
+			Error:       cause.Error(),
+			RecoveredAt: time.Now(),
+		}
+
+		a.recoveredMu.Lock()
+		a.recovered = append(a.recovered, file)
+		a.recoveredMu.Unlock()
+
+		a.Emit("storage:recovered", file)
+	})
+}
+
+// GetRecoveredFiles returns the files loaded from their backup since the
+// launcher started, including those recovered before the frontend was ready.
+func (a *App) GetRecoveredFiles() []RecoveredFile {
+	a.recoveredMu.Lock()
+	defer a.recoveredMu.Unlock()
+	return append([]RecoveredFile(nil), a.recovered...)
+}
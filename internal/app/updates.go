@@ -8,7 +8,10 @@ import (
 
 	"github.com/getsentry/sentry-go"
 
+	"hytale-launcher/internal/events"
+	"hytale-launcher/internal/notifications"
 	"hytale-launcher/internal/pkg"
+	"hytale-launcher/internal/snapshot"
 	"hytale-launcher/internal/update"
 )
 
@@ -62,11 +65,15 @@ func (a *App) ApplyUpdates() error {
 
 	slog.Info("applying updates")
 
+	// Protect worlds and settings before the game files change
+	a.autoSnapshotAll(snapshot.ReasonUpdate)
+
 	// Apply updates through the updater
 	if err := a.Updater.ApplyUpdates(a.State); err != nil {
 		sentry.CaptureException(err)
 		slog.Error("failed to apply updates", "error", err)
 		a.Emit("update:error", err.Error())
+		a.notify(events.UpdateFailed, notifications.TypeError, "Update failed", err.Error())
 		return err
 	}
 
@@ -81,6 +88,7 @@ func (a *App) ApplyUpdates() error {
 
 	slog.Info("updates applied successfully")
 	a.Emit("update:complete")
+	a.notify(events.UpdateComplete, notifications.TypeSuccess, "Update finished", "Hytale is up to date and ready to play.")
 	return nil
 }
 
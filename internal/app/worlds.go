@@ -0,0 +1,222 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/snapshot"
+	"hytale-launcher/internal/worlds"
+)
+
+// serverWorldsDir is the storage subdirectory holding the server's worlds.
+const serverWorldsDir = "package/game/latest/Server/universe/worlds"
+
+// worldRoot returns the directory holding the worlds of a target. Client
+// worlds are in the user directory of profile, or in the shared UserData
+// directory if profile is empty.
+func (a *App) worldRoot(target, profile string) (string, error) {
+	switch target {
+	case worlds.TargetClient:
+		dir, err := a.profileUserDir(profile)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir.Path(), snapshot.SavesDir), nil
+	case worlds.TargetServer:
+		return hytale.InStorageDir(serverWorldsDir), nil
+	}
+	return "", fmt.Errorf("unknown target %q", target)
+}
+
+// requireServerStopped refuses to touch server worlds while the server runs.
+func (a *App) requireServerStopped(target string) error {
+	if target == worlds.TargetServer && a.IsServerRunning() {
+		return errors.New("stop the server before changing its worlds")
+	}
+	return nil
+}
+
+// beforeWorldWrite prepares a target for receiving a world. Client worlds are
+// snapshotted first so an overwrite can be undone.
+func (a *App) beforeWorldWrite(target, profile string) error {
+	if err := a.requireServerStopped(target); err != nil {
+		return err
+	}
+	if target == worlds.TargetClient {
+		dir, err := a.profileUserDir(profile)
+		if err != nil {
+			return err
+		}
+		a.autoSnapshot(dir, snapshot.ReasonImport)
+	}
+	return nil
+}
+
+// GetWorlds returns the worlds in the shared UserData directory, in the
+// user directories of profiles that have their own, and in the server
+// directory.
+func (a *App) GetWorlds() ([]worlds.World, error) {
+	var result []worlds.World
+	for _, dir := range a.userDirs() {
+		found, err := worlds.List(worlds.TargetClient, filepath.Join(dir.Path(), snapshot.SavesDir))
+		if err != nil {
+			return nil, err
+		}
+		for i := range found {
+			found[i].Profile = dir.Profile
+		}
+		result = append(result, found...)
+	}
+
+	found, err := worlds.List(worlds.TargetServer, hytale.InStorageDir(serverWorldsDir))
+	if err != nil {
+		return nil, err
+	}
+	return append(result, found...), nil
+}
+
+// ExportWorld writes a client or server world to a portable .zip archive.
+// profile selects the user directory of a client world; empty is the shared
+// UserData directory.
+func (a *App) ExportWorld(target, profile, name, path string) error {
+	if err := a.requireServerStopped(target); err != nil {
+		return err
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		return errors.New("world archive must have a .zip extension")
+	}
+
+	root, err := a.worldRoot(target, profile)
+	if err != nil {
+		return err
+	}
+	if err := worlds.ValidateName(name); err != nil {
+		return err
+	}
+
+	w := worlds.World{Name: name, Target: target, Profile: profile, Path: filepath.Join(root, name)}
+	if err := worlds.Export(w, a.GetGameVersion(), path); err != nil {
+		return err
+	}
+
+	slog.Info("exported world", "target", target, "world", name, "path", path)
+	return nil
+}
+
+// ImportWorldRequest contains parameters for importing a world archive.
+type ImportWorldRequest struct {
+	// Path is the world archive to import.
+	Path string `json:"path"`
+
+	// Target is "client" or "server".
+	Target string `json:"target"`
+
+	// Profile selects the player profile whose user directory receives a
+	// client world. Empty is the shared UserData directory.
+	Profile string `json:"profile,omitempty"`
+
+	// Name optionally renames the world. Empty keeps the exported name.
+	Name string `json:"name,omitempty"`
+
+	// Collision is "fail", "rename" or "overwrite".
+	Collision string `json:"collision"`
+}
+
+// ImportWorld imports a world archive into the client or the server and
+// returns the name it was imported under.
+func (a *App) ImportWorld(req ImportWorldRequest) (string, error) {
+	root, err := a.worldRoot(req.Target, req.Profile)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(filepath.Ext(req.Path), ".zip") {
+		return "", errors.New("world archive must have a .zip extension")
+	}
+	if err := a.beforeWorldWrite(req.Target, req.Profile); err != nil {
+		return "", err
+	}
+
+	name, err := worlds.Import(req.Path, root, req.Name, req.Collision)
+	if err != nil {
+		return "", err
+	}
+
+	slog.Info("imported world", "target", req.Target, "profile", req.Profile, "world", name, "path", req.Path)
+	a.Emit("worlds:changed", map[string]interface{}{
+		"target":  req.Target,
+		"profile": req.Profile,
+		"world":   name,
+	})
+	return name, nil
+}
+
+// CopyWorldRequest contains parameters for copying a world between the
+// client and the server.
+type CopyWorldRequest struct {
+	// From is the source location, "client" or "server".
+	From string `json:"from"`
+
+	// FromProfile selects the user directory of a client source world.
+	// Empty is the shared UserData directory.
+	FromProfile string `json:"fromProfile,omitempty"`
+
+	// Name is the world to copy.
+	Name string `json:"name"`
+
+	// To is the destination location, "client" or "server".
+	To string `json:"to"`
+
+	// ToProfile selects the user directory receiving a client copy. Empty
+	// is the shared UserData directory.
+	ToProfile string `json:"toProfile,omitempty"`
+
+	// NewName optionally renames the copy. Empty keeps the name.
+	NewName string `json:"newName,omitempty"`
+
+	// Collision is "fail", "rename" or "overwrite".
+	Collision string `json:"collision"`
+}
+
+// CopyWorld copies a world between user directories and the server
+// directory and returns the name of the copy.
+func (a *App) CopyWorld(req CopyWorldRequest) (string, error) {
+	srcRoot, err := a.worldRoot(req.From, req.FromProfile)
+	if err != nil {
+		return "", err
+	}
+	dstRoot, err := a.worldRoot(req.To, req.ToProfile)
+	if err != nil {
+		return "", err
+	}
+	if err := worlds.ValidateName(req.Name); err != nil {
+		return "", err
+	}
+	if err := a.requireServerStopped(req.From); err != nil {
+		return "", err
+	}
+	if err := a.beforeWorldWrite(req.To, req.ToProfile); err != nil {
+		return "", err
+	}
+
+	newName := req.NewName
+	if newName == "" {
+		newName = req.Name
+	}
+
+	name, err := worlds.Copy(filepath.Join(srcRoot, req.Name), dstRoot, newName, req.Collision)
+	if err != nil {
+		return "", err
+	}
+
+	slog.Info("copied world", "from", req.From, "to", req.To, "world", req.Name, "as", name)
+	a.Emit("worlds:changed", map[string]interface{}{
+		"target":  req.To,
+		"profile": req.ToProfile,
+		"world":   name,
+	})
+	return name, nil
+}
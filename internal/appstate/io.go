@@ -13,13 +13,24 @@ import (
 	"hytale-launcher/internal/build"
 	"hytale-launcher/internal/crypto"
 	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/keyring"
 )
 
 // encryptionKeyName is the keyring key name used for state file encryption.
 const encryptionKeyName = "B7F94324-4365-4EB7-A3FC-7FADAA2EEA2F"
 
+func init() {
+	keyring.RegisterKey(encryptionKeyName)
+}
+
 // writeFile marshals the state to JSON and writes it to the encrypted env file.
+// It refuses to overwrite a file written by a newer launcher.
 func (s *State) writeFile() error {
+	if _, newer := newerChannels.Load(s.Channel); newer {
+		return fmt.Errorf("%w: not saving state for channel %s", ErrNewerSchema, s.Channel)
+	}
+
+	s.SchemaVersion = SchemaVersion
 	data, err := json.Marshal(s)
 	if err != nil {
 		return fmt.Errorf("error marshaling launcher state for write: %w", err)
@@ -66,9 +77,10 @@ func validatePlatform(s *State) error {
 // The IsNew flag is set to true to indicate a fresh state.
 func New(channel string) *State {
 	return &State{
-		Channel:  channel,
-		IsNew:    true,
-		Platform: build.GetPlatform(),
+		SchemaVersion: SchemaVersion,
+		Channel:       channel,
+		IsNew:         true,
+		Platform:      build.GetPlatform(),
 	}
 }
 
@@ -77,27 +89,128 @@ var ErrNotFound = errors.New("state not found")
 
 // Load attempts to load an existing state from disk for the given channel.
 // If the state file doesn't exist, it returns ErrNotFound.
+// Older files are migrated to SchemaVersion after backing them up; files from
+// a newer launcher are rejected with ErrNewerSchema.
 // Returns the state and any error that occurred during loading or validation.
 func Load(channel string) (*State, error) {
+	s, from, err := read(channel)
+	if errors.Is(err, ErrNewerSchema) {
+		newerChannels.Store(channel, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if from < SchemaVersion {
+		if _, err := backupFile(s.envFile(), from); err != nil {
+			return nil, err
+		}
+		if err := s.writeFile(); err != nil {
+			return nil, fmt.Errorf("failed to save migrated launcher state: %w", err)
+		}
+		slog.Info("migrated launcher state",
+			"channel", channel,
+			"from", from,
+			"to", SchemaVersion,
+		)
+	}
+
+	return s, nil
+}
+
+// Read loads the state of a channel like Load, but never writes to disk:
+// older files are migrated in memory only. It is meant for scans that only
+// inspect the state.
+func Read(channel string) (*State, error) {
+	s, _, err := read(channel)
+	return s, err
+}
+
+// read loads and validates the state of a channel, migrating it in memory,
+// and returns it with the schema version it was written with.
+func read(channel string) (*State, int, error) {
 	s := &State{
 		Channel: channel,
 	}
 
-	data, err := crypto.ReadFile(s.envFile(), encryptionKeyName)
+	// A file from a newer launcher is reported as such rather than treated
+	// as corrupt, so its backup is not loaded in its place.
+	var from int
+	var newerErr error
+	err := crypto.LoadFile(s.envFile(), encryptionKeyName, func(data []byte) error {
+		migrated, v, err := migrate(channel, data)
+		if errors.Is(err, ErrNewerSchema) {
+			newerErr = err
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		loaded := &State{Channel: channel}
+		if err := json.Unmarshal(migrated, loaded); err != nil {
+			return err
+		}
+		s, from = loaded, v
+		return nil
+	})
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, ErrNotFound
+			return nil, 0, ErrNotFound
 		}
-		return nil, err
+		return nil, 0, err
 	}
-
-	if err := json.Unmarshal(data, s); err != nil {
-		return nil, err
+	if newerErr != nil {
+		return nil, 0, newerErr
 	}
 
 	if err := validatePlatform(s); err != nil {
+		return nil, 0, err
+	}
+	return s, from, nil
+}
+
+// Import saves state exported from another machine as the state of channel,
+// re-encrypting it with this machine's key. Installs that do not exist on
+// this machine are dropped and the platform is reset, so that the launcher
+// installs them again.
+func Import(channel string, data []byte) (*State, error) {
+	migrated, _, err := migrate(channel, data)
+	if err != nil {
 		return nil, err
 	}
 
+	s := New(channel)
+	if err := json.Unmarshal(migrated, s); err != nil {
+		return nil, fmt.Errorf("invalid launcher state: %w", err)
+	}
+	s.Channel = channel
+	s.Platform = build.GetPlatform()
+
+	for identifier, deps := range s.Dependencies {
+		for version, dep := range deps {
+			if dep.Path == "" {
+				continue
+			}
+			if _, err := os.Stat(dep.Path); err != nil {
+				slog.Info("dropping imported install missing on this machine",
+					"channel", channel,
+					"identifier", identifier,
+					"path", dep.Path,
+				)
+				delete(deps, version)
+			}
+		}
+		if len(deps) == 0 {
+			delete(s.Dependencies, identifier)
+		}
+	}
+	if len(s.Dependencies) == 0 {
+		s.OfflineReady = false
+	}
+
+	if err := s.writeFile(); err != nil {
+		return nil, err
+	}
 	return s, nil
 }
@@ -0,0 +1,116 @@
+package appstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// SchemaVersion is the current version of the state file format.
+//
+// History:
+//
+//	1: files written before the schema version was recorded.
+//	2: adds schema_version and per-dependency last_launched times, and no
+//	   longer stores is_new.
+const SchemaVersion = 2
+
+// schemaKey is the JSON name of the schema version field.
+const schemaKey = "schema_version"
+
+// ErrNewerSchema is returned when a state file was written by a newer
+// launcher. Such files are never overwritten.
+var ErrNewerSchema = errors.New("launcher state was written by a newer launcher")
+
+// migration upgrades raw state from one schema version to the next.
+type migration func(raw map[string]any) error
+
+// migrations holds the migration from version i+1 to i+2 at index i.
+var migrations = []migration{
+	migrateV1,
+}
+
+// newerChannels records the channels whose state file is too new, so that
+// the fallback state used in its place is never saved over it.
+var newerChannels sync.Map
+
+// migrateV1 upgrades unversioned state. Version 1 saved is_new along with the
+// state, so a state created fresh claimed to be new on every later load; it
+// only describes the state in memory and is dropped. last_launched is optional
+// and simply absent.
+func migrateV1(raw map[string]any) error {
+	delete(raw, "is_new")
+	return nil
+}
+
+// schemaOf returns the schema version of raw state.
+func schemaOf(raw map[string]any) (int, error) {
+	v, ok := raw[schemaKey]
+	if !ok {
+		return 1, nil
+	}
+	n, ok := v.(float64)
+	if !ok || n < 1 || n != float64(int(n)) {
+		return 0, fmt.Errorf("invalid %s %v", schemaKey, v)
+	}
+	return int(n), nil
+}
+
+// migrate upgrades state JSON to SchemaVersion. It returns the upgraded JSON
+// and the version the data was written with.
+func migrate(channel string, data []byte) ([]byte, int, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, 0, err
+	}
+
+	from, err := schemaOf(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+	if from > SchemaVersion {
+		return nil, from, fmt.Errorf(
+			"%w: channel %s uses state schema %d but this launcher supports up to %d; update the launcher",
+			ErrNewerSchema, channel, from, SchemaVersion,
+		)
+	}
+	if from == SchemaVersion {
+		return data, from, nil
+	}
+
+	for v := from; v < SchemaVersion; v++ {
+		if err := migrations[v-1](raw); err != nil {
+			return nil, from, fmt.Errorf("failed to migrate launcher state from schema %d: %w", v, err)
+		}
+		raw[schemaKey] = v + 1
+	}
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, from, err
+	}
+	return migrated, from, nil
+}
+
+// backupFile copies the state file before it is migrated. An existing backup
+// of the same version is kept, as it is the oldest copy.
+func backupFile(path string, version int) (string, error) {
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if _, err := os.Stat(backup); err == nil {
+		return backup, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up launcher state: %w", err)
+	}
+
+	slog.Info("backed up launcher state before migration", "path", backup)
+	return backup, nil
+}
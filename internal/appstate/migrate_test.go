@@ -0,0 +1,181 @@
+package appstate
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/keyring"
+)
+
+// TestMain points the storage directory and keyring at a temporary directory.
+// The storage directory is resolved once per process, so tests use distinct
+// channels instead of distinct directories.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "appstate")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("APPDATA", "")
+	os.Setenv("XDG_DATA_HOME", dir)
+	os.Setenv(keyring.PassphraseEnv, "test passphrase")
+	keyring.UseFile(filepath.Join(dir, "secrets.json"))
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// readFixture returns the contents of a file in testdata.
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// installFixture writes a fixture as the unencrypted state file of channel
+// and returns its path.
+func installFixture(t *testing.T, channel, name string) string {
+	t.Helper()
+	dir := hytale.ChannelDir(channel)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "env.json")
+	if err := os.WriteFile(path, readFixture(t, name), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMigrateV1(t *testing.T) {
+	migrated, from, err := migrate("release", readFixture(t, "v1.json"))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if from != 1 {
+		t.Fatalf("from = %d, want 1", from)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(migrated, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if v := raw[schemaKey]; v != float64(SchemaVersion) {
+		t.Fatalf("%s = %v, want %d", schemaKey, v, SchemaVersion)
+	}
+	if _, ok := raw["is_new"]; ok {
+		t.Fatal("is_new survived the migration")
+	}
+
+	var s State
+	if err := json.Unmarshal(migrated, &s); err != nil {
+		t.Fatal(err)
+	}
+	dep := s.Dependencies["game"]["2026.01.15-abc123"]
+	if dep.BuildID != 3401 || !s.OfflineReady {
+		t.Fatalf("migration lost data: %+v", s)
+	}
+}
+
+func TestMigrateRejectsNewer(t *testing.T) {
+	_, from, err := migrate("release", readFixture(t, "newer.json"))
+	if !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("err = %v, want ErrNewerSchema", err)
+	}
+	if from != 99 {
+		t.Fatalf("from = %d, want 99", from)
+	}
+}
+
+func TestMigrateRejectsCorrupt(t *testing.T) {
+	if _, _, err := migrate("release", readFixture(t, "corrupt.json")); err == nil {
+		t.Fatal("corrupt state migrated")
+	}
+}
+
+func TestLoadMigratesAndBacksUp(t *testing.T) {
+	path := installFixture(t, "release", "v1.json")
+
+	s, err := Load("release")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s.SchemaVersion != SchemaVersion || s.IsNew {
+		t.Fatalf("unexpected state: %+v", s)
+	}
+
+	backup, err := os.ReadFile(path + ".v1.bak")
+	if err != nil {
+		t.Fatalf("expected a v1 backup: %v", err)
+	}
+	if string(backup) != string(readFixture(t, "v1.json")) {
+		t.Fatal("backup differs from the original file")
+	}
+
+	// The migrated file loads without another migration.
+	if err := os.Remove(path + ".v1.bak"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load("release"); err != nil {
+		t.Fatalf("Load after migration: %v", err)
+	}
+	if _, err := os.Stat(path + ".v1.bak"); !os.IsNotExist(err) {
+		t.Fatalf("migrated state was backed up again: %v", err)
+	}
+}
+
+func TestLoadKeepsNewer(t *testing.T) {
+	path := installFixture(t, "migrate-newer", "newer.json")
+
+	if _, err := Load("migrate-newer"); !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("Load err = %v, want ErrNewerSchema", err)
+	}
+
+	New("migrate-newer").Save("test")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(readFixture(t, "newer.json")) {
+		t.Fatal("state from a newer launcher was overwritten")
+	}
+	if _, err := os.Stat(path + ".v99.bak"); !os.IsNotExist(err) {
+		t.Fatalf("newer state was backed up: %v", err)
+	}
+}
+
+func TestLoadRejectsCorrupt(t *testing.T) {
+	installFixture(t, "migrate-corrupt", "corrupt.json")
+
+	_, err := Load("migrate-corrupt")
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("Load err = %v, want a parse error", err)
+	}
+}
+
+func TestBackupFileKeepsOldest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "env.json")
+	if err := os.WriteFile(path, []byte("first"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backupFile(path, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("second"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := backupFile(path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(backup); string(data) != "first" {
+		t.Fatalf("backup = %q, want the oldest copy", data)
+	}
+}
@@ -5,6 +5,7 @@ package appstate
 import (
 	"log/slog"
 	"path/filepath"
+	"time"
 
 	"hytale-launcher/internal/build"
 	"hytale-launcher/internal/logging"
@@ -12,12 +13,13 @@ import (
 
 // State represents the persistent application state.
 type State struct {
-	Channel      string                    `json:"channel"`
-	IsNew        bool                      `json:"is_new,omitempty"`
-	Platform     *build.Platform           `json:"platform,omitempty"`
-	Dependencies map[string]map[string]Dep `json:"dependencies,omitempty"`
-	OfflineReady bool                      `json:"offline_ready,omitempty"`
-	DataDir      string                    `json:"data_dir,omitempty"`
+	SchemaVersion int                       `json:"schema_version"`
+	Channel       string                    `json:"channel"`
+	IsNew         bool                      `json:"-"`
+	Platform      *build.Platform           `json:"platform,omitempty"`
+	Dependencies  map[string]map[string]Dep `json:"dependencies,omitempty"`
+	OfflineReady  bool                      `json:"offline_ready,omitempty"`
+	DataDir       string                    `json:"data_dir,omitempty"`
 }
 
 // Dep represents a dependency with version, path, and signature information.
@@ -29,6 +31,9 @@ type Dep struct {
 	Path    string `json:"path,omitempty"`
 	SigDir  string `json:"sig_dir,omitempty"`
 	SigFile string `json:"sig_file,omitempty"`
+
+	// LastLaunched is when the game was last launched from this install.
+	LastLaunched time.Time `json:"last_launched,omitzero"`
 }
 
 // Auth represents authentication state for API requests.
@@ -137,6 +142,15 @@ func (s *State) GetDependency(identifier string) *Dep {
 	return nil
 }
 
+// MarkLaunched records the launch time on every version of a dependency.
+func (s *State) MarkLaunched(identifier string, at time.Time) {
+	deps := s.getDeps(identifier)
+	for version, dep := range deps {
+		dep.LastLaunched = at
+		deps[version] = dep
+	}
+}
+
 // RemoveDependency removes a specific version of a dependency for a given identifier.
 // If the identifier's dependency map becomes empty, the identifier entry is also removed.
 func (s *State) RemoveDependency(identifier string, version string) {
@@ -17,6 +17,7 @@ import (
 
 	"hytale-launcher/internal/account"
 	"hytale-launcher/internal/crypto"
+	"hytale-launcher/internal/ioutil"
 )
 
 // storageDir is a function that returns the application storage directory.
@@ -83,8 +84,8 @@ func (c *Controller) Init() error {
 			"file", filePath,
 		)
 
-		// Try to remove the corrupted file
-		if removeErr := os.Remove(filePath); removeErr != nil {
+		// Try to remove the corrupted file and its backup
+		if removeErr := ioutil.RemoveWithBackup(filePath); removeErr != nil {
 			sentry.CaptureException(removeErr)
 			slog.Error("failed to remove invalid account file",
 				"file", filePath,
@@ -230,6 +231,23 @@ func (c *Controller) SetAccount(acct *account.Account, client *http.Client) {
 	c.SaveAccount("account_set")
 }
 
+// ImportAccount replaces the current account with plaintext account data
+// exported from another machine and saves it with this machine's key.
+func (c *Controller) ImportAccount(data []byte) error {
+	filePath := getAccountFilePath()
+	if filePath == "" {
+		return errors.New("account storage is not configured")
+	}
+
+	acct, err := account.Decode(filePath, data)
+	if err != nil {
+		return err
+	}
+	c.restore(acct)
+	c.SaveAccount("account_imported")
+	return nil
+}
+
 // Logout clears the current session and removes the account file.
 func (c *Controller) Logout() error {
 	c.mu.Lock()
@@ -242,7 +260,8 @@ func (c *Controller) Logout() error {
 		return nil
 	}
 
-	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+	// The backup holds the same tokens, so it has to go too.
+	if err := ioutil.RemoveWithBackup(filePath); err != nil {
 		return err
 	}
 
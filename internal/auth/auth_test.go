@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/keyring"
+)
+
+func TestLogoutRemovesBackup(t *testing.T) {
+	dir := t.TempDir()
+	SetStorageDir(func() string { return dir })
+	t.Cleanup(func() { SetStorageDir(nil) })
+
+	// Keep the account key for the restart.
+	t.Setenv(keyring.PassphraseEnv, "test passphrase")
+	keyring.UseFile(filepath.Join(dir, "secrets.json"))
+
+	data := []byte(`{"profiles":[{"uuid":"p1","username":"steve","token":{"access_token":"a","refresh_token":"r"}}]}`)
+
+	var c Controller
+	if err := c.ImportAccount(data); err != nil {
+		t.Fatalf("ImportAccount: %v", err)
+	}
+	// A second save moves the first one to the backup.
+	c.SaveAccount("test")
+	if _, err := os.Stat(getAccountFilePath() + ioutil.BackupSuffix); err != nil {
+		t.Fatalf("expected an account backup: %v", err)
+	}
+
+	if err := c.Logout(); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+
+	var restarted Controller
+	if err := restarted.Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if acct := restarted.GetAccount(); acct != nil {
+		t.Fatalf("account restored after logout: %+v", acct)
+	}
+}
@@ -50,6 +50,13 @@ func DebugLogging() bool {
 	return ok
 }
 
+// JSONLogging returns true if launcher logs should be written as JSON lines,
+// as requested by the HYTALE_LAUNCHER_JSON_LOGGING environment variable.
+func JSONLogging() bool {
+	_, ok := os.LookupEnv("HYTALE_LAUNCHER_JSON_LOGGING")
+	return ok
+}
+
 // TestRunBinaries returns true if test run binaries should be executed.
 // In dev mode, this can be disabled via the HYTALE_LAUNCHER_NO_TEST_RUN_BINARIES
 // environment variable.
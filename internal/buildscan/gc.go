@@ -0,0 +1,42 @@
+package buildscan
+
+import (
+	"log/slog"
+	"os"
+)
+
+// GCResult reports what garbage collection removed or would remove.
+type GCResult struct {
+	// DryRun is set when nothing was actually deleted.
+	DryRun bool
+
+	// Removed are the leftovers deleted, or that would be deleted.
+	Removed []Leftover
+
+	// Freed is the number of bytes reclaimed, or that would be reclaimed.
+	Freed int64
+
+	// Failed lists leftovers that could not be deleted, with the error.
+	Failed map[string]string
+}
+
+// CollectGarbage deletes the leftovers found by a detailed scan. With dryRun
+// it only reports what would be deleted.
+func CollectGarbage(dryRun bool) *GCResult {
+	report := Scan()
+	result := &GCResult{DryRun: dryRun, Failed: make(map[string]string)}
+
+	for _, l := range report.Leftovers {
+		if !dryRun {
+			slog.Info("removing leftover", "kind", l.Kind, "path", l.Path, "size", l.Size)
+			if err := os.RemoveAll(l.Path); err != nil {
+				slog.Warn("failed to remove leftover", "path", l.Path, "error", err)
+				result.Failed[l.Path] = err.Error()
+				continue
+			}
+		}
+		result.Removed = append(result.Removed, l)
+		result.Freed += l.Size
+	}
+	return result
+}
@@ -3,10 +3,10 @@ package buildscan
 
 import (
 	"errors"
-	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"hytale-launcher/internal/appstate"
 	"hytale-launcher/internal/deletex"
@@ -30,6 +30,22 @@ type GameInstall struct {
 
 	// HasSignature indicates whether a signature file exists for this install.
 	HasSignature bool
+
+	// The fields below are only filled in by a detailed scan.
+
+	// Missing is set when the app state records the install but its
+	// directory does not exist.
+	Missing bool
+
+	// Size is the total size of the installation directory in bytes.
+	Size int64
+
+	// LastLaunched is when the game was last launched from this install.
+	LastLaunched time.Time
+
+	// Signature is the state of the install's signature file, one of the
+	// Signature* constants.
+	Signature string
 }
 
 // Uninstall removes the game installation from disk and cleans up related state.
@@ -93,53 +109,30 @@ func (g *GameInstall) Uninstall(reporter deletex.ProgressReporter, state *appsta
 	return nil
 }
 
-// ScanInstalledGames scans the storage directory for installed game builds.
-// It walks through channel directories and identifies valid game installations
-// by checking for app state files.
-// If detailed is true, additional verification is performed on each install.
+// ScanInstalledGames scans the channel directories for installed game builds
+// recorded in each channel's app state.
+// If detailed is true, each install is also measured and checked: its size,
+// last launch, signature and whether its directory still exists.
 func ScanInstalledGames(detailed bool) []GameInstall {
-	_ = detailed // Used for future extended scanning
 	var installs []GameInstall
 
-	storageDir := hytale.StorageDir()
-
-	// Walk through the storage directory
-	err := filepath.WalkDir(storageDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return nil // Skip errors and continue
-		}
-
-		// Skip the root directory
-		if path == storageDir {
-			return nil
+	for _, channelName := range hytale.KnownChannels() {
+		path := hytale.ChannelDir(channelName)
+		if _, err := os.Stat(path); err != nil {
+			continue
 		}
 
-		// Only process directories
-		if !d.IsDir() {
-			return nil
-		}
-
-		// Check if this is a channel directory
-		channelName := d.Name()
-		if !hytale.IsKnownChannel(channelName) {
-			slog.Warn("skipping unrecognized channel directory",
-				"path", path,
-				"channel", channelName,
-			)
-			return filepath.SkipDir
-		}
-
-		// Try to load app state for this channel
-		state, err := appstate.Load(channelName)
+		// Try to read app state for this channel; a scan must not migrate it
+		state, err := appstate.Read(channelName)
 		if errors.Is(err, appstate.ErrNotFound) {
-			return nil
+			continue
 		}
 		if err != nil {
 			slog.Warn("error loading app state for game install",
 				"path", path,
 				"error", err,
 			)
-			return nil
+			continue
 		}
 
 		// Iterate through known game packages to find installations
@@ -172,17 +165,14 @@ func ScanInstalledGames(detailed bool) []GameInstall {
 					Dir:          installDir,
 					HasSignature: hasSignature,
 				}
+				if detailed {
+					inspect(&install, dep)
+				}
 
 				slog.Info("found game install", "install", install)
 				installs = append(installs, install)
 			}
 		}
-
-		return filepath.SkipDir // Don't recurse into channel directories
-	})
-
-	if err != nil {
-		slog.Error("error scanning for installed games", "error", err)
 	}
 
 	return installs
@@ -0,0 +1,279 @@
+package buildscan
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"hytale-launcher/internal/appstate"
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/ioutil"
+)
+
+// Signature states reported by a detailed scan.
+const (
+	// SignatureNone means no signature is recorded for the install.
+	SignatureNone = "none"
+
+	// SignatureMissing means a signature is recorded but the file is gone.
+	SignatureMissing = "missing"
+
+	// SignatureInvalid means the signature file is unreadable or is not a
+	// wharf signature.
+	SignatureInvalid = "invalid"
+
+	// SignatureValid means the signature file is a well-formed wharf signature.
+	SignatureValid = "valid"
+)
+
+// signatureMagic is the little-endian header of wharf signature files.
+const signatureMagic = 0x0FEF5F01
+
+// Kinds of leftovers found by Scan.
+const (
+	// LeftoverBuild is a build directory no app state refers to.
+	LeftoverBuild = "orphaned_build"
+
+	// LeftoverDownload is a temporary download that was never cleaned up.
+	LeftoverDownload = "temp_download"
+
+	// LeftoverStaging is a staging directory from an interrupted operation.
+	LeftoverStaging = "staging_dir"
+)
+
+// staleAge is how old a temporary file must be before it counts as a
+// leftover, so work in progress is never touched.
+const staleAge = time.Hour
+
+// orphanAge is how old an unreferenced build directory must be before it
+// counts as a leftover, so a build an update is still writing, or has not yet
+// recorded in app state, is never touched.
+const orphanAge = 24 * time.Hour
+
+// buildDirPattern matches numbered build directories left by demotion.
+var buildDirPattern = regexp.MustCompile(`^build-\d+$`)
+
+// stagingPatterns lists where interrupted operations leave staging
+// directories, relative to the storage directory.
+var stagingPatterns = []string{
+	"cache/modpack-*",
+	"UserData/Saves/.import-*",
+	"package/game/latest/Server/universe/worlds/.import-*",
+}
+
+// patchStagingPattern matches patch staging directories in temp directories.
+const patchStagingPattern = "hytale-patch-staging-*"
+
+// Leftover is something on disk that can be deleted to reclaim space.
+type Leftover struct {
+	// Kind is one of the Leftover* constants.
+	Kind string
+
+	// Channel is the channel the leftover belongs to, if any.
+	Channel string
+
+	// Path is the file or directory.
+	Path string
+
+	// Size is its size in bytes.
+	Size int64
+
+	// ModifiedAt is its modification time.
+	ModifiedAt time.Time
+}
+
+// Report is the result of a detailed scan.
+type Report struct {
+	// Installs are the installs recorded in app state.
+	Installs []GameInstall
+
+	// Leftovers are files and directories that can be reclaimed.
+	Leftovers []Leftover
+
+	// Reclaimable is the total size of the leftovers in bytes.
+	Reclaimable int64
+}
+
+// Scan performs a detailed scan of installs and leftovers.
+func Scan() *Report {
+	report := &Report{Installs: ScanInstalledGames(true)}
+
+	for _, channel := range hytale.KnownChannels() {
+		report.add(orphanedBuilds(channel)...)
+	}
+	report.add(staleDownloads()...)
+	report.add(stagingDirs()...)
+
+	return report
+}
+
+// add appends leftovers to the report.
+func (r *Report) add(leftovers ...Leftover) {
+	for _, l := range leftovers {
+		r.Leftovers = append(r.Leftovers, l)
+		r.Reclaimable += l.Size
+	}
+}
+
+// inspect fills in the detailed fields of an install.
+func inspect(install *GameInstall, dep appstate.Dep) {
+	install.LastLaunched = dep.LastLaunched
+	install.Signature = signatureState(dep.SigPath())
+
+	if _, err := os.Stat(install.Dir); errors.Is(err, os.ErrNotExist) {
+		install.Missing = true
+		return
+	}
+	size, err := ioutil.DirSize(install.Dir)
+	if err != nil {
+		slog.Warn("failed to measure game install", "dir", install.Dir, "error", err)
+	}
+	install.Size = size
+}
+
+// signatureState checks the signature file at path.
+func signatureState(path string) string {
+	if path == "" {
+		return SignatureNone
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return SignatureMissing
+	}
+	if err != nil {
+		return SignatureInvalid
+	}
+	defer f.Close()
+
+	var magic uint32
+	if err := binary.Read(f, binary.LittleEndian, &magic); err != nil || magic != signatureMagic {
+		return SignatureInvalid
+	}
+	// A signature has a header after the magic; an empty body is truncated.
+	if _, err := f.Read(make([]byte, 1)); err == io.EOF {
+		return SignatureInvalid
+	}
+	return SignatureValid
+}
+
+// orphanedBuilds returns the numbered build directories of a channel that
+// its app state does not refer to and that have not changed for orphanAge.
+// Channels whose state cannot be read are skipped, as without it there is no
+// telling what is in use.
+func orphanedBuilds(channel string) []Leftover {
+	gameDir := hytale.PackageDir("game", channel, "")
+	entries, err := os.ReadDir(gameDir)
+	if err != nil {
+		return nil
+	}
+
+	state, err := appstate.Read(channel)
+	if err != nil {
+		slog.Warn("skipping orphan scan for channel", "channel", channel, "error", err)
+		return nil
+	}
+	referenced := make(map[string]bool)
+	for _, deps := range state.Dependencies {
+		for _, dep := range deps {
+			if dep.Path != "" {
+				referenced[filepath.Clean(dep.Path)] = true
+			}
+		}
+	}
+
+	var orphans []Leftover
+	for _, e := range entries {
+		path := filepath.Join(gameDir, e.Name())
+		if !e.IsDir() || !buildDirPattern.MatchString(e.Name()) || referenced[path] {
+			continue
+		}
+		if l, ok := leftover(LeftoverBuild, channel, path, orphanAge); ok {
+			orphans = append(orphans, l)
+		}
+	}
+	return orphans
+}
+
+// staleDownloads returns temporary downloads left in the cache.
+func staleDownloads() []Leftover {
+	var found []Leftover
+	filepath.WalkDir(hytale.InStorageDir("cache"), func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if ok, _ := filepath.Match("dl-*", d.Name()); ok {
+				if l, ok := leftover(LeftoverDownload, "", p, staleAge); ok {
+					found = append(found, l)
+				}
+			}
+		}
+		return nil
+	})
+	return found
+}
+
+// stagingDirs returns staging directories left by interrupted operations.
+func stagingDirs() []Leftover {
+	var patterns []string
+	for _, p := range stagingPatterns {
+		patterns = append(patterns, hytale.InStorageDir(p))
+	}
+	for _, dir := range tempDirs() {
+		patterns = append(patterns, filepath.Join(dir, patchStagingPattern))
+	}
+
+	var found []Leftover
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			if seen[m] {
+				continue
+			}
+			seen[m] = true
+			if l, ok := leftover(LeftoverStaging, "", m, staleAge); ok {
+				found = append(found, l)
+			}
+		}
+	}
+	return found
+}
+
+// tempDirs returns the directories patch staging may be created in.
+func tempDirs() []string {
+	dirs := []string{os.TempDir()}
+	for _, env := range []string{"TMPDIR", "XDG_CACHE_HOME"} {
+		if dir, ok := os.LookupEnv(env); ok && dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// leftover describes the file or directory at path if it has not been
+// modified within minAge.
+func leftover(kind, channel, path string, minAge time.Duration) (Leftover, bool) {
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) < minAge {
+		return Leftover{}, false
+	}
+
+	size := info.Size()
+	if info.IsDir() {
+		size, _ = ioutil.DirSize(path)
+	}
+	return Leftover{
+		Kind:       kind,
+		Channel:    channel,
+		Path:       path,
+		Size:       size,
+		ModifiedAt: info.ModTime(),
+	}, true
+}
@@ -0,0 +1,60 @@
+package buildscan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"hytale-launcher/internal/appstate"
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/keyring"
+)
+
+// TestMain points the storage directory and keyring at a temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "buildscan")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("APPDATA", "")
+	os.Setenv("XDG_DATA_HOME", dir)
+	os.Setenv(keyring.PassphraseEnv, "test passphrase")
+	keyring.UseFile(filepath.Join(dir, "secrets.json"))
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// makeBuild creates a build directory of channel last modified at modTime.
+func makeBuild(t *testing.T, channel, name string, modTime time.Time) string {
+	t.Helper()
+	path := filepath.Join(hytale.PackageDir("game", channel, ""), name)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOrphanedBuildsRequiresAge(t *testing.T) {
+	old := makeBuild(t, "gc-age", "build-1", time.Now().Add(-2*orphanAge))
+	makeBuild(t, "gc-age", "build-2", time.Now())
+	appstate.New("gc-age").Save("test")
+
+	orphans := orphanedBuilds("gc-age")
+	if len(orphans) != 1 || orphans[0].Path != old {
+		t.Fatalf("orphans = %+v, want only %s", orphans, old)
+	}
+}
+
+func TestOrphanedBuildsSkipsChannelWithoutState(t *testing.T) {
+	makeBuild(t, "gc-nostate", "build-1", time.Now().Add(-2*orphanAge))
+
+	if orphans := orphanedBuilds("gc-nostate"); len(orphans) != 0 {
+		t.Fatalf("orphans = %+v for a channel without state", orphans)
+	}
+}
@@ -0,0 +1,215 @@
+// Package configexport packages the launcher configuration into a single
+// passphrase-encrypted file, so it can be moved to another machine.
+package configexport
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+
+	"hytale-launcher/internal/playerprofile"
+	"hytale-launcher/internal/servers"
+	"hytale-launcher/internal/settings"
+)
+
+// Format identifies launcher configuration exports.
+const Format = "hytale-launcher-config"
+
+// FormatVersion is the current version of the export format. Data is
+// encrypted with AES-GCM and prefixed with the nonce.
+const FormatVersion = 1
+
+// Extension is the file extension of configuration exports.
+const Extension = ".hlconfig"
+
+// MinPassphraseLen is the shortest passphrase accepted for an export.
+const MinPassphraseLen = 8
+
+// Key derivation parameters for the export passphrase.
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	argonKeyLen  = 32
+	saltLen      = 16
+)
+
+// ErrWrongPassphrase is returned when an export cannot be decrypted.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted export")
+
+// ErrBadKDFParams is returned for exports whose key derivation parameters
+// differ from the ones Write uses. Reading them could exhaust memory or
+// crash the key derivation.
+var ErrBadKDFParams = errors.New("unsupported launcher config export key derivation parameters")
+
+// Payload is the launcher configuration carried by an export. Secrets are
+// stored in plaintext inside the encrypted file, so that they can be
+// re-encrypted with the keyring of the machine they are imported on.
+type Payload struct {
+	// CreatedAt is when the export was made.
+	CreatedAt time.Time `json:"created_at"`
+
+	// LauncherVersion is the version of the launcher that made the export.
+	LauncherVersion string `json:"launcher_version"`
+
+	// States holds the app state of each channel as JSON.
+	States map[string]json.RawMessage `json:"states,omitempty"`
+
+	// PlayerProfiles are the offline player profiles.
+	PlayerProfiles []*playerprofile.PlayerProfile `json:"player_profiles,omitempty"`
+
+	// PlayerName is the saved offline player name.
+	PlayerName string `json:"player_name,omitempty"`
+
+	// Settings are the launcher settings.
+	Settings *settings.Settings `json:"settings,omitempty"`
+
+	// Servers is the multiplayer server list.
+	Servers []servers.Server `json:"servers,omitempty"`
+
+	// Account is the signed-in account as JSON, if accounts were included.
+	Account json.RawMessage `json:"account,omitempty"`
+}
+
+// envelope is the on-disk form of an export.
+type envelope struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	Salt    []byte `json:"salt"`
+	Data    []byte `json:"data"`
+}
+
+// ValidatePassphrase checks that a passphrase is long enough to protect an
+// export.
+func ValidatePassphrase(passphrase string) error {
+	if len([]rune(passphrase)) < MinPassphraseLen {
+		return fmt.Errorf("passphrase must be at least %d characters", MinPassphraseLen)
+	}
+	return nil
+}
+
+// Write encrypts payload with a key derived from passphrase and writes it to
+// path.
+func Write(path, passphrase string, payload *Payload) error {
+	if err := ValidatePassphrase(passphrase); err != nil {
+		return err
+	}
+
+	plain, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal launcher config: %w", err)
+	}
+
+	env := envelope{
+		Format:  Format,
+		Version: FormatVersion,
+		KDF:     "argon2id",
+		Time:    argonTime,
+		Memory:  argonMemory,
+		Threads: argonThreads,
+		Salt:    make([]byte, saltLen),
+	}
+	if _, err := rand.Read(env.Salt); err != nil {
+		return err
+	}
+
+	key := argon2.IDKey([]byte(passphrase), env.Salt, env.Time, env.Memory, env.Threads, argonKeyLen)
+	env.Data, err = seal(key, plain)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt launcher config: %w", err)
+	}
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write launcher config export: %w", err)
+	}
+	return nil
+}
+
+// Read decrypts the export at path with passphrase.
+func Read(path, passphrase string) (*Payload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read launcher config export: %w", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil || env.Format != Format {
+		return nil, errors.New("file is not a launcher config export")
+	}
+	if env.Version > FormatVersion {
+		return nil, fmt.Errorf("launcher config export version %d is newer than supported version %d", env.Version, FormatVersion)
+	}
+	if env.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported launcher config export version %d", env.Version)
+	}
+	if env.KDF != "argon2id" {
+		return nil, fmt.Errorf("unsupported launcher config export kdf %q", env.KDF)
+	}
+	if env.Time != argonTime || env.Memory != argonMemory || env.Threads != argonThreads || len(env.Salt) != saltLen {
+		return nil, ErrBadKDFParams
+	}
+
+	key := argon2.IDKey([]byte(passphrase), env.Salt, env.Time, env.Memory, env.Threads, argonKeyLen)
+	plain, err := open(key, env.Data)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(plain, &payload); err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return &payload, nil
+}
+
+// seal encrypts plain with AES-GCM, prefixing the nonce.
+func seal(key, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+// open decrypts data produced by seal.
+func open(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// newGCM returns an AES-GCM cipher for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
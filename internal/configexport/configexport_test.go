@@ -0,0 +1,128 @@
+package configexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config"+Extension)
+	payload := &Payload{PlayerName: "steve", LauncherVersion: "1.2.3"}
+
+	if err := Write(path, "correct horse", payload); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("steve")) {
+		t.Fatal("export contains the payload in plaintext")
+	}
+
+	got, err := Read(path, "correct horse")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.PlayerName != "steve" || got.LauncherVersion != "1.2.3" {
+		t.Fatalf("payload = %+v", got)
+	}
+}
+
+func TestReadWrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config"+Extension)
+	if err := Write(path, "correct horse", &Payload{PlayerName: "steve"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if _, err := Read(path, "battery staple"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("Read err = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestReadRejectsUnencrypted(t *testing.T) {
+	plain, err := json.Marshal(&Payload{PlayerName: "steve"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := validEnvelope()
+	env.Data = plain
+	path := writeEnvelope(t, env)
+
+	if _, err := Read(path, "correct horse"); err == nil {
+		t.Fatal("unencrypted export was read")
+	}
+}
+
+func TestReadRejectsKDFParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*envelope)
+	}{
+		{"zero time", func(e *envelope) { e.Time = 0 }},
+		{"zero threads", func(e *envelope) { e.Threads = 0 }},
+		{"huge memory", func(e *envelope) { e.Memory = 4294967295 }},
+		{"small memory", func(e *envelope) { e.Memory = 8 }},
+		{"short salt", func(e *envelope) { e.Salt = e.Salt[:4] }},
+		{"missing salt", func(e *envelope) { e.Salt = nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnvelope()
+			tt.modify(&env)
+			path := writeEnvelope(t, env)
+
+			if _, err := Read(path, "correct horse"); !errors.Is(err, ErrBadKDFParams) {
+				t.Fatalf("Read err = %v, want ErrBadKDFParams", err)
+			}
+		})
+	}
+}
+
+func TestReadRejectsOtherVersions(t *testing.T) {
+	for _, version := range []int{0, FormatVersion + 1} {
+		env := validEnvelope()
+		env.Version = version
+		path := writeEnvelope(t, env)
+
+		if _, err := Read(path, "correct horse"); err == nil {
+			t.Fatalf("version %d export was read", version)
+		}
+	}
+}
+
+// validEnvelope returns an envelope with the parameters Write uses.
+func validEnvelope() envelope {
+	return envelope{
+		Format:  Format,
+		Version: FormatVersion,
+		KDF:     "argon2id",
+		Time:    argonTime,
+		Memory:  argonMemory,
+		Threads: argonThreads,
+		Salt:    make([]byte, saltLen),
+		Data:    make([]byte, 64),
+	}
+}
+
+// writeEnvelope writes env to a temporary export file and returns its path.
+func writeEnvelope(t *testing.T, env envelope) string {
+	t.Helper()
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config"+Extension)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
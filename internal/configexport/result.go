@@ -0,0 +1,72 @@
+package configexport
+
+// Conflict policies control what happens when imported configuration clashes
+// with configuration already on this machine.
+const (
+	// ConflictKeep keeps the local configuration.
+	ConflictKeep = "keep"
+
+	// ConflictReplace replaces the local configuration with the imported one.
+	ConflictReplace = "replace"
+)
+
+// Items that can be imported.
+const (
+	ItemState          = "state"
+	ItemPlayerProfiles = "player_profiles"
+	ItemPlayerName     = "player_name"
+	ItemSettings       = "settings"
+	ItemServers        = "servers"
+	ItemAccount        = "account"
+)
+
+// ValidConflict reports whether policy is a known conflict policy.
+func ValidConflict(policy string) bool {
+	return policy == ConflictKeep || policy == ConflictReplace
+}
+
+// Conflict is imported configuration that clashed with local configuration.
+type Conflict struct {
+	// Item is one of the Item* constants.
+	Item string `json:"item"`
+
+	// Key identifies the entry, such as a channel, player or server address.
+	Key string `json:"key,omitempty"`
+
+	// Replaced is set if the imported entry replaced the local one.
+	Replaced bool `json:"replaced"`
+}
+
+// Result reports the outcome of an import.
+type Result struct {
+	// Imported lists the items that were imported, at least in part.
+	Imported []string `json:"imported"`
+
+	// Conflicts lists the entries that clashed with local configuration.
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+
+	// Failed maps items that could not be imported to the error.
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+// NewResult creates an empty Result.
+func NewResult() *Result {
+	return &Result{Imported: []string{}, Failed: make(map[string]string)}
+}
+
+// Import records that item was imported.
+func (r *Result) Import(item string) {
+	r.Imported = append(r.Imported, item)
+}
+
+// Conflict records conflicts for item.
+func (r *Result) Conflict(item string, replaced bool, keys ...string) {
+	for _, key := range keys {
+		r.Conflicts = append(r.Conflicts, Conflict{Item: item, Key: key, Replaced: replaced})
+	}
+}
+
+// Fail records that item could not be imported.
+func (r *Result) Fail(item string, err error) {
+	r.Failed[item] = err.Error()
+}
@@ -2,35 +2,48 @@ package crypto
 
 import (
 	"fmt"
-	"os"
 
+	"hytale-launcher/internal/ioutil"
 	"hytale-launcher/internal/keyring"
 )
 
 // ReadFile reads a file and decrypts it if necessary.
 // The keyName is used to retrieve the encryption key from the keyring.
+// If the file cannot be read or decrypted, its backup is used instead.
 func ReadFile(path string, keyName string) ([]byte, error) {
-	data, err := os.ReadFile(path)
+	var decrypted []byte
+	err := LoadFile(path, keyName, func(data []byte) error {
+		decrypted = data
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return decrypted, nil
+}
 
+// LoadFile reads and decrypts a file and passes the plaintext to load.
+// If the file cannot be read or decrypted, or load rejects it, its backup
+// is loaded instead.
+func LoadFile(path string, keyName string, load func(data []byte) error) error {
 	key, err := keyring.GetOrGenKey(keyName)
 	if err != nil {
-		return nil, fmt.Errorf("could not get encryption key %q: %w", keyName, err)
-	}
-
-	decrypted, err := Decrypt(data, key)
-	if err != nil {
-		return nil, err
+		return fmt.Errorf("could not get encryption key %q: %w", keyName, err)
 	}
 
-	return decrypted, nil
+	return ioutil.LoadWithBackup(path, func(data []byte) error {
+		decrypted, err := Decrypt(data, key)
+		if err != nil {
+			return err
+		}
+		return load(decrypted)
+	})
 }
 
 // WriteFile encrypts data and writes it to a file.
 // The keyName is used to retrieve the encryption key from the keyring.
-// The file is written with 0644 permissions.
+// The file is replaced atomically with 0644 permissions, keeping the
+// previous contents as a backup.
 func WriteFile(path string, keyName string, data []byte) error {
 	key, err := keyring.GetOrGenKey(keyName)
 	if err != nil {
@@ -42,9 +55,5 @@ func WriteFile(path string, keyName string, data []byte) error {
 		return fmt.Errorf("could not encrypt data for %q: %w", path, err)
 	}
 
-	if err := os.WriteFile(path, encrypted, 0644); err != nil {
-		return err
-	}
-
-	return nil
+	return ioutil.WriteFileAtomic(path, encrypted, 0644)
 }
@@ -11,6 +11,10 @@ import (
 // selfUpdateKeyID is the UUID used to identify the self-update key in the keyring.
 const selfUpdateKeyID = "3BA63AC3-1B08-425B-AC1A-3B19841B660D"
 
+func init() {
+	keyring.RegisterKey(selfUpdateKeyID)
+}
+
 // HMAC computes an HMAC-SHA256 of the data using the provided key,
 // and returns the result as a hexadecimal string.
 func HMAC(data, key []byte) string {
@@ -6,6 +6,8 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 )
 
 // ProgressReporter is a callback function that reports deletion progress.
@@ -57,3 +59,76 @@ func Dir(dir string, reporter ProgressReporter) error {
 	slog.Info("directory deletion complete", "dir", dir)
 	return nil
 }
+
+// DirExcept deletes everything in a directory with progress reporting,
+// except the files and directories at the paths in keep. Directories left
+// empty are removed, including dir itself.
+func DirExcept(dir string, keep []string, reporter ProgressReporter) error {
+	if len(keep) == 0 {
+		return Dir(dir, reporter)
+	}
+
+	slog.Info("scanning directory for files to delete", "dir", dir, "keep", keep)
+
+	var files, dirs []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if slices.Contains(keep, path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		} else {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	if err != nil {
+		slog.Error("failed to walk directory", "dir", dir, "error", err)
+		return err
+	}
+
+	slog.Info("found files to delete", "total", len(files))
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			slog.Error("failed to delete file", "path", file, "error", err)
+			return err
+		}
+		if reporter != nil {
+			reporter()
+		}
+	}
+
+	// Directories were walked parents first, so remove them in reverse.
+	// Those holding a kept path are not empty and stay.
+	for _, d := range slices.Backward(dirs) {
+		if holdsKept(d, keep) {
+			continue
+		}
+		if err := os.Remove(d); err != nil {
+			slog.Error("failed to remove directory", "dir", d, "error", err)
+			return err
+		}
+	}
+
+	slog.Info("directory deletion complete", "dir", dir)
+	return nil
+}
+
+// holdsKept reports whether dir contains any of the paths in keep.
+func holdsKept(dir string, keep []string) bool {
+	prefix := dir + string(filepath.Separator)
+	for _, k := range keep {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package deletex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirExcept(t *testing.T) {
+	dir := t.TempDir()
+	storage := filepath.Join(dir, "storage")
+	for _, name := range []string{
+		"UserData/Saves/World/level.json",
+		"snapshots/index/1.json",
+		"snapshots/objects/ab/abcd",
+		"settings.json",
+	} {
+		path := filepath.Join(storage, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var deleted int
+	keep := []string{filepath.Join(storage, "snapshots")}
+	if err := DirExcept(storage, keep, func() { deleted++ }); err != nil {
+		t.Fatal(err)
+	}
+
+	if deleted != 2 {
+		t.Fatalf("deleted %d files, want 2", deleted)
+	}
+	for _, name := range []string{"UserData", "settings.json"} {
+		if _, err := os.Stat(filepath.Join(storage, name)); !os.IsNotExist(err) {
+			t.Fatalf("%s was not deleted: %v", name, err)
+		}
+	}
+	for _, name := range []string{"snapshots/index/1.json", "snapshots/objects/ab/abcd"} {
+		if _, err := os.Stat(filepath.Join(storage, filepath.FromSlash(name))); err != nil {
+			t.Fatalf("%s was deleted: %v", name, err)
+		}
+	}
+}
+
+func TestDirExceptNothingKept(t *testing.T) {
+	storage := filepath.Join(t.TempDir(), "storage")
+	if err := os.MkdirAll(filepath.Join(storage, "UserData"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DirExcept(storage, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(storage); !os.IsNotExist(err) {
+		t.Fatalf("storage directory was not removed: %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package diag
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// manifestName is the path of the manifest inside a bundle.
+const manifestName = "manifest.json"
+
+// maxFileBytes caps how much of a file is included; the end is kept.
+const maxFileBytes = 5 * 1024 * 1024
+
+// Manifest records what a bundle contains and what was left out.
+type Manifest struct {
+	// CreatedAt is when the bundle was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// LauncherVersion is the version of the launcher that created it.
+	LauncherVersion string `json:"launcher_version"`
+
+	// Collected lists the files in the bundle.
+	Collected []Collected `json:"collected"`
+
+	// Omitted lists the items that could not be collected.
+	Omitted []Omitted `json:"omitted"`
+}
+
+// Collected describes a file in the bundle.
+type Collected struct {
+	// Name is the path inside the bundle.
+	Name string `json:"name"`
+
+	// Size is the number of bytes written.
+	Size int `json:"size"`
+
+	// Truncated is set when only the end of the source file was kept.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Omitted describes an item that is not in the bundle.
+type Omitted struct {
+	// Name is the path the item would have had inside the bundle.
+	Name string `json:"name"`
+
+	// Reason explains why it was left out.
+	Reason string `json:"reason"`
+}
+
+// Bundle writes a diagnostic zip. Everything added is redacted.
+type Bundle struct {
+	dest     string
+	tmp      *os.File
+	zw       *zip.Writer
+	redactor *Redactor
+	manifest Manifest
+}
+
+// Create starts a bundle that is written to dest when closed.
+func Create(dest, launcherVersion string) (*Bundle, error) {
+	tmp, err := os.Create(dest + ".tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create diagnostic bundle: %w", err)
+	}
+
+	return &Bundle{
+		dest:     dest,
+		tmp:      tmp,
+		zw:       zip.NewWriter(tmp),
+		redactor: NewRedactor(),
+		manifest: Manifest{
+			CreatedAt:       time.Now().UTC(),
+			LauncherVersion: launcherVersion,
+		},
+	}, nil
+}
+
+// write redacts data and stores it under name.
+func (b *Bundle) write(name string, data []byte, truncated bool) error {
+	redacted := b.redactor.Redact(string(data))
+
+	w, err := b.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, redacted); err != nil {
+		return err
+	}
+
+	b.manifest.Collected = append(b.manifest.Collected, Collected{
+		Name:      name,
+		Size:      len(redacted),
+		Truncated: truncated,
+	})
+	return nil
+}
+
+// AddFile adds the text file at path under name. Large files are cut to
+// their last few megabytes. Files ending in ".gz" are decompressed. A
+// missing file is recorded as omitted.
+func (b *Bundle) AddFile(name, path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		b.Omit(name, "file not found")
+		return nil
+	}
+	if err != nil {
+		b.Omit(name, err.Error())
+		return nil
+	}
+	defer f.Close()
+
+	var data []byte
+	var truncated bool
+	if strings.HasSuffix(path, ".gz") {
+		data, truncated, err = readCompressedTail(f)
+	} else {
+		data, truncated, err = readTail(f)
+	}
+	if err != nil {
+		b.Omit(name, err.Error())
+		return nil
+	}
+	return b.write(name, data, truncated)
+}
+
+// readTail reads the last maxFileBytes of f.
+func readTail(f *os.File) ([]byte, bool, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+
+	truncated := info.Size() > maxFileBytes
+	if truncated {
+		if _, err := f.Seek(-maxFileBytes, io.SeekEnd); err != nil {
+			return nil, false, err
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(f, maxFileBytes))
+	return data, truncated, err
+}
+
+// readCompressedTail decompresses r and returns its last maxFileBytes.
+func readCompressedTail(r io.Reader) ([]byte, bool, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, false, err
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(data) > maxFileBytes {
+		return data[len(data)-maxFileBytes:], true, nil
+	}
+	return data, false, nil
+}
+
+// AddJSON adds v encoded as indented JSON under name.
+func (b *Bundle) AddJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		b.Omit(name, err.Error())
+		return nil
+	}
+	return b.write(name, data, false)
+}
+
+// Omit records that an item was left out of the bundle.
+func (b *Bundle) Omit(name, reason string) {
+	b.manifest.Omitted = append(b.manifest.Omitted, Omitted{
+		Name:   name,
+		Reason: b.redactor.Redact(reason),
+	})
+}
+
+// Manifest returns what has been collected and omitted so far.
+func (b *Bundle) Manifest() Manifest {
+	return b.manifest
+}
+
+// Close writes the manifest and moves the bundle into place.
+func (b *Bundle) Close() error {
+	defer os.Remove(b.tmp.Name())
+
+	data, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		b.tmp.Close()
+		return err
+	}
+	w, err := b.zw.Create(manifestName)
+	if err != nil {
+		b.tmp.Close()
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		b.tmp.Close()
+		return err
+	}
+	if err := b.zw.Close(); err != nil {
+		b.tmp.Close()
+		return fmt.Errorf("failed to write diagnostic bundle: %w", err)
+	}
+	if err := b.tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(b.tmp.Name(), b.dest)
+}
+
+// Abort discards the bundle.
+func (b *Bundle) Abort() {
+	b.tmp.Close()
+	os.Remove(b.tmp.Name())
+}
@@ -0,0 +1,64 @@
+package diag
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddFileDecompressesArchives(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "hytale-launcher-20260113-172939.log.gz")
+	f, err := os.Create(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	zw.Write([]byte("level=ERROR msg=crashed\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	dest := filepath.Join(dir, "bundle.zip")
+	b, err := Create(dest, "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddFile("logs/launcher/previous.log", src); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readZipFile(t, dest, "logs/launcher/previous.log"); got != "level=ERROR msg=crashed\n" {
+		t.Fatalf("bundled log = %q", got)
+	}
+}
+
+// readZipFile returns the contents of name in the zip at path.
+func readZipFile(t *testing.T, path, name string) string {
+	t.Helper()
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,103 @@
+// Package diag builds diagnostic bundles for support: a zip of logs, state
+// and system information with tokens, UUIDs and user paths scrubbed.
+package diag
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Replacement markers written in place of scrubbed values.
+const (
+	redactedToken = "[REDACTED]"
+	redactedUUID  = "[UUID]"
+	redactedUser  = "<user>"
+	redactedHome  = "~"
+)
+
+var (
+	// jwtPattern matches JSON web tokens.
+	jwtPattern = regexp.MustCompile(`eyJ[\w-]+\.[\w-]+\.[\w-]*`)
+
+	// bearerPattern matches bearer credentials in headers.
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[\w.~+/=-]+`)
+
+	// secretPattern matches secret-looking keys followed by a value, in JSON,
+	// slog attributes, headers and query strings. A bare "code" is only a secret as
+	// the OAuth authorization code query parameter, so that exit and error
+	// codes are kept.
+	secretPattern = regexp.MustCompile(`(?im)("?[\w-]*(?:token|password|secret|session|authorization|api[_-]?key|cookie|code_verifier)"?\s*[:=]\s*|(?:^|[?&])code=)((?:bearer\s+)?(?:"(?:[^"\\]|\\.)*"|[^\s&,{}\[\]]+))`)
+
+	// uuidPattern matches UUIDs, which identify accounts and profiles.
+	uuidPattern = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{12}\b`)
+
+	// userPathPattern matches the user name in home directory paths, also
+	// with JSON-escaped backslashes.
+	userPathPattern = regexp.MustCompile(`(?i)((?:[a-z]:(?:\\\\|\\|/)Users(?:\\\\|\\|/))|/home/|/Users/)([^\\/\s"':]+)`)
+)
+
+// Redactor scrubs secrets and personal information from text.
+type Redactor struct {
+	homes []string
+}
+
+// NewRedactor creates a Redactor that also replaces the current user's home
+// directory.
+func NewRedactor() *Redactor {
+	r := &Redactor{}
+	if home, err := os.UserHomeDir(); err == nil && len(home) > 1 {
+		r.homes = append(r.homes,
+			home,
+			strings.ReplaceAll(home, `\`, `\\`),
+			strings.ReplaceAll(home, `\`, `/`),
+		)
+	}
+	return r
+}
+
+// Redact returns s with tokens, UUIDs and home directory paths replaced.
+func (r *Redactor) Redact(s string) string {
+	for _, home := range r.homes {
+		s = replaceFold(s, home, redactedHome)
+	}
+	s = userPathPattern.ReplaceAllString(s, "${1}"+redactedUser)
+	s = jwtPattern.ReplaceAllString(s, redactedToken)
+	s = secretPattern.ReplaceAllStringFunc(s, redactSecret)
+	s = bearerPattern.ReplaceAllString(s, "${1}"+redactedToken)
+	s = uuidPattern.ReplaceAllString(s, redactedUUID)
+	return s
+}
+
+// redactSecret replaces the value of a secret key-value match, keeping the
+// quotes of quoted values.
+func redactSecret(match string) string {
+	m := secretPattern.FindStringSubmatch(match)
+	if strings.HasPrefix(m[2], `"`) {
+		return m[1] + `"` + redactedToken + `"`
+	}
+	return m[1] + redactedToken
+}
+
+// replaceFold replaces every case-insensitive occurrence of old in s.
+func replaceFold(s, old, replacement string) string {
+	if old == "" {
+		return s
+	}
+	lower, lowerOld := strings.ToLower(s), strings.ToLower(old)
+	if len(lower) != len(s) || len(lowerOld) != len(old) {
+		return strings.ReplaceAll(s, old, replacement)
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, lowerOld)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		b.WriteString(replacement)
+		s, lower = s[i+len(old):], lower[i+len(old):]
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"hytale-launcher/internal/ioutil"
@@ -23,6 +24,15 @@ import (
 // speed is the current download speed in bytes per second.
 type ProgressReporter func(bytesDownloaded int64, speed int64)
 
+// rateLimit is the maximum download speed in bytes per second. Zero means unlimited.
+var rateLimit atomic.Int64
+
+// SetRateLimit caps the speed of all downloads in bytes per second.
+// A value of zero or less removes the limit.
+func SetRateLimit(bytesPerSecond int64) {
+	rateLimit.Store(max(bytesPerSecond, 0))
+}
+
 // base extracts the filename from a URL, stripping any query parameters.
 func base(url string) string {
 	// Cut at the first '?' to remove query parameters
@@ -138,6 +148,7 @@ func downloadFile(
 		lastSampleTime  = time.Now()
 		sampleBytes     int64
 		currentSpeed    int64
+		startTime       = time.Now()
 	)
 
 	for {
@@ -161,6 +172,18 @@ func downloadFile(
 			bytesDownloaded += int64(n)
 			sampleBytes += int64(n)
 
+			// Sleep until the transfer is back under the rate limit
+			if limit := rateLimit.Load(); limit > 0 {
+				expected := time.Duration(float64(bytesDownloaded) / float64(limit) * float64(time.Second))
+				if wait := expected - time.Since(startTime); wait > 0 {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(wait):
+					}
+				}
+			}
+
 			// Update speed calculation periodically
 			elapsed := time.Since(lastSampleTime)
 			if elapsed >= speedSamplePeriod {
@@ -0,0 +1,62 @@
+// Package events names the launcher events that notifications and chat
+// integrations subscribe to, and matches them against the event patterns
+// both are configured with.
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"hytale-launcher/internal/serverout"
+)
+
+// Update events.
+const (
+	UpdateComplete = "update:complete"
+	UpdateFailed   = "update:error"
+)
+
+// Server events. Those parsed from server output share the serverout names.
+const (
+	ServerStarting    = "server:starting"
+	ServerReady       = serverout.EventReady
+	ServerBootTimeout = "server:boot_timeout"
+	ServerStopped     = "server:stopped"
+	ServerCrashed     = "server:crashed"
+	PlayerJoined      = serverout.EventPlayerJoined
+	PlayerLeft        = serverout.EventPlayerLeft
+	Chat              = serverout.EventChat
+	ServerWarning     = serverout.EventWarning
+	ServerError       = serverout.EventError
+	ServerLag         = serverout.EventLag
+
+	// ServerLog is published for every line of server output that no more
+	// specific event was parsed from. Being that frequent, it is only
+	// matched by its own name, never by a pattern such as "server:*".
+	ServerLog = "server:log"
+)
+
+// explicitOnly are the events that prefix patterns do not match.
+var explicitOnly = map[string]bool{
+	ServerLog: true,
+}
+
+// ValidatePattern checks an event pattern: an event name, a prefix ending in
+// "*" such as "server:*", or "*" alone.
+func ValidatePattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("empty event pattern")
+	}
+	if i := strings.IndexByte(pattern, '*'); i >= 0 && i != len(pattern)-1 {
+		return fmt.Errorf("event pattern %q may only end in *", pattern)
+	}
+	return nil
+}
+
+// Match reports whether event matches pattern.
+func Match(pattern, event string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(event, prefix) && !explicitOnly[event]
+	}
+	return pattern == event
+}
@@ -0,0 +1,37 @@
+package events
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, event string
+		want           bool
+	}{
+		{ServerCrashed, ServerCrashed, true},
+		{ServerCrashed, ServerReady, false},
+		{"server:*", PlayerJoined, true},
+		{"server:*", UpdateComplete, false},
+		{"*", UpdateFailed, true},
+		{"server:*", ServerLog, false},
+		{"*", ServerLog, false},
+		{ServerLog, ServerLog, true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.event); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	for _, pattern := range []string{"*", "server:*", ServerReady} {
+		if err := ValidatePattern(pattern); err != nil {
+			t.Errorf("ValidatePattern(%q) = %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"", "*:ready", "server:*:x"} {
+		if err := ValidatePattern(pattern); err == nil {
+			t.Errorf("ValidatePattern(%q) accepted", pattern)
+		}
+	}
+}
@@ -2,6 +2,8 @@
 package hytale
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -9,6 +11,8 @@ import (
 	"sync"
 
 	"github.com/getsentry/sentry-go"
+
+	"hytale-launcher/internal/ioutil"
 )
 
 // getDefaultAppDataDir returns the default application data directory.
@@ -42,15 +46,150 @@ func getUserAppDataDir() (string, error) {
 	return filepath.Join(dir, "hytale"), nil
 }
 
+// locationFileName is the bootstrap pointer file recording a relocated storage
+// directory. It lives in the default app data directory, next to the default
+// storage directory, so it can be found before the storage directory is known.
+const locationFileName = "hytale-location.json"
+
+// Location is the content of the bootstrap pointer file.
+type Location struct {
+	// StorageDir is the absolute path of the relocated storage directory.
+	StorageDir string `json:"storage_dir"`
+}
+
+// LocationFile returns the path of the bootstrap pointer file.
+func LocationFile() (string, error) {
+	dir, err := getDefaultAppDataDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine default app data directory: %w", err)
+	}
+	return filepath.Join(dir, locationFileName), nil
+}
+
+// DefaultStorageDir returns the storage directory used when it has not been relocated.
+func DefaultStorageDir() (string, error) {
+	return getUserAppDataDir()
+}
+
+// ReadLocation reads the bootstrap pointer file.
+// It returns nil if the storage directory has not been relocated.
+func ReadLocation() (*Location, error) {
+	path, err := LocationFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var loc Location
+	err = ioutil.LoadWithBackup(path, func(data []byte) error {
+		var loaded Location
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("invalid storage location file: %w", err)
+		}
+		if !filepath.IsAbs(loaded.StorageDir) {
+			return fmt.Errorf("invalid storage location %q", loaded.StorageDir)
+		}
+		loc = loaded
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &loc, nil
+}
+
+// WriteLocation records a relocated storage directory in the bootstrap pointer file.
+// Passing the default storage directory removes the pointer file instead.
+func WriteLocation(loc Location) error {
+	path, err := LocationFile()
+	if err != nil {
+		return err
+	}
+
+	if def, err := DefaultStorageDir(); err == nil && filepath.Clean(loc.StorageDir) == filepath.Clean(def) {
+		return ioutil.RemoveWithBackup(path)
+	}
+
+	data, err := json.MarshalIndent(loc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFileAtomic(path, data, 0644)
+}
+
+// portableMarkerName is the marker file that enables portable mode when it
+// sits next to the launcher executable.
+const portableMarkerName = "hytale-launcher.portable"
+
+// portableDataDirName is the storage directory used in portable mode,
+// created next to the launcher executable.
+const portableDataDirName = "HytaleData"
+
+// ExecutableDir returns the directory containing the launcher executable.
+func ExecutableDir() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+	return filepath.Dir(exePath), nil
+}
+
+var portable = sync.OnceValue(func() bool {
+	dir, err := ExecutableDir()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, portableMarkerName))
+	return err == nil
+})
+
+// Portable returns true if the launcher runs in portable mode, keeping all
+// data in a directory next to the executable instead of the user app data
+// directory.
+func Portable() bool {
+	return portable()
+}
+
+// resolveStorageDir returns the portable data directory in portable mode,
+// the relocated storage directory if one is recorded and still exists, and
+// otherwise the default storage directory.
+func resolveStorageDir() (string, error) {
+	if Portable() {
+		dir, err := ExecutableDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, portableDataDirName), nil
+	}
+
+	loc, err := ReadLocation()
+	if err != nil {
+		slog.Warn("ignoring storage location file", "error", err)
+	}
+	if loc != nil {
+		if info, err := os.Stat(loc.StorageDir); err == nil && info.IsDir() {
+			return loc.StorageDir, nil
+		}
+		slog.Warn("relocated storage directory is missing, using default", "path", loc.StorageDir)
+	}
+
+	return getUserAppDataDir()
+}
+
 var storageDir = sync.OnceValue(func() string {
-	path, err := getUserAppDataDir()
+	path, err := resolveStorageDir()
 	if err != nil {
 		wrappedErr := fmt.Errorf("unable to determine hytale storage directory: %v", err)
 		sentry.CaptureException(wrappedErr)
 		panic(wrappedErr)
 	}
 
-	slog.Info("selected hytale storage directory", "path", path)
+	slog.Info("selected hytale storage directory", "path", path, "portable", Portable())
 	return path
 })
 
@@ -2,6 +2,7 @@ package hytale
 
 import (
 	"path/filepath"
+	"sort"
 )
 
 // Known channels for game releases.
@@ -33,6 +34,16 @@ func IsKnownChannel(channel string) bool {
 	return knownChannels[channel]
 }
 
+// KnownChannels returns the recognized release channels sorted by name.
+func KnownChannels() []string {
+	result := make([]string, 0, len(knownChannels))
+	for channel := range knownChannels {
+		result = append(result, channel)
+	}
+	sort.Strings(result)
+	return result
+}
+
 // KnownGamePackages returns a slice of all known game package identifiers.
 func KnownGamePackages() []string {
 	result := make([]string, len(knownGamePackages))
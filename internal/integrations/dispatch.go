@@ -0,0 +1,352 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"hytale-launcher/internal/build"
+	"hytale-launcher/internal/events"
+)
+
+// Delivery timing.
+const (
+	// requestTimeout bounds a single delivery.
+	requestTimeout = 10 * time.Second
+
+	// minBackoff and maxBackoff bound the wait after a failed delivery,
+	// which doubles with every attempt.
+	minBackoff = 5 * time.Second
+	maxBackoff = 10 * time.Minute
+
+	// idleWait is how long the dispatcher sleeps with an empty outbox.
+	idleWait = time.Hour
+)
+
+// eventBuffer is how many published events may wait to be queued. Events
+// published while it is full are dropped.
+const eventBuffer = 256
+
+// StatusError is returned when a webhook answers with a non-2xx status.
+type StatusError struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+
+	// RetryAfter is the wait the server asked for, if any.
+	RetryAfter time.Duration
+}
+
+// Error returns the error message for StatusError.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook returned %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Permanent reports whether retrying cannot help, as for most 4xx statuses.
+func (e *StatusError) Permanent() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return e.StatusCode >= 400 && e.StatusCode < 500
+}
+
+// Dispatcher renders published events into the outbox and delivers queued
+// messages in the background.
+type Dispatcher struct {
+	store  *Store
+	outbox *Outbox
+	client *http.Client
+
+	// events holds published events until they are rendered and queued.
+	events chan Event
+
+	// wake interrupts the delivery loop's wait.
+	wake chan struct{}
+
+	// nextSend holds the earliest time each integration may send again.
+	// Only the delivery loop uses it.
+	nextSend map[string]time.Time
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewDispatcher creates a Dispatcher for the integrations in store, queueing
+// through outbox. A nil client uses one with a short timeout.
+func NewDispatcher(store *Store, outbox *Outbox, client *http.Client) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	return &Dispatcher{
+		store:    store,
+		outbox:   outbox,
+		client:   client,
+		events:   make(chan Event, eventBuffer),
+		wake:     make(chan struct{}, 1),
+		nextSend: make(map[string]time.Time),
+	}
+}
+
+// Start begins queueing published events and delivering queued messages,
+// including any left from a previous run.
+func (d *Dispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	go d.queueLoop(ctx)
+	go d.loop(ctx)
+}
+
+// Stop halts delivery. Events still waiting are queued and the outbox is
+// saved, so that they are delivered after a restart.
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+
+	d.drain()
+	if err := d.outbox.Flush(); err != nil {
+		slog.Warn("failed to save integration outbox", "error", err)
+	}
+}
+
+// Publish hands an event to the dispatcher without blocking. It is rendered
+// and queued for every enabled integration that subscribes to it in the
+// background, so callers such as the server output reader are never held up
+// by the outbox.
+func (d *Dispatcher) Publish(e Event) {
+	select {
+	case d.events <- e:
+	default:
+		slog.Warn("integration event buffer is full, dropping event", "event", e.Type)
+	}
+}
+
+// queueLoop queues published events until ctx is cancelled.
+func (d *Dispatcher) queueLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-d.events:
+			d.enqueue(e)
+		}
+	}
+}
+
+// drain queues the events still waiting in the buffer.
+func (d *Dispatcher) drain() {
+	for {
+		select {
+		case e := <-d.events:
+			d.enqueue(e)
+		default:
+			return
+		}
+	}
+}
+
+// enqueue queues a message for every enabled integration that subscribes to
+// the event.
+func (d *Dispatcher) enqueue(e Event) {
+	queued := false
+	for _, i := range d.store.List() {
+		if !i.Enabled || !i.Wants(e.Type) {
+			continue
+		}
+
+		body, err := i.Body(e)
+		if err != nil {
+			slog.Warn("failed to render integration message", "integration", i.Name, "event", e.Type, "error", err)
+			continue
+		}
+		d.outbox.Enqueue(i.ID, e.Type, body)
+		queued = true
+	}
+
+	if queued {
+		d.notify()
+	}
+}
+
+// notify wakes the delivery loop without blocking.
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Test posts a sample message to an integration right away, bypassing the
+// outbox, and returns the delivery error.
+func (d *Dispatcher) Test(ctx context.Context, id string) error {
+	i, err := d.store.Get(id)
+	if err != nil {
+		return err
+	}
+
+	body, err := i.Body(NewEvent(events.ServerReady, nil))
+	if err != nil {
+		return err
+	}
+	return d.post(ctx, i.URL, body)
+}
+
+// loop delivers due messages until ctx is cancelled, sleeping until the next
+// message is due or a new one is published.
+func (d *Dispatcher) loop(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.wake:
+		case <-timer.C:
+		}
+
+		d.deliverDue(ctx)
+
+		wait := idleWait
+		if next, ok := d.outbox.Next(); ok {
+			wait = max(time.Until(next), 0)
+		}
+		timer.Reset(wait)
+	}
+}
+
+// deliverDue attempts every message that is due.
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	for _, msg := range d.outbox.Due(time.Now()) {
+		if ctx.Err() != nil {
+			return
+		}
+		d.deliver(ctx, msg)
+	}
+}
+
+// deliver attempts a single message and updates the outbox with the result.
+func (d *Dispatcher) deliver(ctx context.Context, msg Message) {
+	i, err := d.store.Get(msg.IntegrationID)
+	if err != nil || !i.Enabled {
+		slog.Info("dropping message for removed or disabled integration", "integration", msg.IntegrationID, "event", msg.Event)
+		d.remove(msg)
+		return
+	}
+
+	now := time.Now()
+	if next := d.nextSend[i.ID]; now.Before(next) {
+		d.outbox.Postpone(msg.ID, next)
+		return
+	}
+	d.nextSend[i.ID] = now.Add(i.interval())
+
+	err = d.post(ctx, i.URL, msg.Body)
+	if err == nil {
+		slog.Debug("delivered integration message", "integration", i.Name, "event", msg.Event)
+		d.remove(msg)
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	var status *StatusError
+	if errors.As(err, &status) && status.Permanent() {
+		slog.Warn("integration rejected message, dropping it", "integration", i.Name, "event", msg.Event, "error", err)
+		d.remove(msg)
+		return
+	}
+
+	wait := backoff(msg.Attempts)
+	if status != nil && status.RetryAfter > wait {
+		wait = status.RetryAfter
+	}
+	kept, saveErr := d.outbox.Retry(msg.ID, now.Add(wait), err)
+	if saveErr != nil {
+		slog.Warn("failed to save integration outbox", "error", saveErr)
+	}
+	if kept {
+		slog.Warn("integration delivery failed, will retry", "integration", i.Name, "event", msg.Event, "attempt", msg.Attempts+1, "retryIn", wait, "error", err)
+	} else {
+		slog.Error("integration delivery failed, giving up", "integration", i.Name, "event", msg.Event, "attempts", msg.Attempts+1, "error", err)
+	}
+}
+
+// remove deletes a message from the outbox, logging failures.
+func (d *Dispatcher) remove(msg Message) {
+	if err := d.outbox.Remove(msg.ID); err != nil {
+		slog.Warn("failed to save integration outbox", "error", err)
+	}
+}
+
+// backoff returns the wait after a message has failed attempts times.
+func backoff(attempts int) time.Duration {
+	wait := minBackoff
+	for range attempts {
+		wait *= 2
+		if wait >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return wait
+}
+
+// post sends body to url as JSON.
+func (d *Dispatcher) post(ctx context.Context, url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", build.UserAgent())
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post message: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return nil
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a date.
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
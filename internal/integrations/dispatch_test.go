@@ -0,0 +1,230 @@
+package integrations
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hytale-launcher/internal/events"
+)
+
+// testServer is a webhook that answers with the statuses in order, then 204.
+type testServer struct {
+	*httptest.Server
+	statuses []int
+	requests atomic.Int32
+	received chan struct{}
+}
+
+// newTestServer starts a testServer closed at the end of the test.
+func newTestServer(t *testing.T, statuses ...int) *testServer {
+	t.Helper()
+	s := &testServer{statuses: statuses, received: make(chan struct{}, 16)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(s.requests.Add(1))
+		status := http.StatusNoContent
+		if n <= len(s.statuses) {
+			status = s.statuses[n-1]
+		}
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "120")
+		}
+		w.WriteHeader(status)
+		s.received <- struct{}{}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// newTestDispatcher creates a dispatcher with one webhook integration for
+// url, storing its files in dir.
+func newTestDispatcher(t *testing.T, dir, url string, rate int) (*Dispatcher, Integration) {
+	t.Helper()
+	store := NewStore(filepath.Join(dir, "integrations.json"))
+	i, err := store.Add(Integration{URL: url, Events: []string{"server:*"}, RatePerMinute: rate, Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox := NewOutbox(filepath.Join(dir, "outbox.json"))
+	return NewDispatcher(store, outbox, nil), i
+}
+
+// pending returns the only queued message.
+func pending(t *testing.T, d *Dispatcher) Message {
+	t.Helper()
+	messages := d.outbox.List()
+	if len(messages) != 1 {
+		t.Fatalf("outbox holds %d messages, want 1", len(messages))
+	}
+	return messages[0]
+}
+
+// assertDue fails unless msg is due about wait from now.
+func assertDue(t *testing.T, msg Message, wait time.Duration) {
+	t.Helper()
+	if d := time.Until(msg.NextAttempt); d < wait-5*time.Second || d > wait {
+		t.Fatalf("next attempt in %v, want about %v", d, wait)
+	}
+}
+
+// makeDue makes every queued message due and lifts the rate limit.
+func makeDue(d *Dispatcher) {
+	for _, msg := range d.outbox.List() {
+		d.outbox.Postpone(msg.ID, time.Now())
+	}
+	clear(d.nextSend)
+}
+
+func TestDeliverRetriesWithBackoff(t *testing.T) {
+	server := newTestServer(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+	d, _ := newTestDispatcher(t, t.TempDir(), server.URL, 0)
+	ctx := context.Background()
+
+	d.enqueue(NewEvent(events.ServerReady, nil))
+
+	d.deliverDue(ctx)
+	msg := pending(t, d)
+	if msg.Attempts != 1 || msg.LastError == "" {
+		t.Fatalf("after one failure: %+v", msg)
+	}
+	assertDue(t, msg, minBackoff)
+
+	makeDue(d)
+	d.deliverDue(ctx)
+	msg = pending(t, d)
+	if msg.Attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", msg.Attempts)
+	}
+	assertDue(t, msg, 2*minBackoff)
+
+	makeDue(d)
+	d.deliverDue(ctx)
+	if n := len(d.outbox.List()); n != 0 {
+		t.Fatalf("outbox holds %d messages after delivery", n)
+	}
+	if n := server.requests.Load(); n != 3 {
+		t.Fatalf("server got %d requests, want 3", n)
+	}
+}
+
+func TestDeliverHonoursRetryAfter(t *testing.T) {
+	server := newTestServer(t, http.StatusTooManyRequests)
+	d, _ := newTestDispatcher(t, t.TempDir(), server.URL, 0)
+
+	d.enqueue(NewEvent(events.ServerReady, nil))
+	d.deliverDue(context.Background())
+
+	assertDue(t, pending(t, d), 120*time.Second)
+}
+
+func TestDeliverDropsRejected(t *testing.T) {
+	server := newTestServer(t, http.StatusNotFound)
+	d, _ := newTestDispatcher(t, t.TempDir(), server.URL, 0)
+
+	d.enqueue(NewEvent(events.ServerReady, nil))
+	d.deliverDue(context.Background())
+
+	if n := len(d.outbox.List()); n != 0 {
+		t.Fatalf("outbox holds %d messages after a 404", n)
+	}
+}
+
+func TestDeliverRateLimit(t *testing.T) {
+	server := newTestServer(t)
+	d, _ := newTestDispatcher(t, t.TempDir(), server.URL, 1)
+
+	d.enqueue(NewEvent(events.PlayerJoined, map[string]string{"player": "a"}))
+	d.enqueue(NewEvent(events.PlayerJoined, map[string]string{"player": "b"}))
+	d.deliverDue(context.Background())
+
+	if n := server.requests.Load(); n != 1 {
+		t.Fatalf("server got %d requests, want 1", n)
+	}
+	msg := pending(t, d)
+	if msg.Attempts != 0 {
+		t.Fatalf("rate-limited message counted as failed: %+v", msg)
+	}
+	assertDue(t, msg, time.Minute)
+}
+
+func TestOutboxSurvivesRestart(t *testing.T) {
+	server := newTestServer(t, http.StatusServiceUnavailable)
+	dir := t.TempDir()
+
+	d, _ := newTestDispatcher(t, dir, server.URL, 600)
+	d.enqueue(NewEvent(events.ServerReady, nil))
+	d.deliverDue(context.Background())
+	<-server.received
+	d.Publish(NewEvent(events.ServerCrashed, nil))
+	d.Stop()
+
+	// The restarted dispatcher delivers both messages once they are due.
+	store := NewStore(filepath.Join(dir, "integrations.json"))
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	outbox := NewOutbox(filepath.Join(dir, "outbox.json"))
+	if err := outbox.Load(); err != nil {
+		t.Fatal(err)
+	}
+	messages := outbox.List()
+	if len(messages) != 2 || messages[0].Attempts != 1 || messages[1].Event != events.ServerCrashed {
+		t.Fatalf("restored outbox = %+v", messages)
+	}
+	for _, msg := range messages {
+		outbox.Postpone(msg.ID, time.Now())
+	}
+
+	restarted := NewDispatcher(store, outbox, nil)
+	restarted.Start()
+	defer restarted.Stop()
+
+	for range 2 {
+		select {
+		case <-server.received:
+		case <-time.After(5 * time.Second):
+			t.Fatal("restored messages were not delivered")
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(outbox.List()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("outbox still holds %+v", outbox.List())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPublishQueuesInBackground(t *testing.T) {
+	server := newTestServer(t)
+	d, _ := newTestDispatcher(t, t.TempDir(), server.URL, 0)
+	d.Start()
+	defer d.Stop()
+
+	d.Publish(NewEvent(events.ServerReady, nil))
+
+	select {
+	case <-server.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("published event was not delivered")
+	}
+}
+
+func TestWildcardSkipsServerLog(t *testing.T) {
+	i := Integration{Events: []string{"server:*"}}
+	if !i.Wants(events.Chat) {
+		t.Fatal("server:* does not match chat")
+	}
+	if i.Wants(events.ServerLog) {
+		t.Fatal("server:* matches server:log")
+	}
+
+	i.Events = append(i.Events, events.ServerLog)
+	if !i.Wants(events.ServerLog) {
+		t.Fatal("explicit server:log subscription ignored")
+	}
+}
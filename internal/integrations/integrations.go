@@ -0,0 +1,230 @@
+// Package integrations delivers messages about the launcher-managed server,
+// such as it booting, crashing or players joining, to chat webhooks. Messages
+// are rendered from templates, queued in an on-disk outbox so they survive
+// restarts, and delivered with retries and a per-integration rate limit.
+// Integrations subscribe to the events and patterns of package events.
+package integrations
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"hytale-launcher/internal/events"
+)
+
+// DefaultEvents are subscribed to by integrations that do not list any.
+var DefaultEvents = []string{
+	events.ServerReady,
+	events.ServerCrashed,
+	events.PlayerJoined,
+	events.PlayerLeft,
+}
+
+// defaultTemplates are the message templates used when an integration does
+// not override them. Templates see the event fields, plus "event" and
+// "time".
+var defaultTemplates = map[string]string{
+	events.ServerStarting:    "Server is starting",
+	events.ServerReady:       "Server is up",
+	events.ServerBootTimeout: "Server has not finished booting after 60 seconds",
+	events.ServerStopped:     "Server stopped",
+	events.ServerCrashed:     "Server crashed{{if .exit_code}} with exit code {{.exit_code}}{{end}}{{if .error}}: {{.error}}{{end}}",
+	events.PlayerJoined:      "{{.player}} joined the server",
+	events.PlayerLeft:        "{{.player}} left the server",
+	events.Chat:              "<{{.player}}> {{.message}}",
+	events.ServerWarning:     "Warning: {{.message}}",
+	events.ServerError:       "Error: {{.message}}",
+	events.ServerLag:         "Server is lagging{{if .tps}} ({{.tps}} TPS){{end}}{{if .behind_ms}} ({{.behind_ms}} ms behind){{end}}",
+	events.ServerLog:         "{{.line}}",
+}
+
+// Kinds select the request body an integration posts.
+const (
+	// KindWebhook posts the event, its fields and the rendered text.
+	KindWebhook = "webhook"
+
+	// KindDiscord posts a Discord webhook message.
+	KindDiscord = "discord"
+
+	// KindSlack posts a Slack incoming-webhook message.
+	KindSlack = "slack"
+)
+
+// DefaultRatePerMinute is the delivery rate used when an integration does not
+// set one. It stays below the limits of common chat webhooks.
+const DefaultRatePerMinute = 20
+
+// ErrNotFound is returned when an integration does not exist.
+var ErrNotFound = errors.New("integration not found")
+
+// Event is something that happened to the launcher-managed server.
+type Event struct {
+	// Type is one of the Event constants.
+	Type string `json:"type"`
+
+	// Time is when the event happened.
+	Time time.Time `json:"time"`
+
+	// Fields are event details, such as "player" for joins.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// NewEvent creates an event of the given type happening now.
+func NewEvent(typ string, fields map[string]string) Event {
+	return Event{Type: typ, Time: time.Now(), Fields: fields}
+}
+
+// Integration is a configured chat or webhook destination.
+type Integration struct {
+	// ID uniquely identifies the integration.
+	ID string `json:"id"`
+
+	// Name is the display name chosen by the user.
+	Name string `json:"name"`
+
+	// Kind is KindWebhook, KindDiscord or KindSlack.
+	Kind string `json:"kind"`
+
+	// URL is where messages are posted.
+	URL string `json:"url"`
+
+	// Events are the events delivered, as event names or prefixes ending in
+	// "*". Empty means DefaultEvents.
+	Events []string `json:"events,omitempty"`
+
+	// Templates override the message template per event.
+	Templates map[string]string `json:"templates,omitempty"`
+
+	// RatePerMinute caps how many messages are delivered per minute. Zero
+	// means DefaultRatePerMinute.
+	RatePerMinute int `json:"rate_per_minute,omitempty"`
+
+	// Enabled turns delivery on. Messages for disabled integrations are
+	// dropped.
+	Enabled bool `json:"enabled"`
+}
+
+// validate normalizes and checks the integration.
+func (i *Integration) validate() error {
+	i.Name = strings.TrimSpace(i.Name)
+	i.URL = strings.TrimSpace(i.URL)
+
+	switch i.Kind {
+	case "":
+		i.Kind = KindWebhook
+	case KindWebhook, KindDiscord, KindSlack:
+	default:
+		return fmt.Errorf("unknown integration kind %q", i.Kind)
+	}
+
+	u, err := url.Parse(i.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("integration URL must be an http or https URL")
+	}
+	if i.Name == "" {
+		i.Name = u.Host
+	}
+
+	for _, pattern := range i.Events {
+		if err := events.ValidatePattern(pattern); err != nil {
+			return err
+		}
+	}
+	for event, text := range i.Templates {
+		if _, err := parseTemplate(text); err != nil {
+			return fmt.Errorf("invalid template for %s: %w", event, err)
+		}
+	}
+
+	if i.RatePerMinute < 0 {
+		return errors.New("rate per minute cannot be negative")
+	}
+	return nil
+}
+
+// Wants reports whether the integration subscribes to an event type.
+func (i *Integration) Wants(event string) bool {
+	patterns := i.Events
+	if len(patterns) == 0 {
+		patterns = DefaultEvents
+	}
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		return events.Match(pattern, event)
+	})
+}
+
+// interval returns the minimum time between two deliveries.
+func (i *Integration) interval() time.Duration {
+	rate := i.RatePerMinute
+	if rate == 0 {
+		rate = DefaultRatePerMinute
+	}
+	return time.Minute / time.Duration(rate)
+}
+
+// parseTemplate parses a message template. Missing fields render empty.
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("message").Option("missingkey=zero").Parse(text)
+}
+
+// Render renders the message text of an event for the integration.
+func (i *Integration) Render(e Event) (string, error) {
+	text, ok := i.Templates[e.Type]
+	if !ok {
+		text, ok = defaultTemplates[e.Type]
+	}
+	if !ok {
+		text = "{{.event}}"
+	}
+
+	tmpl, err := parseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+
+	data := map[string]string{
+		"event": e.Type,
+		"time":  e.Time.Format(time.RFC3339),
+	}
+	for k, v := range e.Fields {
+		data[k] = v
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// webhookBody is posted by KindWebhook integrations.
+type webhookBody struct {
+	Event  string            `json:"event"`
+	Time   time.Time         `json:"time"`
+	Text   string            `json:"text"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Body renders the request body posted for an event.
+func (i *Integration) Body(e Event) ([]byte, error) {
+	text, err := i.Render(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render message: %w", err)
+	}
+
+	switch i.Kind {
+	case KindDiscord:
+		return json.Marshal(map[string]string{"content": text})
+	case KindSlack:
+		return json.Marshal(map[string]string{"text": text})
+	default:
+		return json.Marshal(webhookBody{Event: e.Type, Time: e.Time, Text: text, Fields: e.Fields})
+	}
+}
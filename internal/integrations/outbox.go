@@ -0,0 +1,288 @@
+package integrations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hytale-launcher/internal/ioutil"
+)
+
+// Outbox limits. Messages past them are dropped rather than delivered late.
+const (
+	// MaxAttempts is how many failed deliveries a message gets.
+	MaxAttempts = 10
+
+	// MaxAge is how long a message may wait for delivery.
+	MaxAge = 24 * time.Hour
+
+	// MaxPending is how many messages the outbox holds. The oldest are
+	// dropped first.
+	MaxPending = 500
+)
+
+// flushDelay is how long newly queued messages may stay unsaved, so that a
+// burst of events is written in one go.
+const flushDelay = time.Second
+
+// Message is a rendered message waiting for delivery.
+type Message struct {
+	// ID uniquely identifies the message.
+	ID string `json:"id"`
+
+	// IntegrationID is the integration the message is for. Its URL is
+	// looked up at delivery, so edits apply to queued messages.
+	IntegrationID string `json:"integration_id"`
+
+	// Event is the event type the message was rendered from.
+	Event string `json:"event"`
+
+	// Body is the request body.
+	Body json.RawMessage `json:"body"`
+
+	// CreatedAt is when the message was queued.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Attempts is how many deliveries have failed.
+	Attempts int `json:"attempts"`
+
+	// NextAttempt is when the message is due.
+	NextAttempt time.Time `json:"next_attempt"`
+
+	// LastError is why the last delivery failed.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Outbox is the on-disk queue of undelivered messages.
+type Outbox struct {
+	messages []*Message
+	filePath string
+	mu       sync.Mutex
+
+	// flushTimer saves messages queued since the last save; it is nil when
+	// nothing is pending.
+	flushTimer *time.Timer
+}
+
+// NewOutbox creates a new Outbox with the given storage file path.
+func NewOutbox(filePath string) *Outbox {
+	return &Outbox{
+		filePath: filePath,
+	}
+}
+
+// Load loads the queued messages from disk, dropping expired ones.
+func (o *Outbox) Load() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var messages []*Message
+	err := ioutil.LoadWithBackup(o.filePath, func(data []byte) error {
+		var loaded []*Message
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("failed to unmarshal outbox: %w", err)
+		}
+		messages = loaded
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read outbox: %w", err)
+	}
+
+	o.messages = messages
+	if o.pruneLocked(time.Now()) {
+		return o.saveLocked()
+	}
+	return nil
+}
+
+// saveLocked saves the queued messages without acquiring the lock.
+// Caller must hold o.mu.
+func (o *Outbox) saveLocked() error {
+	if o.flushTimer != nil {
+		o.flushTimer.Stop()
+		o.flushTimer = nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(o.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(o.messages, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox: %w", err)
+	}
+
+	if err := ioutil.WriteFileAtomic(o.filePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	return nil
+}
+
+// pruneLocked drops expired messages and the oldest ones past MaxPending,
+// and reports whether any were dropped.
+// Caller must hold o.mu.
+func (o *Outbox) pruneLocked(now time.Time) bool {
+	n := len(o.messages)
+	o.messages = slices.DeleteFunc(o.messages, func(m *Message) bool {
+		if now.Sub(m.CreatedAt) > MaxAge {
+			slog.Warn("dropping expired integration message", "id", m.ID, "event", m.Event, "attempts", m.Attempts)
+			return true
+		}
+		return false
+	})
+	if excess := len(o.messages) - MaxPending; excess > 0 {
+		slog.Warn("integration outbox is full, dropping oldest messages", "dropped", excess)
+		o.messages = slices.Delete(o.messages, 0, excess)
+	}
+	return len(o.messages) != n
+}
+
+// findLocked returns the index of the message with the given ID, or -1.
+// Caller must hold o.mu.
+func (o *Outbox) findLocked(id string) int {
+	return slices.IndexFunc(o.messages, func(m *Message) bool {
+		return m.ID == id
+	})
+}
+
+// Flush saves messages queued since the last save.
+func (o *Outbox) Flush() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.flushTimer == nil {
+		return nil
+	}
+	return o.saveLocked()
+}
+
+// flushPending saves pending messages when the flush timer fires.
+func (o *Outbox) flushPending() {
+	if err := o.Flush(); err != nil {
+		slog.Warn("failed to save integration outbox", "error", err)
+	}
+}
+
+// Enqueue queues a message for an integration, due immediately. It is saved
+// within flushDelay, together with any other messages queued meanwhile.
+func (o *Outbox) Enqueue(integrationID, event string, body []byte) {
+	now := time.Now()
+	msg := &Message{
+		ID:            uuid.NewString(),
+		IntegrationID: integrationID,
+		Event:         event,
+		Body:          body,
+		CreatedAt:     now,
+		NextAttempt:   now,
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.messages = append(o.messages, msg)
+	o.pruneLocked(now)
+	if o.flushTimer == nil {
+		o.flushTimer = time.AfterFunc(flushDelay, o.flushPending)
+	}
+}
+
+// List returns copies of the queued messages, oldest first.
+func (o *Outbox) List() []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	result := make([]Message, 0, len(o.messages))
+	for _, m := range o.messages {
+		result = append(result, *m)
+	}
+	return result
+}
+
+// Due returns copies of the messages due at now, oldest first.
+func (o *Outbox) Due(now time.Time) []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var due []Message
+	for _, m := range o.messages {
+		if !m.NextAttempt.After(now) {
+			due = append(due, *m)
+		}
+	}
+	return due
+}
+
+// Next returns when the earliest queued message is due, or false if the
+// outbox is empty.
+func (o *Outbox) Next() (time.Time, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var next time.Time
+	for _, m := range o.messages {
+		if next.IsZero() || m.NextAttempt.Before(next) {
+			next = m.NextAttempt
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// Remove deletes a message, after delivery or when it cannot be delivered.
+func (o *Outbox) Remove(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	idx := o.findLocked(id)
+	if idx < 0 {
+		return nil
+	}
+	o.messages = slices.Delete(o.messages, idx, idx+1)
+	return o.saveLocked()
+}
+
+// Postpone moves a message's next attempt to at without counting a failed
+// delivery, as when it is held back by the rate limit.
+func (o *Outbox) Postpone(id string, at time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if idx := o.findLocked(id); idx >= 0 {
+		o.messages[idx].NextAttempt = at
+	}
+}
+
+// Retry records a failed delivery and schedules the next attempt at at. The
+// message is dropped once it has failed MaxAttempts times; Retry reports
+// whether it was kept.
+func (o *Outbox) Retry(id string, at time.Time, cause error) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	idx := o.findLocked(id)
+	if idx < 0 {
+		return false, nil
+	}
+
+	m := o.messages[idx]
+	m.Attempts++
+	m.LastError = cause.Error()
+	m.NextAttempt = at
+
+	kept := m.Attempts < MaxAttempts
+	if !kept {
+		o.messages = slices.Delete(o.messages, idx, idx+1)
+	}
+	return kept, o.saveLocked()
+}
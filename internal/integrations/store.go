@@ -0,0 +1,164 @@
+package integrations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"hytale-launcher/internal/ioutil"
+)
+
+// Store manages the configured integrations.
+type Store struct {
+	integrations []*Integration
+	filePath     string
+	mu           sync.RWMutex
+}
+
+// NewStore creates a new Store with the given storage file path.
+func NewStore(filePath string) *Store {
+	return &Store{
+		filePath: filePath,
+	}
+}
+
+// Load loads the integrations from disk.
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var integrations []*Integration
+	err := ioutil.LoadWithBackup(s.filePath, func(data []byte) error {
+		var loaded []*Integration
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("failed to unmarshal integrations: %w", err)
+		}
+		integrations = loaded
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read integrations: %w", err)
+	}
+
+	s.integrations = integrations
+	return nil
+}
+
+// saveLocked saves the integrations without acquiring the lock.
+// Caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(s.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.integrations, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal integrations: %w", err)
+	}
+
+	// Webhook URLs often embed a secret token.
+	if err := ioutil.WriteFileAtomic(s.filePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write integrations: %w", err)
+	}
+	return nil
+}
+
+// findLocked returns the index of the integration with the given ID, or -1.
+// Caller must hold s.mu.
+func (s *Store) findLocked(id string) int {
+	return slices.IndexFunc(s.integrations, func(i *Integration) bool {
+		return i.ID == id
+	})
+}
+
+// List returns copies of all integrations sorted by name.
+func (s *Store) List() []Integration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Integration, 0, len(s.integrations))
+	for _, i := range s.integrations {
+		result = append(result, *i)
+	}
+	slices.SortStableFunc(result, func(a, b Integration) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return result
+}
+
+// Get returns a copy of the integration with the given ID.
+func (s *Store) Get(id string) (Integration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	idx := s.findLocked(id)
+	if idx < 0 {
+		return Integration{}, ErrNotFound
+	}
+	return *s.integrations[idx], nil
+}
+
+// Add validates and stores a new integration, assigning it a fresh ID.
+func (s *Store) Add(i Integration) (Integration, error) {
+	if err := i.validate(); err != nil {
+		return Integration{}, err
+	}
+	i.ID = uuid.NewString()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.integrations = append(s.integrations, &i)
+	if err := s.saveLocked(); err != nil {
+		s.integrations = s.integrations[:len(s.integrations)-1]
+		return Integration{}, err
+	}
+	return i, nil
+}
+
+// Update replaces an existing integration.
+func (s *Store) Update(i Integration) (Integration, error) {
+	if err := i.validate(); err != nil {
+		return Integration{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.findLocked(i.ID)
+	if idx < 0 {
+		return Integration{}, ErrNotFound
+	}
+
+	previous := s.integrations[idx]
+	s.integrations[idx] = &i
+	if err := s.saveLocked(); err != nil {
+		s.integrations[idx] = previous
+		return Integration{}, err
+	}
+	return i, nil
+}
+
+// Remove deletes the integration with the given ID.
+func (s *Store) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.findLocked(id)
+	if idx < 0 {
+		return ErrNotFound
+	}
+
+	s.integrations = slices.Delete(s.integrations, idx, idx+1)
+	return s.saveLocked()
+}
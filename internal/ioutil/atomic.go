@@ -0,0 +1,134 @@
+package ioutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// BackupSuffix is appended to a file's path to name its rolling backup.
+const BackupSuffix = ".bak"
+
+// WriteFileAtomic writes data to path so that readers only ever see the old or
+// the new contents. The data is written to a temporary file in the same
+// directory and synced before it is renamed over path. The previous contents
+// are kept as path+BackupSuffix.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary file for %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to sync %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+
+	// Keep the current file as the backup. Between the two renames only the
+	// backup exists, which LoadWithBackup recovers from.
+	if err := os.Rename(path, path+BackupSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("unable to back up %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("unable to replace %s: %w", path, err)
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes directory entries so that renames survive a crash.
+// Windows does not support syncing directories.
+func syncDir(dir string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	d.Sync()
+}
+
+// recoveryHandler is called when a file is loaded from its backup.
+var (
+	recoveryMu      sync.Mutex
+	recoveryHandler func(path string, cause error)
+)
+
+// OnRecovered registers fn to be called whenever LoadWithBackup falls back to
+// a backup. Only one handler is kept.
+func OnRecovered(fn func(path string, cause error)) {
+	recoveryMu.Lock()
+	defer recoveryMu.Unlock()
+	recoveryHandler = fn
+}
+
+// LoadWithBackup reads path and passes its contents to load. If the file is
+// missing, unreadable or rejected by load, the backup written by
+// WriteFileAtomic is tried instead, and the recovery handler is notified.
+// When neither can be loaded the error for path is returned, so a missing
+// file without a backup still reports fs.ErrNotExist.
+func LoadWithBackup(path string, load func(data []byte) error) error {
+	err := loadFile(path, load)
+	if err == nil {
+		return nil
+	}
+
+	backup := path + BackupSuffix
+	if backupErr := loadFile(backup, load); backupErr != nil {
+		if !errors.Is(backupErr, fs.ErrNotExist) {
+			slog.Warn("backup could not be loaded either", "path", backup, "error", backupErr)
+		}
+		return err
+	}
+
+	slog.Warn("loaded file from backup", "path", path, "error", err)
+
+	recoveryMu.Lock()
+	fn := recoveryHandler
+	recoveryMu.Unlock()
+	if fn != nil {
+		fn(path, err)
+	}
+	return nil
+}
+
+// loadFile reads path and passes its contents to load.
+func loadFile(path string, load func(data []byte) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return load(data)
+}
+
+// RemoveWithBackup removes path and its backup. Missing files are ignored.
+func RemoveWithBackup(path string) error {
+	for _, p := range []string{path, path + BackupSuffix} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
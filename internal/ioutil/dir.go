@@ -89,3 +89,32 @@ func OpenDirectory(path string) error {
 	}
 	return nil
 }
+
+// OpenFile opens a file with its default application.
+func OpenFile(path string) error {
+	slog.Debug("opening file", "path", path)
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	// On Windows, explorer opens files with their associated application
+	cmd := exec.Command("explorer", path)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	return nil
+}
+
+// existingAncestor returns path or the nearest parent directory that exists.
+func existingAncestor(path string) string {
+	path = filepath.Clean(path)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
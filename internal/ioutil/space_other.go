@@ -0,0 +1,17 @@
+//go:build !windows
+
+package ioutil
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// FreeSpace returns the number of bytes available to the current user on the
+// filesystem containing path.
+func FreeSpace(path string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(existingAncestor(path), &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package ioutil
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// FreeSpace returns the number of bytes available to the current user on the
+// volume containing path.
+func FreeSpace(path string) (uint64, error) {
+	dir, err := windows.UTF16PtrFromString(existingAncestor(path))
+	if err != nil {
+		return 0, err
+	}
+
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(dir, &free, nil, nil); err != nil {
+		return 0, err
+	}
+	return free, nil
+}
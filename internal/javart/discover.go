@@ -0,0 +1,160 @@
+package javart
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// candidate is a java binary found during discovery.
+type candidate struct {
+	path   string
+	source string
+}
+
+// systemRoots returns directories that commonly contain one Java home per entry.
+func systemRoots() []string {
+	home, _ := os.UserHomeDir()
+
+	switch runtime.GOOS {
+	case "windows":
+		var roots []string
+		for _, env := range []string{"ProgramFiles", "ProgramW6432", "ProgramFiles(x86)"} {
+			base := os.Getenv(env)
+			if base == "" {
+				continue
+			}
+			for _, vendor := range []string{"Java", "Eclipse Adoptium", "Microsoft", "Zulu", "BellSoft", "Amazon Corretto", "Semeru"} {
+				roots = append(roots, filepath.Join(base, vendor))
+			}
+		}
+		if home != "" {
+			roots = append(roots, filepath.Join(home, ".jdks"))
+		}
+		return roots
+	case "darwin":
+		roots := []string{"/Library/Java/JavaVirtualMachines"}
+		if home != "" {
+			roots = append(roots, filepath.Join(home, "Library", "Java", "JavaVirtualMachines"))
+		}
+		return roots
+	default:
+		roots := []string{"/usr/lib/jvm", "/usr/java", "/opt/java", "/opt"}
+		if home != "" {
+			roots = append(roots,
+				filepath.Join(home, ".jdks"),
+				filepath.Join(home, ".sdkman", "candidates", "java"),
+			)
+		}
+		return roots
+	}
+}
+
+// homesIn returns the Java homes found directly below root.
+func homesIn(root string) []string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	var homes []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, e.Name())
+
+		// macOS bundles keep the home under Contents/Home.
+		if isExecutable(BinaryIn(filepath.Join(dir, "Contents", "Home"))) {
+			homes = append(homes, filepath.Join(dir, "Contents", "Home"))
+			continue
+		}
+		if isExecutable(BinaryIn(dir)) {
+			homes = append(homes, dir)
+		}
+	}
+	return homes
+}
+
+// candidates lists java binaries from JAVA_HOME, PATH and common install
+// locations, without duplicates.
+func candidates(extra []candidate) []candidate {
+	var found []candidate
+	seen := make(map[string]bool)
+
+	add := func(path, source string) {
+		if path == "" {
+			return
+		}
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		path = filepath.Clean(path)
+		if seen[path] || !isExecutable(path) {
+			return
+		}
+		seen[path] = true
+		found = append(found, candidate{path: path, source: source})
+	}
+
+	for _, c := range extra {
+		add(c.path, c.source)
+	}
+
+	if home := os.Getenv("JAVA_HOME"); home != "" {
+		add(BinaryIn(home), SourceJavaHome)
+	}
+
+	if path, err := exec.LookPath(ExeName()); err == nil {
+		add(path, SourcePath)
+	}
+
+	for _, root := range systemRoots() {
+		for _, home := range homesIn(root) {
+			add(BinaryIn(home), SourceSystem)
+		}
+	}
+
+	return found
+}
+
+// Discover finds Java runtimes on the system and probes each of them.
+// extra lists additional binaries (e.g., the bundled and custom runtimes)
+// by path and source; they take precedence over discovered duplicates.
+func Discover(ctx context.Context, extra map[string]string) []Runtime {
+	var extras []candidate
+	for path, source := range extra {
+		extras = append(extras, candidate{path: path, source: source})
+	}
+
+	found := candidates(extras)
+
+	var wg sync.WaitGroup
+	results := make([]*Runtime, len(found))
+
+	for i, c := range found {
+		wg.Add(1)
+		go func(i int, c candidate) {
+			defer wg.Done()
+			rt, err := Probe(ctx, c.path, c.source)
+			if err != nil {
+				slog.Warn("skipping unusable java runtime", "bin", c.path, "error", err)
+				return
+			}
+			results[i] = rt
+		}(i, c)
+	}
+	wg.Wait()
+
+	var runtimes []Runtime
+	for _, rt := range results {
+		if rt != nil {
+			runtimes = append(runtimes, *rt)
+		}
+	}
+	return runtimes
+}
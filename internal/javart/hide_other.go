@@ -0,0 +1,10 @@
+//go:build !windows
+
+package javart
+
+import (
+	"os/exec"
+)
+
+// hideWindow is a no-op on platforms without console windows.
+func hideWindow(cmd *exec.Cmd) {}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package javart
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// hideWindow prevents the java process from opening a console window.
+func hideWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
+	}
+}
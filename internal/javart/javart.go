@@ -0,0 +1,205 @@
+// Package javart discovers and inspects Java runtimes installed on the system
+// so that the game and server can run on a runtime other than the bundled one.
+package javart
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"hytale-launcher/internal/build"
+)
+
+// MinimumMajor is the lowest Java feature release the game supports.
+const MinimumMajor = 25
+
+// probeTimeout bounds how long a runtime may take to print its properties.
+const probeTimeout = 10 * time.Second
+
+// Runtime sources describe where a runtime was found.
+const (
+	SourceBundled  = "bundled"
+	SourceJavaHome = "java_home"
+	SourcePath     = "path"
+	SourceSystem   = "system"
+	SourceCustom   = "custom"
+)
+
+// Runtime describes a Java installation.
+type Runtime struct {
+	// ID is a stable identifier derived from the binary path.
+	ID string `json:"id"`
+
+	// Path is the path to the java executable.
+	Path string `json:"path"`
+
+	// Home is the java.home reported by the runtime.
+	Home string `json:"home"`
+
+	// Version is the full java.version string (e.g., "25.0.1").
+	Version string `json:"version"`
+
+	// Major is the Java feature release number (e.g., 25).
+	Major int `json:"major"`
+
+	// Vendor is the java.vendor reported by the runtime.
+	Vendor string `json:"vendor"`
+
+	// Arch is the os.arch reported by the runtime.
+	Arch string `json:"arch"`
+
+	// Source is where the runtime was found.
+	Source string `json:"source"`
+
+	// Compatible is true if the runtime can run the game.
+	Compatible bool `json:"compatible"`
+
+	// Problem explains why the runtime is not compatible.
+	Problem string `json:"problem,omitempty"`
+}
+
+// ExeName returns the file name of the java executable on this platform.
+func ExeName() string {
+	if runtime.GOOS == "windows" {
+		return "java.exe"
+	}
+	return "java"
+}
+
+// BinaryIn returns the path of the java executable inside a Java home directory.
+func BinaryIn(home string) string {
+	return filepath.Join(home, "bin", ExeName())
+}
+
+// idFor returns the stable runtime ID for a java binary path.
+func idFor(path string) string {
+	sum := sha1.Sum([]byte(filepath.Clean(path)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Probe runs the java binary with -XshowSettings:properties and reads its
+// version, vendor and architecture. The result is checked for compatibility.
+func Probe(ctx context.Context, javaBin, source string) (*Runtime, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	slog.Debug("probing java runtime", "bin", javaBin)
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, javaBin, "-XshowSettings:properties", "-version")
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	hideWindow(cmd)
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("java exited with code %d", exitErr.ExitCode())
+		}
+		return nil, fmt.Errorf("failed to run java: %w", err)
+	}
+
+	props := parseProperties(out.Bytes())
+
+	rt := &Runtime{
+		ID:      idFor(javaBin),
+		Path:    javaBin,
+		Home:    props["java.home"],
+		Version: props["java.version"],
+		Vendor:  props["java.vendor"],
+		Arch:    props["os.arch"],
+		Source:  source,
+	}
+	if rt.Version == "" {
+		return nil, errors.New("java did not report its version")
+	}
+
+	rt.Major = majorVersion(rt.Version)
+	rt.Check()
+	return rt, nil
+}
+
+// Check updates Compatible and Problem against the game's requirements.
+func (r *Runtime) Check() {
+	r.Compatible = false
+
+	switch {
+	case r.Major < MinimumMajor:
+		r.Problem = fmt.Sprintf("Java %d or newer is required, found %s", MinimumMajor, r.Version)
+	case !archMatches(r.Arch, build.Arch()):
+		r.Problem = fmt.Sprintf("runtime architecture %s does not match %s", r.Arch, build.Arch())
+	default:
+		r.Compatible = true
+		r.Problem = ""
+	}
+}
+
+// parseProperties extracts "key = value" lines from -XshowSettings output.
+// Multi-line values keep only their first line.
+func parseProperties(output []byte) map[string]string {
+	props := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, ok := strings.Cut(line, " = ")
+		if !ok || strings.ContainsAny(key, " \t") {
+			continue
+		}
+		if _, exists := props[key]; !exists {
+			props[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return props
+}
+
+// majorVersion returns the feature release of a java.version string.
+// Legacy "1.8.0_392" style versions map to 8.
+func majorVersion(version string) int {
+	version = strings.TrimPrefix(version, "1.")
+	end := strings.IndexFunc(version, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if end >= 0 {
+		version = version[:end]
+	}
+
+	major, err := strconv.Atoi(version)
+	if err != nil {
+		return 0
+	}
+	return major
+}
+
+// archMatches compares a Java os.arch value to a Go architecture name.
+func archMatches(javaArch, goArch string) bool {
+	switch javaArch {
+	case "amd64", "x86_64":
+		return goArch == "amd64"
+	case "aarch64", "arm64":
+		return goArch == "arm64"
+	case "x86", "i386", "i686":
+		return goArch == "386"
+	default:
+		return javaArch == goArch
+	}
+}
+
+// isExecutable reports whether path exists and is a regular file.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
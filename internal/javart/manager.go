@@ -0,0 +1,241 @@
+package javart
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"hytale-launcher/internal/ioutil"
+)
+
+// Selection targets for the built-in launch configurations.
+const (
+	TargetClient = "client"
+	TargetServer = "server"
+)
+
+// config is the persisted runtime configuration.
+type config struct {
+	// Custom lists java binaries added by the user.
+	Custom []string `json:"custom,omitempty"`
+
+	// Selections maps a selection key to the java binary it uses.
+	Selections map[string]string `json:"selections,omitempty"`
+}
+
+// Manager keeps user-added runtimes and the runtime selected for each launch
+// target and launch profile.
+type Manager struct {
+	filePath string
+
+	mu  sync.RWMutex
+	cfg config
+}
+
+// NewManager creates a Manager with the given storage file path.
+func NewManager(filePath string) *Manager {
+	return &Manager{
+		filePath: filePath,
+		cfg:      config{Selections: make(map[string]string)},
+	}
+}
+
+// Load loads the runtime configuration from disk.
+func (m *Manager) Load() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var cfg config
+	err := ioutil.LoadWithBackup(m.filePath, func(data []byte) error {
+		var loaded config
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("failed to unmarshal java runtime config: %w", err)
+		}
+		cfg = loaded
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read java runtime config: %w", err)
+	}
+	if cfg.Selections == nil {
+		cfg.Selections = make(map[string]string)
+	}
+
+	m.cfg = cfg
+	return nil
+}
+
+// saveLocked saves the configuration without acquiring the lock.
+// Caller must hold m.mu.
+func (m *Manager) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(m.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m.cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal java runtime config: %w", err)
+	}
+
+	if err := ioutil.WriteFileAtomic(m.filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write java runtime config: %w", err)
+	}
+	return nil
+}
+
+// Custom returns the java binaries added by the user.
+func (m *Manager) Custom() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.cfg.Custom)
+}
+
+// resolveBinary accepts either a java binary or a Java home directory.
+func resolveBinary(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		path = BinaryIn(path)
+		if !isExecutable(path) {
+			return "", fmt.Errorf("no java executable found in %s", filepath.Dir(path))
+		}
+	}
+	return filepath.Clean(path), nil
+}
+
+// AddCustom probes and remembers a user-supplied runtime.
+// path may be the java binary or its Java home directory.
+func (m *Manager) AddCustom(ctx context.Context, path string) (*Runtime, error) {
+	bin, err := resolveBinary(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := Probe(ctx, bin, SourceCustom)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !slices.Contains(m.cfg.Custom, bin) {
+		m.cfg.Custom = append(m.cfg.Custom, bin)
+		if err := m.saveLocked(); err != nil {
+			return nil, err
+		}
+	}
+	return rt, nil
+}
+
+// RemoveCustom forgets a user-supplied runtime and clears any selection of it.
+func (m *Manager) RemoveCustom(bin string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cfg.Custom = slices.DeleteFunc(m.cfg.Custom, func(p string) bool {
+		return p == bin
+	})
+	for key, selected := range m.cfg.Selections {
+		if selected == bin {
+			delete(m.cfg.Selections, key)
+		}
+	}
+	return m.saveLocked()
+}
+
+// Key returns the selection key of a launch profile of target. An empty
+// profile is the target's default selection, which is also used by profiles
+// without a selection of their own.
+func Key(target, profile string) string {
+	if profile == "" {
+		return target
+	}
+	return target + ":" + profile
+}
+
+// TargetOf returns the launch target of a selection key.
+func TargetOf(key string) string {
+	target, _, _ := strings.Cut(key, ":")
+	return target
+}
+
+// Select assigns a runtime to a launch target or profile after checking it
+// is compatible. An empty bin clears the selection, so that a profile uses
+// its target's runtime and a target the bundled one.
+func (m *Manager) Select(ctx context.Context, key, bin string) error {
+	if target := TargetOf(key); target != TargetClient && target != TargetServer {
+		return fmt.Errorf("unknown target %q", target)
+	}
+
+	if bin != "" {
+		rt, err := Probe(ctx, bin, SourceCustom)
+		if err != nil {
+			return err
+		}
+		if !rt.Compatible {
+			return errors.New(rt.Problem)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if bin == "" {
+		delete(m.cfg.Selections, key)
+	} else {
+		m.cfg.Selections[key] = bin
+	}
+	return m.saveLocked()
+}
+
+// Selected returns the java binary selected for a target or profile, falling
+// back to the target's selection for profiles without one. It returns an
+// empty string if neither has a selection.
+func (m *Manager) Selected(key string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if bin, ok := m.cfg.Selections[key]; ok {
+		return bin
+	}
+	return m.cfg.Selections[TargetOf(key)]
+}
+
+// Selections returns all runtime selections by selection key.
+func (m *Manager) Selections() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[string]string, len(m.cfg.Selections))
+	for key, bin := range m.cfg.Selections {
+		result[key] = bin
+	}
+	return result
+}
+
+// MoveSelection moves a profile's own selection to a new key, as when the
+// profile is renamed. Nothing happens if from has no selection of its own.
+func (m *Manager) MoveSelection(from, to string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bin, ok := m.cfg.Selections[from]
+	if !ok || from == to {
+		return nil
+	}
+	delete(m.cfg.Selections, from)
+	m.cfg.Selections[to] = bin
+	return m.saveLocked()
+}
@@ -0,0 +1,69 @@
+package javart
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelectRejectsUnknownTarget(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "java.json"))
+	ctx := context.Background()
+
+	for _, key := range []string{"launcher", Key("launcher", "alex")} {
+		if err := m.Select(ctx, key, ""); err == nil {
+			t.Fatalf("selection for %q accepted", key)
+		}
+	}
+	for _, target := range []string{TargetClient, TargetServer} {
+		if err := m.Select(ctx, target, ""); err != nil {
+			t.Fatalf("Select(%s): %v", target, err)
+		}
+	}
+}
+
+func TestProfileSelectionFallsBackToTarget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "java.json")
+	config := `{"selections": {"client": "/jdk/client/bin/java", "client:alex": "/jdk/alex/bin/java"}}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(path)
+	if err := m.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{TargetClient, "/jdk/client/bin/java"},
+		{Key(TargetClient, "alex"), "/jdk/alex/bin/java"},
+		{Key(TargetClient, "sam"), "/jdk/client/bin/java"},
+		{TargetServer, ""},
+	}
+	for _, tt := range tests {
+		if got := m.Selected(tt.key); got != tt.want {
+			t.Errorf("Selected(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	if err := m.MoveSelection(Key(TargetClient, "alex"), Key(TargetClient, "sam")); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Selected(Key(TargetClient, "sam")); got != "/jdk/alex/bin/java" {
+		t.Errorf("moved selection = %q", got)
+	}
+	if _, ok := m.Selections()[Key(TargetClient, "alex")]; ok {
+		t.Error("selection left under the old key")
+	}
+
+	// Clearing a profile's selection makes it use the target's again.
+	if err := m.Select(context.Background(), Key(TargetClient, "sam"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Selected(Key(TargetClient, "sam")); got != "/jdk/client/bin/java" {
+		t.Errorf("cleared profile selection = %q, want the client's", got)
+	}
+}
@@ -0,0 +1,175 @@
+// Package jvmopts plans JVM heap sizes, garbage collector and extra flags for
+// the game client and server based on system memory and user overrides.
+package jvmopts
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Launch targets that have their own JVM options.
+const (
+	TargetClient = "client"
+	TargetServer = "server"
+)
+
+// Garbage collectors that can be selected. GCDefault means G1, which is
+// passed explicitly rather than left to the JVM: its own choice depends on
+// the machine and falls back to the serial collector on small ones.
+const (
+	GCDefault    = ""
+	GCG1         = "g1"
+	GCZ          = "zgc"
+	GCShenandoah = "shenandoah"
+	GCParallel   = "parallel"
+)
+
+// gcFlags maps each collector to the flag that enables it.
+var gcFlags = map[string]string{
+	GCG1:         "-XX:+UseG1GC",
+	GCZ:          "-XX:+UseZGC",
+	GCShenandoah: "-XX:+UseShenandoahGC",
+	GCParallel:   "-XX:+UseParallelGC",
+}
+
+// Preset is a curated group of -XX flags.
+type Preset struct {
+	// ID identifies the preset in Options.
+	ID string `json:"id"`
+
+	// Name is a short human-readable name.
+	Name string `json:"name"`
+
+	// Description explains what the preset does.
+	Description string `json:"description"`
+
+	// Flags are the JVM flags the preset adds.
+	Flags []string `json:"flags"`
+
+	// GCs lists the collectors the preset works with. Empty means any.
+	GCs []string `json:"gcs,omitempty"`
+}
+
+// presets is the curated list of flag presets users can choose from.
+var presets = []Preset{
+	{
+		ID:          "g1_low_pause",
+		Name:        "Low pause G1",
+		Description: "Targets shorter garbage collection pauses at a small throughput cost.",
+		Flags:       []string{"-XX:MaxGCPauseMillis=100", "-XX:+ParallelRefProcEnabled"},
+		GCs:         []string{GCG1},
+	},
+	{
+		ID:          "g1_server",
+		Name:        "G1 server tuning",
+		Description: "Larger young generation and earlier marking, suited to busy servers.",
+		Flags: []string{
+			"-XX:+UnlockExperimentalVMOptions",
+			"-XX:G1NewSizePercent=30",
+			"-XX:G1MaxNewSizePercent=40",
+			"-XX:G1HeapRegionSize=8M",
+			"-XX:G1ReservePercent=20",
+			"-XX:InitiatingHeapOccupancyPercent=15",
+		},
+		GCs: []string{GCG1},
+	},
+	{
+		ID:          "pre_touch",
+		Name:        "Pre-touch heap",
+		Description: "Commits the whole heap at startup. Slower start, steadier performance.",
+		Flags:       []string{"-XX:+AlwaysPreTouch"},
+	},
+	{
+		ID:          "disable_explicit_gc",
+		Name:        "Ignore System.gc()",
+		Description: "Prevents plugins from forcing full garbage collections.",
+		Flags:       []string{"-XX:+DisableExplicitGC"},
+	},
+	{
+		ID:          "string_dedup",
+		Name:        "String deduplication",
+		Description: "Shares identical strings to reduce heap usage.",
+		Flags:       []string{"-XX:+UseStringDeduplication"},
+	},
+	{
+		ID:          "compact_headers",
+		Name:        "Compact object headers",
+		Description: "Smaller object headers, lowering heap usage on Java 25 and newer.",
+		Flags:       []string{"-XX:+UseCompactObjectHeaders"},
+	},
+}
+
+// Presets returns the curated flag presets.
+func Presets() []Preset {
+	return slices.Clone(presets)
+}
+
+// findPreset returns the preset with the given ID.
+func findPreset(id string) (Preset, bool) {
+	i := slices.IndexFunc(presets, func(p Preset) bool {
+		return p.ID == id
+	})
+	if i < 0 {
+		return Preset{}, false
+	}
+	return presets[i], true
+}
+
+// Heap size limits accepted from users, in MiB.
+const (
+	minHeapMB = 512
+	maxHeapMB = 256 * 1024
+)
+
+// Options are the user's JVM overrides for a target. Zero values are automatic.
+type Options struct {
+	// MaxHeapMB overrides -Xmx in MiB.
+	MaxHeapMB int `json:"max_heap_mb,omitempty"`
+
+	// InitialHeapMB overrides -Xms in MiB.
+	InitialHeapMB int `json:"initial_heap_mb,omitempty"`
+
+	// GC selects the garbage collector.
+	GC string `json:"gc,omitempty"`
+
+	// Presets lists the IDs of the enabled flag presets.
+	Presets []string `json:"presets,omitempty"`
+}
+
+// Validate checks the options for out-of-range sizes and unknown or
+// incompatible presets.
+func (o Options) Validate() error {
+	if o.MaxHeapMB != 0 && (o.MaxHeapMB < minHeapMB || o.MaxHeapMB > maxHeapMB) {
+		return fmt.Errorf("maximum heap must be between %d and %d MB", minHeapMB, maxHeapMB)
+	}
+	if o.InitialHeapMB != 0 && (o.InitialHeapMB < minHeapMB || o.InitialHeapMB > maxHeapMB) {
+		return fmt.Errorf("initial heap must be between %d and %d MB", minHeapMB, maxHeapMB)
+	}
+	if o.MaxHeapMB != 0 && o.InitialHeapMB > o.MaxHeapMB {
+		return fmt.Errorf("initial heap of %d MB exceeds the maximum of %d MB", o.InitialHeapMB, o.MaxHeapMB)
+	}
+
+	if _, ok := gcFlags[o.GC]; !ok && o.GC != GCDefault {
+		return fmt.Errorf("unknown garbage collector %q", o.GC)
+	}
+
+	for _, id := range o.Presets {
+		preset, ok := findPreset(id)
+		if !ok {
+			return fmt.Errorf("unknown preset %q", id)
+		}
+		if len(preset.GCs) > 0 && !slices.Contains(preset.GCs, o.effectiveGC()) {
+			return fmt.Errorf("preset %q requires one of the %v collectors", id, preset.GCs)
+		}
+	}
+	return nil
+}
+
+// effectiveGC returns the collector the JVM will use. Without an explicit
+// choice this is G1, the default for server-class machines.
+func (o Options) effectiveGC() string {
+	if o.GC == GCDefault {
+		return GCG1
+	}
+	return o.GC
+}
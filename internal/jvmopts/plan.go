@@ -0,0 +1,153 @@
+package jvmopts
+
+import (
+	"fmt"
+)
+
+const mb = 1024 * 1024
+
+// Memory describes system memory in bytes.
+type Memory struct {
+	// Total is the installed physical memory.
+	Total uint64 `json:"total"`
+
+	// Available is the memory that can be allocated without swapping.
+	Available uint64 `json:"available"`
+}
+
+// heapBounds are the automatic heap limits for a target, in MiB.
+type heapBounds struct {
+	// share is the fraction of total memory given to the heap.
+	share float64
+
+	// min and max clamp the automatic heap size.
+	min, max int
+}
+
+// bounds holds the automatic sizing rules per target. The client's JVM only
+// runs the local world, so it gets a smaller share than a dedicated server.
+var bounds = map[string]heapBounds{
+	TargetClient: {share: 0.25, min: 2048, max: 6144},
+	TargetServer: {share: 0.5, min: 2048, max: 16384},
+}
+
+// systemReserveMB is the memory left for the OS and the game client itself.
+const systemReserveMB = 2048
+
+// fallbackHeapMB is used when system memory cannot be read.
+const fallbackHeapMB = 4096
+
+// Plan is the computed JVM configuration for a target.
+type Plan struct {
+	// Target is the launch target the plan is for.
+	Target string `json:"target"`
+
+	// Memory is the system memory the plan was computed from.
+	Memory Memory `json:"memory"`
+
+	// RecommendedHeapMB is the automatic maximum heap size.
+	RecommendedHeapMB int `json:"recommended_heap_mb"`
+
+	// MaxHeapMB is the -Xmx value that will be used.
+	MaxHeapMB int `json:"max_heap_mb"`
+
+	// InitialHeapMB is the -Xms value that will be used.
+	InitialHeapMB int `json:"initial_heap_mb"`
+
+	// GC is the selected garbage collector.
+	GC string `json:"gc"`
+
+	// Presets are the enabled flag presets.
+	Presets []string `json:"presets,omitempty"`
+
+	// Args are the resulting JVM arguments.
+	Args []string `json:"args"`
+
+	// Warnings describe problems with the configuration.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Recommend returns the automatic maximum heap size in MiB for a target.
+// A zero mem falls back to a fixed size.
+func Recommend(target string, mem Memory) int {
+	b, ok := bounds[target]
+	if !ok {
+		b = bounds[TargetClient]
+	}
+	if mem.Total == 0 {
+		return fallbackHeapMB
+	}
+
+	totalMB := int(mem.Total / mb)
+	heap := int(float64(totalMB) * b.share)
+	heap = min(heap, totalMB-systemReserveMB)
+	heap = max(min(heap, b.max), b.min)
+
+	// Round down to a 512 MiB step for readable values.
+	return max(heap/512*512, minHeapMB)
+}
+
+// NewPlan computes the JVM configuration for a target from system memory and
+// the user's options. Options are assumed to be valid.
+func NewPlan(target string, mem Memory, opts Options) *Plan {
+	p := &Plan{
+		Target:            target,
+		Memory:            mem,
+		RecommendedHeapMB: Recommend(target, mem),
+		GC:                opts.effectiveGC(),
+		Presets:           opts.Presets,
+	}
+
+	p.MaxHeapMB = p.RecommendedHeapMB
+	if opts.MaxHeapMB != 0 {
+		p.MaxHeapMB = opts.MaxHeapMB
+	}
+
+	switch {
+	case opts.InitialHeapMB != 0:
+		p.InitialHeapMB = min(opts.InitialHeapMB, p.MaxHeapMB)
+	case target == TargetServer:
+		// A server grows to its maximum anyway; reserving it up front
+		// avoids resizing pauses.
+		p.InitialHeapMB = p.MaxHeapMB
+	default:
+		p.InitialHeapMB = min(1024, p.MaxHeapMB)
+	}
+
+	p.Args = []string{
+		fmt.Sprintf("-Xms%dM", p.InitialHeapMB),
+		fmt.Sprintf("-Xmx%dM", p.MaxHeapMB),
+		gcFlags[p.GC],
+	}
+	for _, id := range opts.Presets {
+		if preset, ok := findPreset(id); ok {
+			p.Args = append(p.Args, preset.Flags...)
+		}
+	}
+
+	p.Warnings = warnings(p)
+	return p
+}
+
+// warnings reports heap sizes that do not fit the system's memory.
+func warnings(p *Plan) []string {
+	if p.Memory.Total == 0 {
+		return []string{fmt.Sprintf("System memory could not be read; using a %d MB heap.", p.MaxHeapMB)}
+	}
+
+	var w []string
+	totalMB := int(p.Memory.Total / mb)
+	availableMB := int(p.Memory.Available / mb)
+
+	switch {
+	case p.MaxHeapMB >= totalMB:
+		w = append(w, fmt.Sprintf("The %d MB heap is larger than the %d MB of installed memory.", p.MaxHeapMB, totalMB))
+	case p.MaxHeapMB > totalMB-systemReserveMB:
+		w = append(w, fmt.Sprintf("The %d MB heap leaves less than %d MB for the system.", p.MaxHeapMB, systemReserveMB))
+	}
+
+	if p.Memory.Available > 0 && p.MaxHeapMB > availableMB {
+		w = append(w, fmt.Sprintf("The %d MB heap exceeds the %d MB of memory currently free; close other programs or lower it.", p.MaxHeapMB, availableMB))
+	}
+	return w
+}
@@ -0,0 +1,20 @@
+package jvmopts
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDefaultGCIsG1(t *testing.T) {
+	mem := Memory{Total: 16 << 30, Available: 8 << 30}
+
+	p := NewPlan(TargetClient, mem, Options{GC: GCDefault})
+	if p.GC != GCG1 || !slices.Contains(p.Args, gcFlags[GCG1]) {
+		t.Fatalf("default plan GC = %q, args = %v, want G1 passed explicitly", p.GC, p.Args)
+	}
+
+	p = NewPlan(TargetClient, mem, Options{GC: GCZ})
+	if slices.Contains(p.Args, gcFlags[GCG1]) || !slices.Contains(p.Args, gcFlags[GCZ]) {
+		t.Fatalf("ZGC plan args = %v", p.Args)
+	}
+}
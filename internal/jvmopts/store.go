@@ -0,0 +1,171 @@
+package jvmopts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"hytale-launcher/internal/ioutil"
+)
+
+// Store persists the user's JVM options per launch target and launch profile.
+type Store struct {
+	filePath string
+
+	mu      sync.RWMutex
+	options map[string]Options
+}
+
+// NewStore creates a Store with the given storage file path.
+func NewStore(filePath string) *Store {
+	return &Store{
+		filePath: filePath,
+		options:  make(map[string]Options),
+	}
+}
+
+// Load loads the JVM options from disk.
+func (s *Store) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var options map[string]Options
+	err := ioutil.LoadWithBackup(s.filePath, func(data []byte) error {
+		var loaded map[string]Options
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("failed to unmarshal jvm options: %w", err)
+		}
+		options = loaded
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read jvm options: %w", err)
+	}
+
+	// A file holding "null" decodes to a nil map, which Set cannot write to.
+	if options == nil {
+		options = make(map[string]Options)
+	}
+
+	// Drop entries that are no longer valid (e.g., a removed preset)
+	// rather than failing every launch.
+	for key, opts := range options {
+		if err := opts.Validate(); err != nil {
+			delete(options, key)
+		}
+	}
+
+	s.options = options
+	return nil
+}
+
+// saveLocked saves the options without acquiring the lock.
+// Caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(s.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.options, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal jvm options: %w", err)
+	}
+
+	if err := ioutil.WriteFileAtomic(s.filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write jvm options: %w", err)
+	}
+	return nil
+}
+
+// Key returns the options key of a launch profile of target. An empty
+// profile is the target's options, which are also used by profiles without
+// options of their own.
+func Key(target, profile string) string {
+	if profile == "" {
+		return target
+	}
+	return target + ":" + profile
+}
+
+// TargetOf returns the launch target of an options key.
+func TargetOf(key string) string {
+	target, _, _ := strings.Cut(key, ":")
+	return target
+}
+
+// Get returns the options for a target or profile, falling back to the
+// target's options for profiles without their own.
+func (s *Store) Get(key string) Options {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	opts, ok := s.options[key]
+	if !ok {
+		opts = s.options[TargetOf(key)]
+	}
+	opts.Presets = slices.Clone(opts.Presets)
+	return opts
+}
+
+// Set validates and stores the options for a target or profile. Zero
+// options restore automatic sizing; a profile keeps them as its own rather
+// than falling back to its target's options.
+func (s *Store) Set(key string, opts Options) error {
+	if target := TargetOf(key); target != TargetClient && target != TargetServer {
+		return fmt.Errorf("unknown target %q", target)
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	zero := opts.MaxHeapMB == 0 && opts.InitialHeapMB == 0 && opts.GC == GCDefault && len(opts.Presets) == 0
+	if zero && key == TargetOf(key) {
+		delete(s.options, key)
+	} else {
+		s.options[key] = opts
+	}
+	return s.saveLocked()
+}
+
+// Delete removes a profile's own options, so that it uses its target's
+// options again.
+func (s *Store) Delete(key string) error {
+	if key == TargetOf(key) {
+		return fmt.Errorf("cannot delete the %s options", key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.options[key]; !ok {
+		return nil
+	}
+	delete(s.options, key)
+	return s.saveLocked()
+}
+
+// Move moves a profile's own options to a new key, as when the profile is
+// renamed. Nothing happens if from has no options of its own.
+func (s *Store) Move(from, to string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	opts, ok := s.options[from]
+	if !ok || from == to {
+		return nil
+	}
+	delete(s.options, from)
+	s.options[to] = opts
+	return s.saveLocked()
+}
@@ -0,0 +1,66 @@
+package jvmopts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreLoadNull(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jvm_options.json")
+	if err := os.WriteFile(path, []byte("null"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStore(path)
+	if err := s.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := s.Set(TargetClient, Options{MaxHeapMB: 4096}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got := s.Get(TargetClient).MaxHeapMB; got != 4096 {
+		t.Fatalf("MaxHeapMB = %d, want 4096", got)
+	}
+}
+
+func TestStoreProfileOptions(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "jvm_options.json"))
+	if err := s.Set(TargetClient, Options{MaxHeapMB: 4096}); err != nil {
+		t.Fatal(err)
+	}
+
+	alex := Key(TargetClient, "alex")
+	if got := s.Get(alex).MaxHeapMB; got != 4096 {
+		t.Fatalf("profile without options: MaxHeapMB = %d, want the client's 4096", got)
+	}
+
+	// Zero options are kept for a profile instead of falling back.
+	if err := s.Set(alex, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Get(alex).MaxHeapMB; got != 0 {
+		t.Fatalf("profile with automatic sizing: MaxHeapMB = %d, want 0", got)
+	}
+
+	sam := Key(TargetClient, "sam")
+	if err := s.Move(alex, sam); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Get(alex).MaxHeapMB; got != 4096 {
+		t.Fatalf("options left under the old key: MaxHeapMB = %d", got)
+	}
+	if got := s.Get(sam).MaxHeapMB; got != 0 {
+		t.Fatalf("moved options: MaxHeapMB = %d, want 0", got)
+	}
+
+	if err := s.Delete(sam); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Get(sam).MaxHeapMB; got != 4096 {
+		t.Fatalf("deleted profile options: MaxHeapMB = %d, want the client's 4096", got)
+	}
+	if err := s.Delete(TargetClient); err == nil {
+		t.Fatal("deleted the client options")
+	}
+}
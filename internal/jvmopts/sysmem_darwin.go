@@ -0,0 +1,26 @@
+//go:build darwin
+
+package jvmopts
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// SystemMemory reads total memory and the kernel's available-memory level.
+func SystemMemory() (Memory, error) {
+	total, err := unix.SysctlUint64("hw.memsize")
+	if err != nil {
+		return Memory{}, fmt.Errorf("failed to read hw.memsize: %w", err)
+	}
+
+	mem := Memory{Total: total, Available: total}
+
+	// memorystatus_level is the percentage of memory available before the
+	// system comes under memory pressure, which includes reclaimable cache.
+	if level, err := unix.SysctlUint32("kern.memorystatus_level"); err == nil && level <= 100 {
+		mem.Available = total / 100 * uint64(level)
+	}
+	return mem, nil
+}
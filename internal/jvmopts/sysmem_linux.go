@@ -0,0 +1,51 @@
+//go:build linux
+
+package jvmopts
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SystemMemory reads total and available memory from /proc/meminfo.
+func SystemMemory() (Memory, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return Memory{}, fmt.Errorf("failed to read meminfo: %w", err)
+	}
+	defer f.Close()
+
+	var mem Memory
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+
+		// Values are reported in KiB, e.g. "16314532 kB".
+		kib, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch key {
+		case "MemTotal":
+			mem.Total = kib * 1024
+		case "MemAvailable":
+			mem.Available = kib * 1024
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Memory{}, fmt.Errorf("failed to read meminfo: %w", err)
+	}
+
+	if mem.Total == 0 {
+		return Memory{}, errors.New("meminfo does not report MemTotal")
+	}
+	return mem, nil
+}
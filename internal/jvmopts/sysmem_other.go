@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package jvmopts
+
+import "errors"
+
+// SystemMemory is not supported on this platform.
+func SystemMemory() (Memory, error) {
+	return Memory{}, errors.New("reading system memory is not supported on this platform")
+}
@@ -3,7 +3,11 @@ package keyring
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
 )
 
 const (
@@ -11,32 +15,89 @@ const (
 	ServiceName = "com.hypixel.hytale-launcher"
 )
 
+// Backend names identify where secrets are stored.
+const (
+	// BackendAuto selects the OS keyring when it is usable and the secrets
+	// file otherwise. It is only accepted by Configure.
+	BackendAuto = "auto"
+
+	// BackendSystem is the OS keyring.
+	BackendSystem = "system"
+
+	// BackendFile is the passphrase-protected secrets file. Without a
+	// passphrase in PassphraseEnv the passphrase is kept in a key file next
+	// to it, so the secrets are not encrypted at rest.
+	BackendFile = "file"
+
+	// BackendDisabled stores nothing; secrets do not survive a restart.
+	BackendDisabled = "disabled"
+)
+
 // keyStore is the interface for platform-specific keyring implementations.
 type keyStore interface {
 	get(service, key string) ([]byte, error)
 	set(service, key string, value []byte) error
+	setEnabled(enabled bool)
+	backend() string
 }
 
-// store is the platform-specific keyring implementation.
-var store keyStore
+var (
+	// storeMu protects store and filePath.
+	storeMu sync.RWMutex
+
+	// store is the active keyring implementation.
+	store keyStore
+
+	// filePath is the location of the secrets file used by the file backend.
+	filePath string
+
+	// knownKeys are the key names registered with RegisterKey or used so far.
+	knownKeys = make(map[string]struct{})
+	knownMu   sync.Mutex
+)
 
 func init() {
 	store = newKeyStore()
 }
 
+// current returns the active key store.
+func current() keyStore {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// remember records a key name so it is included in migrations.
+func remember(key string) {
+	knownMu.Lock()
+	defer knownMu.Unlock()
+	knownKeys[key] = struct{}{}
+}
+
+// RegisterKey declares a key name used by the launcher so that it is carried
+// over when secrets are migrated between backends.
+func RegisterKey(key string) {
+	remember(key)
+}
+
 // Get retrieves a value from the keyring.
 func Get(key string) ([]byte, error) {
-	return store.get(ServiceName, key)
+	remember(key)
+	return current().get(ServiceName, key)
 }
 
 // Set stores a value in the keyring.
 func Set(key string, value []byte) error {
-	return store.set(ServiceName, key, value)
+	remember(key)
+	return current().set(ServiceName, key, value)
 }
 
 // GetOrGenKey retrieves a key from the keyring, or generates a new one if it doesn't exist.
 // The key is 32 bytes (256 bits) suitable for use with AES-256.
 func GetOrGenKey(key string) ([]byte, error) {
+	remember(key)
+	store := current()
+
 	// Try to get existing key
 	existingKey, err := store.get(ServiceName, key)
 	if err != nil {
@@ -61,3 +122,188 @@ func GetOrGenKey(key string) ([]byte, error) {
 
 	return newKey, nil
 }
+
+// newStore creates a key store for the given backend.
+func newStore(backend, path string) (keyStore, error) {
+	switch backend {
+	case BackendSystem:
+		s := newKeyStore()
+		s.setEnabled(true)
+		return s, nil
+	case BackendFile:
+		if path == "" {
+			return nil, errors.New("no secrets file configured")
+		}
+		return newFileKeyStore(path), nil
+	case BackendDisabled:
+		s := newKeyStore()
+		s.setEnabled(false)
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown keyring backend %q", backend)
+	}
+}
+
+// resolve turns BackendAuto into a concrete backend.
+// The OS keyring is used when it is enabled on this platform and reachable;
+// otherwise secrets go to the file so they persist across restarts.
+func resolve(backend string) string {
+	if backend != BackendAuto {
+		return backend
+	}
+	if enabledByDefault() && systemAvailable() {
+		return BackendSystem
+	}
+	return BackendFile
+}
+
+// Configure selects the backend used for secrets without migrating existing
+// ones. path is the secrets file used by the file backend.
+func Configure(backend, path string) error {
+	resolved := resolve(backend)
+
+	s, err := newStore(resolved, path)
+	if err != nil {
+		return err
+	}
+
+	storeMu.Lock()
+	store = s
+	filePath = path
+	storeMu.Unlock()
+
+	slog.Info("selected secret store", "backend", resolved, "requested", backend)
+	return nil
+}
+
+// UseFile switches secret storage to an encrypted file at path instead of the
+// OS keyring. The passphrase is read from PassphraseEnv, or generated once and
+// kept in a key file next to the secrets file when the variable is unset. In
+// that case anyone who can read the secrets file can also read the key file,
+// so the secrets are only as safe as the directory holding them.
+func UseFile(path string) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = newFileKeyStore(path)
+	filePath = path
+}
+
+// MigrationReport describes the result of moving secrets between backends.
+type MigrationReport struct {
+	// From is the backend secrets were read from.
+	From string `json:"from"`
+
+	// To is the backend secrets were written to.
+	To string `json:"to"`
+
+	// Migrated lists the keys copied to the new backend.
+	Migrated []string `json:"migrated"`
+
+	// Missing lists known keys that had no value in the old backend.
+	Missing []string `json:"missing"`
+}
+
+// ErrSecretsWouldBeLost is returned when migrating to BackendDisabled while
+// secrets are stored, as the disabled backend cannot hold them.
+var ErrSecretsWouldBeLost = errors.New("stored secrets would be lost")
+
+// Migrate copies every known secret from the active backend to backend and
+// then makes it the active one. The old backend is left untouched so a failed
+// migration can simply be retried. Migrating to BackendDisabled is refused
+// with ErrSecretsWouldBeLost while any secret is stored.
+func Migrate(backend string) (*MigrationReport, error) {
+	storeMu.RLock()
+	from, path := store, filePath
+	storeMu.RUnlock()
+
+	resolved := resolve(backend)
+	report := &MigrationReport{From: from.backend(), To: resolved}
+	if report.From == resolved {
+		return report, nil
+	}
+
+	to, err := newStore(resolved, path)
+	if err != nil {
+		return nil, err
+	}
+
+	knownMu.Lock()
+	keys := make([]string, 0, len(knownKeys))
+	for key := range knownKeys {
+		keys = append(keys, key)
+	}
+	knownMu.Unlock()
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		value, err := from.get(ServiceName, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key '%s' from %s backend: %w", key, report.From, err)
+		}
+		if value == nil {
+			report.Missing = append(report.Missing, key)
+			continue
+		}
+		if resolved == BackendDisabled {
+			return nil, fmt.Errorf("%w: the %s backend does not store secrets; key '%s' is held by the %s backend",
+				ErrSecretsWouldBeLost, resolved, key, report.From)
+		}
+		if err := to.set(ServiceName, key, value); err != nil {
+			return nil, fmt.Errorf("failed to write key '%s' to %s backend: %w", key, resolved, err)
+		}
+		report.Migrated = append(report.Migrated, key)
+	}
+
+	storeMu.Lock()
+	store = to
+	storeMu.Unlock()
+
+	slog.Info("migrated secrets",
+		"from", report.From,
+		"to", report.To,
+		"migrated", len(report.Migrated),
+		"missing", len(report.Missing),
+	)
+	return report, nil
+}
+
+// Status describes the active secret store.
+type Status struct {
+	// Backend is the active backend.
+	Backend string `json:"backend"`
+
+	// SystemAvailable reports whether the OS keyring can be reached.
+	SystemAvailable bool `json:"system_available"`
+
+	// FilePath is the secrets file used by the file backend.
+	FilePath string `json:"file_path,omitempty"`
+
+	// PassphraseSource is "env" or "keyfile" when the file backend is active.
+	PassphraseSource string `json:"passphrase_source,omitempty"`
+
+	// EncryptedAtRest reports whether secrets are protected by something not
+	// stored alongside them: the OS keyring, or a file backend passphrase
+	// supplied through PassphraseEnv.
+	EncryptedAtRest bool `json:"encrypted_at_rest"`
+}
+
+// CurrentStatus reports which backend is in use.
+func CurrentStatus() Status {
+	storeMu.RLock()
+	s, path := store, filePath
+	storeMu.RUnlock()
+
+	status := Status{
+		Backend:         s.backend(),
+		SystemAvailable: systemAvailable(),
+	}
+	switch status.Backend {
+	case BackendSystem:
+		status.EncryptedAtRest = true
+	case BackendFile:
+		status.FilePath = path
+		status.PassphraseSource = passphraseSource()
+		status.EncryptedAtRest = status.PassphraseSource == "env"
+	}
+	return status
+}
@@ -5,20 +5,31 @@ package keyring
 import (
 	"encoding/base64"
 	"errors"
+	"sync/atomic"
 
 	gokeyring "github.com/zalando/go-keyring"
 )
 
 // darwinKeyStore implements keyStore for macOS using the system Keychain.
-type darwinKeyStore struct{}
+type darwinKeyStore struct {
+	// enabled is read by every get and set and changed by setEnabled, which
+	// may happen concurrently.
+	enabled atomic.Bool
+}
 
 // newKeyStore creates a new macOS keyring implementation.
 func newKeyStore() keyStore {
-	return &darwinKeyStore{}
+	k := &darwinKeyStore{}
+	k.enabled.Store(enabledByDefault())
+	return k
 }
 
 // get retrieves a value from the macOS Keychain.
 func (k *darwinKeyStore) get(service, key string) ([]byte, error) {
+	if !k.enabled.Load() {
+		return nil, nil
+	}
+
 	secret, err := gokeyring.Get(service, key)
 	if err != nil {
 		if errors.Is(err, gokeyring.ErrNotFound) {
@@ -38,7 +49,34 @@ func (k *darwinKeyStore) get(service, key string) ([]byte, error) {
 
 // set stores a value in the macOS Keychain.
 func (k *darwinKeyStore) set(service, key string, value []byte) error {
+	if !k.enabled.Load() {
+		return nil
+	}
+
 	// Encode to base64 for storage
 	encoded := base64.StdEncoding.EncodeToString(value)
 	return gokeyring.Set(service, key, encoded)
 }
+
+// setEnabled turns use of the macOS Keychain on or off.
+func (k *darwinKeyStore) setEnabled(enabled bool) {
+	k.enabled.Store(enabled)
+}
+
+// backend returns BackendSystem, or BackendDisabled if the macOS Keychain is turned off.
+func (k *darwinKeyStore) backend() string {
+	if !k.enabled.Load() {
+		return BackendDisabled
+	}
+	return BackendSystem
+}
+
+// enabledByDefault reports whether the macOS Keychain is used when no override is set.
+func enabledByDefault() bool {
+	return true
+}
+
+// systemAvailable reports whether the macOS Keychain can be reached.
+func systemAvailable() bool {
+	return true
+}
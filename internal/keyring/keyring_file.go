@@ -0,0 +1,277 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+
+	"hytale-launcher/internal/ioutil"
+)
+
+// PassphraseEnv is the environment variable that supplies the passphrase
+// protecting the secrets file.
+const PassphraseEnv = "HYTALE_LAUNCHER_SECRETS_PASSPHRASE"
+
+// Argon2id parameters used to derive the secrets file key from the passphrase.
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 2
+	argonKeyLen  = 32
+	saltLen      = 16
+)
+
+// secretsFile is the on-disk format of the encrypted secrets file.
+type secretsFile struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	Salt    string `json:"salt"`
+	Data    string `json:"data"`
+}
+
+// fileKeyStore implements keyStore on top of a single file encrypted with
+// AES-GCM under a key derived from a passphrase with Argon2id.
+//
+// The file is only encrypted at rest when the passphrase comes from
+// PassphraseEnv. Otherwise a random passphrase is generated and stored in
+// plaintext next to the secrets file, which keeps secrets out of casual view
+// and ties them to this storage directory but protects nothing from someone
+// who can read that directory.
+type fileKeyStore struct {
+	path string
+
+	mu      sync.Mutex
+	secrets map[string][]byte
+	salt    []byte
+	key     []byte
+}
+
+// newFileKeyStore creates a file-backed key store at path.
+func newFileKeyStore(path string) *fileKeyStore {
+	return &fileKeyStore{path: path}
+}
+
+// get retrieves a value from the secrets file.
+func (k *fileKeyStore) get(service, key string) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.loadLocked(); err != nil {
+		return nil, err
+	}
+	return k.secrets[service+"/"+key], nil
+}
+
+// set stores a value in the secrets file.
+func (k *fileKeyStore) set(service, key string, value []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.loadLocked(); err != nil {
+		return err
+	}
+	k.secrets[service+"/"+key] = value
+	return k.saveLocked()
+}
+
+// setEnabled is a no-op; the secrets file is always used once selected.
+func (k *fileKeyStore) setEnabled(bool) {}
+
+// backend returns BackendFile.
+func (k *fileKeyStore) backend() string {
+	return BackendFile
+}
+
+// passphraseSource reports where the secrets file passphrase comes from.
+func passphraseSource() string {
+	if p, ok := os.LookupEnv(PassphraseEnv); ok && p != "" {
+		return "env"
+	}
+	return "keyfile"
+}
+
+// loadLocked reads and decrypts the secrets file on first use.
+// Caller must hold k.mu.
+func (k *fileKeyStore) loadLocked() error {
+	if k.secrets != nil {
+		return nil
+	}
+
+	err := ioutil.LoadWithBackup(k.path, k.decodeLocked)
+	if errors.Is(err, os.ErrNotExist) {
+		k.secrets = make(map[string][]byte)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read secrets file: %w", err)
+	}
+	return nil
+}
+
+// decodeLocked decrypts the contents of a secrets file.
+// Caller must hold k.mu.
+func (k *fileKeyStore) decodeLocked(data []byte) error {
+	var f secretsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+	if f.KDF != "argon2id" {
+		return fmt.Errorf("unsupported secrets file kdf %q", f.KDF)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(f.Salt)
+	if err != nil {
+		return fmt.Errorf("invalid secrets file salt: %w", err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(f.Data)
+	if err != nil {
+		return fmt.Errorf("invalid secrets file data: %w", err)
+	}
+
+	passphrase, err := k.passphrase()
+	if err != nil {
+		return err
+	}
+	key := argon2.IDKey(passphrase, salt, f.Time, f.Memory, f.Threads, argonKeyLen)
+
+	plain, err := open(key, sealed)
+	if err != nil {
+		return errors.New("unable to decrypt secrets file: wrong passphrase or corrupted file")
+	}
+
+	var secrets map[string][]byte
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return fmt.Errorf("failed to parse decrypted secrets: %w", err)
+	}
+
+	k.secrets = secrets
+	k.salt = salt
+	k.key = key
+	return nil
+}
+
+// saveLocked encrypts and writes the secrets file.
+// Caller must hold k.mu.
+func (k *fileKeyStore) saveLocked() error {
+	if k.key == nil {
+		passphrase, err := k.passphrase()
+		if err != nil {
+			return err
+		}
+		k.salt = make([]byte, saltLen)
+		if _, err := rand.Read(k.salt); err != nil {
+			return err
+		}
+		k.key = argon2.IDKey(passphrase, k.salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	}
+
+	plain, err := json.Marshal(k.secrets)
+	if err != nil {
+		return err
+	}
+	sealed, err := seal(k.key, plain)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(secretsFile{
+		Version: 1,
+		KDF:     "argon2id",
+		Time:    argonTime,
+		Memory:  argonMemory,
+		Threads: argonThreads,
+		Salt:    base64.StdEncoding.EncodeToString(k.salt),
+		Data:    base64.StdEncoding.EncodeToString(sealed),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(k.path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFileAtomic(k.path, data, 0600)
+}
+
+// passphrase returns the passphrase protecting the secrets file: the value of
+// PassphraseEnv, or else the contents of the key file, generated on first use.
+func (k *fileKeyStore) passphrase() ([]byte, error) {
+	if p, ok := os.LookupEnv(PassphraseEnv); ok && p != "" {
+		return []byte(p), nil
+	}
+
+	keyPath := k.path + ".key"
+	data, err := os.ReadFile(keyPath)
+	if err == nil {
+		return data, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read secrets key file: %w", err)
+	}
+
+	generated := make([]byte, 32)
+	if _, err := rand.Read(generated); err != nil {
+		return nil, err
+	}
+	passphrase := []byte(base64.StdEncoding.EncodeToString(generated))
+
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFileAtomic(keyPath, passphrase, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write secrets key file: %w", err)
+	}
+	slog.Warn("secrets file passphrase stored in a key file; set "+PassphraseEnv+" to encrypt secrets at rest",
+		"path", keyPath,
+	)
+	return passphrase, nil
+}
+
+// seal encrypts plain with AES-GCM, prefixing the nonce.
+func seal(key, plain []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+// open decrypts data produced by seal.
+func open(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
@@ -6,25 +6,30 @@ import (
 	"encoding/base64"
 	"errors"
 	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	gokeyring "github.com/zalando/go-keyring"
 )
 
 // linuxKeyStore implements keyStore for Linux using the system keyring.
 type linuxKeyStore struct {
-	enabled bool
+	// enabled is read by every get and set and changed by setEnabled, which
+	// may happen concurrently.
+	enabled atomic.Bool
 }
 
 // newKeyStore creates a new Linux keyring implementation.
 func newKeyStore() keyStore {
-	// Check if keyring is enabled via environment variable
-	_, enabled := os.LookupEnv("HYTALE_LAUNCHER_ENABLE_KEYRING")
-	return &linuxKeyStore{enabled: enabled}
+	k := &linuxKeyStore{}
+	k.enabled.Store(enabledByDefault())
+	return k
 }
 
 // get retrieves a value from the Linux keyring.
 func (k *linuxKeyStore) get(service, key string) ([]byte, error) {
-	if !k.enabled {
+	if !k.enabled.Load() {
 		return nil, nil
 	}
 
@@ -47,7 +52,7 @@ func (k *linuxKeyStore) get(service, key string) ([]byte, error) {
 
 // set stores a value in the Linux keyring.
 func (k *linuxKeyStore) set(service, key string, value []byte) error {
-	if !k.enabled {
+	if !k.enabled.Load() {
 		return nil
 	}
 
@@ -55,3 +60,43 @@ func (k *linuxKeyStore) set(service, key string, value []byte) error {
 	encoded := base64.StdEncoding.EncodeToString(value)
 	return gokeyring.Set(service, key, encoded)
 }
+
+// setEnabled turns use of the Linux keyring on or off.
+func (k *linuxKeyStore) setEnabled(enabled bool) {
+	k.enabled.Store(enabled)
+}
+
+// backend returns BackendSystem, or BackendDisabled if the Linux keyring is turned off.
+func (k *linuxKeyStore) backend() string {
+	if !k.enabled.Load() {
+		return BackendDisabled
+	}
+	return BackendSystem
+}
+
+// enabledByDefault reports whether the Linux keyring is used when no override
+// is set. It is opt-in via environment variable.
+func enabledByDefault() bool {
+	_, enabled := os.LookupEnv("HYTALE_LAUNCHER_ENABLE_KEYRING")
+	return enabled
+}
+
+// probeTimeout bounds how long the Secret Service availability probe may take.
+const probeTimeout = 2 * time.Second
+
+// systemAvailable reports whether a Secret Service provider answers on the
+// session bus. The result is cached after the first probe.
+var systemAvailable = sync.OnceValue(func() bool {
+	result := make(chan bool, 1)
+	go func() {
+		_, err := gokeyring.Get(ServiceName, "availability-probe")
+		result <- err == nil || errors.Is(err, gokeyring.ErrNotFound)
+	}()
+
+	select {
+	case ok := <-result:
+		return ok
+	case <-time.After(probeTimeout):
+		return false
+	}
+})
@@ -0,0 +1,68 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestMigrateToDisabledKeepsSecrets(t *testing.T) {
+	t.Setenv(PassphraseEnv, "test passphrase")
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	UseFile(path)
+
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	if err := Set("test-key", secret); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Migrate(BackendDisabled); !errors.Is(err, ErrSecretsWouldBeLost) {
+		t.Fatalf("Migrate err = %v, want ErrSecretsWouldBeLost", err)
+	}
+	if backend := CurrentStatus().Backend; backend != BackendFile {
+		t.Fatalf("backend = %s, want %s", backend, BackendFile)
+	}
+	got, err := Get("test-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatalf("secret = %x, want %x", got, secret)
+	}
+}
+
+func TestFileStatusReportsKeyFile(t *testing.T) {
+	t.Setenv(PassphraseEnv, "")
+	UseFile(filepath.Join(t.TempDir(), "secrets.json"))
+
+	status := CurrentStatus()
+	if status.PassphraseSource != "keyfile" || status.EncryptedAtRest {
+		t.Fatalf("status = %+v, want an unencrypted key file", status)
+	}
+
+	t.Setenv(PassphraseEnv, "test passphrase")
+	if status := CurrentStatus(); !status.EncryptedAtRest {
+		t.Fatalf("status = %+v, want encrypted at rest", status)
+	}
+}
+
+func TestSetEnabledConcurrently(t *testing.T) {
+	s := newKeyStore()
+
+	// Run with -race: toggling the store must not race with its readers.
+	var wg sync.WaitGroup
+	for n := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.setEnabled(n%2 == 0)
+		}()
+		go func() {
+			defer wg.Done()
+			s.backend()
+		}()
+	}
+	wg.Wait()
+}
@@ -5,20 +5,31 @@ package keyring
 import (
 	"encoding/base64"
 	"errors"
+	"sync/atomic"
 
 	gokeyring "github.com/zalando/go-keyring"
 )
 
 // windowsKeyStore implements keyStore for Windows using the Credential Manager.
-type windowsKeyStore struct{}
+type windowsKeyStore struct {
+	// enabled is read by every get and set and changed by setEnabled, which
+	// may happen concurrently.
+	enabled atomic.Bool
+}
 
 // newKeyStore creates a new Windows keyring implementation.
 func newKeyStore() keyStore {
-	return &windowsKeyStore{}
+	k := &windowsKeyStore{}
+	k.enabled.Store(enabledByDefault())
+	return k
 }
 
 // get retrieves a value from Windows Credential Manager.
 func (k *windowsKeyStore) get(service, key string) ([]byte, error) {
+	if !k.enabled.Load() {
+		return nil, nil
+	}
+
 	secret, err := gokeyring.Get(service, key)
 	if err != nil {
 		if errors.Is(err, gokeyring.ErrNotFound) {
@@ -38,7 +49,34 @@ func (k *windowsKeyStore) get(service, key string) ([]byte, error) {
 
 // set stores a value in Windows Credential Manager.
 func (k *windowsKeyStore) set(service, key string, value []byte) error {
+	if !k.enabled.Load() {
+		return nil
+	}
+
 	// Encode to base64 for storage
 	encoded := base64.StdEncoding.EncodeToString(value)
 	return gokeyring.Set(service, key, encoded)
 }
+
+// setEnabled turns use of the Windows Credential Manager on or off.
+func (k *windowsKeyStore) setEnabled(enabled bool) {
+	k.enabled.Store(enabled)
+}
+
+// backend returns BackendSystem, or BackendDisabled if the Windows Credential Manager is turned off.
+func (k *windowsKeyStore) backend() string {
+	if !k.enabled.Load() {
+		return BackendDisabled
+	}
+	return BackendSystem
+}
+
+// enabledByDefault reports whether the Windows Credential Manager is used when no override is set.
+func enabledByDefault() bool {
+	return true
+}
+
+// systemAvailable reports whether the Windows Credential Manager can be reached.
+func systemAvailable() bool {
+	return true
+}
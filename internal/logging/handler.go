@@ -0,0 +1,136 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// config is the active handler configuration. It is replaced as a whole so
+// that log calls never see a half-applied change.
+type config struct {
+	handler slog.Handler
+	base    slog.Level
+	levels  map[string]slog.Level
+	min     slog.Level
+}
+
+// levelFor returns the minimum level for records logged from pkg.
+func (c *config) levelFor(pkg string) slog.Level {
+	if l, ok := c.levels[pkg]; ok {
+		return l
+	}
+	return c.base
+}
+
+// dynamicHandler forwards records to the current configuration's handler,
+// filtering them by the level configured for the logging package.
+type dynamicHandler struct {
+	cfg *atomic.Pointer[config]
+
+	// ops replays WithAttrs and WithGroup calls on the current handler.
+	ops []func(slog.Handler) slog.Handler
+}
+
+// Enabled reports whether any package logs at level.
+func (h *dynamicHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.cfg.Load().min
+}
+
+// Handle writes r if its level is enabled for the package that logged it.
+func (h *dynamicHandler) Handle(ctx context.Context, r slog.Record) error {
+	cfg := h.cfg.Load()
+	if r.Level < cfg.levelFor(packageOf(r.PC)) {
+		return nil
+	}
+
+	handler := cfg.handler
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a handler that adds attrs to every record.
+func (h *dynamicHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(inner slog.Handler) slog.Handler { return inner.WithAttrs(attrs) })
+}
+
+// WithGroup returns a handler that nests attributes under name.
+func (h *dynamicHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(inner slog.Handler) slog.Handler { return inner.WithGroup(name) })
+}
+
+// with returns a copy of h with op appended.
+func (h *dynamicHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &dynamicHandler{cfg: h.cfg, ops: append(ops, op)}
+}
+
+// packages caches the package name for each program counter.
+var packages sync.Map
+
+// packageOf returns the short name of the package containing pc, such as
+// "download" for hytale-launcher/internal/download.
+func packageOf(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	if name, ok := packages.Load(pc); ok {
+		return name.(string)
+	}
+
+	frames := runtime.CallersFrames([]uintptr{pc})
+	frame, _ := frames.Next()
+	fn := frame.Function
+
+	// "hytale-launcher/internal/download.(*Downloader).Run" -> "download"
+	slash := strings.LastIndexByte(fn, '/')
+	pkg := fn[slash+1:]
+	if dot := strings.IndexByte(pkg, '.'); dot >= 0 {
+		pkg = pkg[:dot]
+	}
+
+	packages.Store(pc, pkg)
+	return pkg
+}
+
+// newHandler creates the handler for the given output format.
+func newHandler(w io.Writer, json bool) slog.Handler {
+	// Levels are filtered by dynamicHandler, so let everything through.
+	opts := &slog.HandlerOptions{Level: slog.Level(-100)}
+	if json {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// ParseLevels parses per-package levels written as "download=debug,auth=warn".
+func ParseLevels(s string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		pkg, level, ok := strings.Cut(part, "=")
+		pkg = strings.TrimSpace(pkg)
+		if !ok || pkg == "" {
+			return nil, fmt.Errorf("%q is not package=level", part)
+		}
+
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+			return nil, fmt.Errorf("unknown level for %s: %w", pkg, err)
+		}
+		levels[pkg] = l
+	}
+	return levels, nil
+}
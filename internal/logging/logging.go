@@ -8,7 +8,9 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"hytale-launcher/internal/build"
 	"hytale-launcher/internal/hytale"
@@ -19,16 +21,40 @@ const (
 	// logFileName is the name of the log file.
 	logFileName = "hytale-launcher.log"
 
-	// maxLogFileSize is the maximum size of the log file before it is removed (10MB).
+	// maxLogFileSize is the size at which the log file is rotated (10MB).
 	maxLogFileSize = 10 * 1024 * 1024
+
+	// DefaultRetention is how many rotated log files are kept by default.
+	DefaultRetention = 5
 )
 
+// Options configures log output at runtime.
+type Options struct {
+	// Debug lowers the default level to debug.
+	Debug bool
+
+	// JSON writes JSON lines instead of text.
+	JSON bool
+
+	// Levels overrides the level per package, as "download=debug,auth=warn".
+	Levels string
+
+	// Retention is how many rotated log files are kept.
+	Retention int
+}
+
 var (
 	// logFile is the current open log file.
-	logFile *os.File
+	logFile *rotatingFile
+
+	// output is where log lines are written: the log file and stdout.
+	output io.Writer = os.Stdout
 
 	// initOnce ensures Init is only called once.
 	initOnce sync.Once
+
+	// active is the configuration used by the default slog handler.
+	active atomic.Pointer[config]
 )
 
 // Init initializes the logging system.
@@ -46,7 +72,7 @@ func Init() error {
 
 func doInit() error {
 	// Get the log file path in the storage directory.
-	logPath := hytale.InStorageDir(logFileName)
+	logPath := Path()
 	logDir := filepath.Dir(logPath)
 
 	// Ensure the storage directory exists.
@@ -54,46 +80,70 @@ func doInit() error {
 		return fmt.Errorf("unable to create storage directory: %w", err)
 	}
 
-	// Check if the log file exists and is too large.
-	if info, err := os.Stat(logPath); err == nil {
-		if info.Size() > maxLogFileSize {
-			if err := os.Remove(logPath); err != nil {
-				return fmt.Errorf("unable to remove oversized log file %s: %w", logPath, err)
-			}
-		}
-	}
-
-	// Open the log file for appending.
-	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	// Open the log file, rotating the previous session's log.
+	f, err := openRotatingFile(logPath, maxLogFileSize, DefaultRetention)
 	if err != nil {
-		return fmt.Errorf("unable to open log file %s: %w", logPath, err)
+		return err
 	}
 	logFile = f
 
 	// Create a multi-writer that writes to both the file and stdout.
-	multiWriter := io.MultiWriter(logFile, os.Stdout)
+	output = io.MultiWriter(logFile, os.Stdout)
 
 	// Configure the standard logger.
-	log.SetOutput(multiWriter)
+	log.SetOutput(output)
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	// Determine the log level based on environment.
-	var logLevel slog.Level
-	if build.DebugLogging() {
-		logLevel = slog.LevelDebug
-	} else {
-		logLevel = slog.LevelInfo
+	// Start with the defaults until the settings are loaded.
+	if err := Configure(Options{Retention: DefaultRetention}); err != nil {
+		return err
 	}
+	slog.SetDefault(slog.New(&dynamicHandler{cfg: &active}))
 
-	// Configure slog with a text handler.
-	handler := slog.NewTextHandler(multiWriter, &slog.HandlerOptions{
-		Level: logLevel,
-	})
-	slog.SetDefault(slog.New(handler))
+	return nil
+}
 
+// Configure applies log options at runtime. Debug logging and JSON output
+// are also forced on when the build or environment requests them.
+func Configure(opts Options) error {
+	levels, err := ParseLevels(opts.Levels)
+	if err != nil {
+		return err
+	}
+
+	cfg := &config{
+		handler: newHandler(output, opts.JSON || build.JSONLogging()),
+		base:    slog.LevelInfo,
+		levels:  levels,
+	}
+	if opts.Debug || build.DebugLogging() {
+		cfg.base = slog.LevelDebug
+	}
+	cfg.min = cfg.base
+	for _, l := range levels {
+		cfg.min = min(cfg.min, l)
+	}
+	active.Store(cfg)
+
+	if logFile != nil && opts.Retention > 0 {
+		logFile.SetKeep(opts.Retention)
+	}
 	return nil
 }
 
+// Path returns the path of the current launcher log.
+func Path() string {
+	return hytale.InStorageDir(logFileName)
+}
+
+// RotatedLogs returns the paths of the rotated launcher logs, newest first.
+// The newest is the log of the previous launch, unless the current one grew
+// past the size limit. Rotated logs are gzip-compressed shortly after
+// rotation.
+func RotatedLogs() []string {
+	return archivesOf(strings.TrimSuffix(Path(), filepath.Ext(logFileName)))
+}
+
 // Close closes the log file.
 // It should be called when the application exits.
 func Close() {
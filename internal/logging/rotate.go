@@ -0,0 +1,240 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatingFile is a log file that is rotated when it grows past maxSize.
+// Rotated files are gzip-compressed next to it and the newest keep are kept.
+type rotatingFile struct {
+	path    string
+	maxSize int64
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+	keep int
+
+	// compressing serializes background compression and pruning.
+	compressing sync.Mutex
+}
+
+// openRotatingFile opens the log at path for appending. An existing
+// non-empty log is rotated first so every launch starts a fresh file.
+func openRotatingFile(path string, maxSize int64, keep int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxSize: maxSize, keep: keep}
+
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		if err := r.archive(); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+
+	// Finish archives left uncompressed by a previous run.
+	go r.compressPending()
+	return r, nil
+}
+
+// open opens the current log file for appending.
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to open log file %s: %w", r.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f = f
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends p to the log, rotating first if p would push it past maxSize.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotateLocked(); err != nil {
+			// Keep logging to the oversized file rather than losing entries.
+			fmt.Fprintf(os.Stderr, "unable to rotate log file: %v\n", err)
+		}
+	}
+
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotateLocked archives the current file and starts a new one.
+// Caller must hold r.mu.
+func (r *rotatingFile) rotateLocked() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+
+	archiveErr := r.archive()
+	if err := r.open(); err != nil {
+		return err
+	}
+	if archiveErr != nil {
+		return archiveErr
+	}
+
+	go r.compressPending()
+	return nil
+}
+
+// archive renames the current file to a timestamped name.
+func (r *rotatingFile) archive() error {
+	base := r.archiveBase()
+	stamp := time.Now().Format("20060102-150405")
+
+	name := fmt.Sprintf("%s-%s.log", base, stamp)
+	for i := 2; fileExists(name) || fileExists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s-%s-%d.log", base, stamp, i)
+	}
+	return os.Rename(r.path, name)
+}
+
+// archiveBase returns the path prefix of rotated files.
+func (r *rotatingFile) archiveBase() string {
+	return strings.TrimSuffix(r.path, filepath.Ext(r.path))
+}
+
+// compressPending compresses uncompressed archives and prunes old ones.
+func (r *rotatingFile) compressPending() {
+	r.compressing.Lock()
+	defer r.compressing.Unlock()
+
+	pending, _ := filepath.Glob(r.archiveBase() + "-*.log")
+	for _, name := range pending {
+		if err := gzipFile(name); err != nil {
+			slog.Warn("failed to compress rotated log", "path", name, "error", err)
+		}
+	}
+	r.prune()
+}
+
+// prune deletes the oldest compressed archives beyond the retention count.
+func (r *rotatingFile) prune() {
+	r.mu.Lock()
+	keep := r.keep
+	r.mu.Unlock()
+
+	archives, _ := filepath.Glob(r.archiveBase() + "-*.log.gz")
+	if len(archives) <= keep {
+		return
+	}
+
+	// Names embed the rotation time, so they sort oldest first.
+	sort.Strings(archives)
+	for _, old := range archives[:len(archives)-keep] {
+		if err := os.Remove(old); err != nil {
+			slog.Warn("failed to delete old log", "path", old, "error", err)
+		}
+	}
+}
+
+// archivesOf returns the rotated files with the path prefix base, newest
+// first.
+func archivesOf(base string) []string {
+	plain, _ := filepath.Glob(base + "-*.log")
+	compressed, _ := filepath.Glob(base + "-*.log.gz")
+	archives := append(plain, compressed...)
+
+	// Names embed the rotation time; a numeric suffix marks a later rotation
+	// within the same second.
+	stamp := func(name string) string {
+		return strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ".log")
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return stamp(archives[i]) > stamp(archives[j])
+	})
+	return archives
+}
+
+// SetKeep changes how many rotated files are kept and prunes any excess.
+func (r *rotatingFile) SetKeep(keep int) {
+	r.mu.Lock()
+	changed := r.keep != keep
+	r.keep = keep
+	r.mu.Unlock()
+
+	if changed {
+		go r.compressPending()
+	}
+}
+
+// Close closes the current log file.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// gzipFile compresses path to path.gz and removes the original.
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := path + ".gz.tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	zw := gzip.NewWriter(out)
+	zw.Name = filepath.Base(path)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		return err
+	}
+
+	in.Close()
+	return os.Remove(path)
+}
+
+// fileExists reports whether a file exists at path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
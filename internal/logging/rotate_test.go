@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchivesNewestFirst(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "hytale-launcher")
+	for _, name := range []string{
+		"hytale-launcher-20260101-120000.log.gz",
+		"hytale-launcher-20260102-120000.log.gz",
+		"hytale-launcher-20260102-120000-2.log",
+		"hytale-launcher-20260101-120000.log.gz.tmp",
+		"hytale-launcher.log",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		base + "-20260102-120000-2.log",
+		base + "-20260102-120000.log.gz",
+		base + "-20260101-120000.log.gz",
+	}
+	if got := archivesOf(base); !slices.Equal(got, want) {
+		t.Fatalf("archives = %q, want %q", got, want)
+	}
+}
+
+func TestOpenRotatesPreviousLog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hytale-launcher.log")
+	if err := os.WriteFile(path, []byte("previous session\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := openRotatingFile(path, 1024, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// Wait for the background compression, so it is not cut short by the
+	// test's cleanup.
+	var archives []string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		archives = archivesOf(filepath.Join(dir, "hytale-launcher"))
+		if len(archives) == 1 && strings.HasSuffix(archives[0], ".gz") {
+			break
+		}
+	}
+	if len(archives) != 1 || !strings.HasSuffix(archives[0], ".gz") {
+		t.Fatalf("archives = %q, want the compressed previous session", archives)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != 0 {
+		t.Fatalf("current log was not started fresh: %v", err)
+	}
+}
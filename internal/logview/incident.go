@@ -0,0 +1,149 @@
+package logview
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kinds of incidents.
+const (
+	// IncidentException is a logged Java stack trace.
+	IncidentException = "exception"
+
+	// IncidentCrash is a JVM fatal error or a crash report file.
+	IncidentCrash = "crash"
+)
+
+// fatalBanner starts the JVM's fatal error report.
+const fatalBanner = "A fatal error has been detected by the Java Runtime Environment"
+
+// maxIncidentLines caps the text kept for an incident.
+const maxIncidentLines = 200
+
+// Incident is a stack trace or crash extracted from a log.
+type Incident struct {
+	// ID identifies the incident within its source.
+	ID string `json:"id"`
+
+	// Source is the ID of the source the incident was found in.
+	Source string `json:"source"`
+
+	// Kind is IncidentException or IncidentCrash.
+	Kind string `json:"kind"`
+
+	// Time is the timestamp of the entry the incident was found in.
+	Time time.Time `json:"time,omitzero"`
+
+	// Line is the line the incident starts on.
+	Line int `json:"line"`
+
+	// Title summarizes the incident, such as the exception and its message.
+	Title string `json:"title"`
+
+	// Text is the full stack trace or crash section.
+	Text string `json:"text"`
+}
+
+// incidentOf returns the incident contained in an entry, if any.
+func incidentOf(e *Entry) (Incident, bool) {
+	text := e.text()
+	lines := strings.Split(text, "\n")
+
+	kind := ""
+	switch {
+	case strings.Contains(text, fatalBanner):
+		kind = IncidentCrash
+	case hasStackFrame(lines):
+		kind = IncidentException
+	default:
+		return Incident{}, false
+	}
+
+	if len(lines) > maxIncidentLines {
+		lines = append(lines[:maxIncidentLines], fmt.Sprintf("... %d more lines", len(lines)-maxIncidentLines))
+	}
+	return Incident{
+		ID:     fmt.Sprintf("%s:%d", e.Source, e.Line),
+		Source: e.Source,
+		Kind:   kind,
+		Time:   e.Time,
+		Line:   e.Line,
+		Title:  incidentTitle(kind, e, lines),
+		Text:   strings.Join(lines, "\n"),
+	}, true
+}
+
+// crashFileIncident returns the incident for a whole crash report file.
+func crashFileIncident(src Source, entries []Entry) (Incident, bool) {
+	if len(entries) == 0 {
+		return Incident{}, false
+	}
+
+	var b strings.Builder
+	for i := range entries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(entries[i].text())
+	}
+	lines := strings.Split(b.String(), "\n")
+	if len(lines) > maxIncidentLines {
+		lines = append(lines[:maxIncidentLines], fmt.Sprintf("... %d more lines", len(lines)-maxIncidentLines))
+	}
+
+	return Incident{
+		ID:     src.ID + ":1",
+		Source: src.ID,
+		Kind:   IncidentCrash,
+		Time:   src.ModifiedAt,
+		Line:   1,
+		Title:  incidentTitle(IncidentCrash, &entries[0], lines),
+		Text:   strings.Join(lines, "\n"),
+	}, true
+}
+
+// hasStackFrame reports whether any line is a Java stack frame.
+func hasStackFrame(lines []string) bool {
+	for _, l := range lines {
+		if stackFrame.MatchString(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// incidentTitle picks a one-line summary for an incident.
+func incidentTitle(kind string, e *Entry, lines []string) string {
+	if kind == IncidentCrash {
+		// The line after the banner names the signal or exception code.
+		for i, l := range lines {
+			if strings.Contains(l, fatalBanner) {
+				for _, next := range lines[i+1:] {
+					if t := strings.TrimSpace(strings.TrimLeft(next, "#")); t != "" {
+						return t
+					}
+				}
+			}
+		}
+		return "JVM crash"
+	}
+
+	for _, l := range lines {
+		if exceptionLine.MatchString(strings.TrimSpace(l)) {
+			return strings.TrimSpace(l)
+		}
+	}
+	return e.Message
+}
+
+// FindIncidents returns the incidents contained in entries.
+func FindIncidents(entries []Entry) []Incident {
+	var result []Incident
+	for i := range entries {
+		if inc, ok := incidentOf(&entries[i]); ok {
+			result = append(result, inc)
+		}
+	}
+	return result
+}
@@ -0,0 +1,182 @@
+// Package logview indexes the launcher, server and client log files, parses
+// their entries and pulls Java stack traces and JVM crashes out as incidents.
+package logview
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of log sources.
+const (
+	KindLauncher = "launcher"
+	KindServer   = "server"
+	KindClient   = "client"
+	KindCrash    = "crash"
+)
+
+// Normalized log levels, from least to most severe.
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+// levelRank orders levels by severity.
+var levelRank = map[string]int{
+	LevelDebug: 0,
+	LevelInfo:  1,
+	LevelWarn:  2,
+	LevelError: 3,
+	LevelFatal: 4,
+}
+
+// rank returns the severity of a level. Entries without a level rank as info.
+func rank(level string) int {
+	if r, ok := levelRank[level]; ok {
+		return r
+	}
+	return levelRank[LevelInfo]
+}
+
+// Source is a log file.
+type Source struct {
+	// ID identifies the source and is stable across restarts.
+	ID string `json:"id"`
+
+	// Kind is where the log comes from.
+	Kind string `json:"kind"`
+
+	// Name is the file name.
+	Name string `json:"name"`
+
+	// Path is the file's absolute path.
+	Path string `json:"path"`
+
+	// Size is the file size in bytes.
+	Size int64 `json:"size"`
+
+	// ModifiedAt is the file's modification time.
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// Entry is a parsed log entry. Lines that continue an entry, such as stack
+// trace frames, are collected in Detail.
+type Entry struct {
+	// Source is the ID of the source the entry was read from.
+	Source string `json:"source"`
+
+	// Line is the 1-based line number the entry starts on.
+	Line int `json:"line"`
+
+	// Time is the entry's timestamp, if the line had one.
+	Time time.Time `json:"time,omitzero"`
+
+	// Level is the normalized level, if the line had one.
+	Level string `json:"level,omitempty"`
+
+	// Message is the entry's first line without its timestamp and level.
+	Message string `json:"message"`
+
+	// Detail holds the continuation lines.
+	Detail string `json:"detail,omitempty"`
+}
+
+// text returns the entry's message and detail.
+func (e *Entry) text() string {
+	if e.Detail == "" {
+		return e.Message
+	}
+	return e.Message + "\n" + e.Detail
+}
+
+// Filter selects entries. Zero fields match everything.
+type Filter struct {
+	// Sources limits the result to these source IDs.
+	Sources []string `json:"sources,omitempty"`
+
+	// MinLevel excludes entries less severe than this level.
+	MinLevel string `json:"minLevel,omitempty"`
+
+	// Text matches entries containing it, ignoring case.
+	Text string `json:"text,omitempty"`
+
+	// From and To bound the entry time. Entries without a timestamp never
+	// match a time range.
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+
+	// Limit is the maximum number of entries returned; the newest are kept.
+	// Zero means DefaultLimit.
+	Limit int `json:"limit,omitempty"`
+}
+
+// DefaultLimit is the number of entries returned when a filter has no limit.
+const DefaultLimit = 1000
+
+// matchesSource reports whether entries of source pass the filter.
+func (f *Filter) matchesSource(source string) bool {
+	if len(f.Sources) == 0 {
+		return true
+	}
+	for _, s := range f.Sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether e passes the filter. needle is the lowercased Text.
+func (f *Filter) matches(e *Entry, needle string) bool {
+	if f.MinLevel != "" && rank(e.Level) < rank(f.MinLevel) {
+		return false
+	}
+	if f.From != nil && (e.Time.IsZero() || e.Time.Before(*f.From)) {
+		return false
+	}
+	if f.To != nil && (e.Time.IsZero() || e.Time.After(*f.To)) {
+		return false
+	}
+	if needle != "" && !strings.Contains(strings.ToLower(e.text()), needle) {
+		return false
+	}
+	return true
+}
+
+// File returns the source for a single log file, or false if it is missing.
+func File(kind, path string) (Source, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return Source{}, false
+	}
+	return Source{
+		ID:         kind + ":" + info.Name(),
+		Kind:       kind,
+		Name:       info.Name(),
+		Path:       path,
+		Size:       info.Size(),
+		ModifiedAt: info.ModTime(),
+	}, true
+}
+
+// Glob returns the sources for the files matching pattern, newest first.
+func Glob(kind, pattern string) []Source {
+	matches, _ := filepath.Glob(pattern)
+
+	var sources []Source
+	for _, m := range matches {
+		if src, ok := File(kind, m); ok {
+			sources = append(sources, src)
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].ModifiedAt.After(sources[j].ModifiedAt)
+	})
+	return sources
+}
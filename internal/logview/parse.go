@@ -0,0 +1,253 @@
+package logview
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stderrPrefix marks server lines that were written to stderr.
+const stderrPrefix = "[ERROR] "
+
+var (
+	// slogLine matches the launcher's slog text handler output.
+	slogLine = regexp.MustCompile(`^time=(\S+) level=(\S+) (.*)$`)
+
+	// bracketLine matches "[2026/01/13 17:29:39   INFO] message".
+	bracketLine = regexp.MustCompile(`^\[(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2})\s+(\w+)\]\s*(.*)$`)
+
+	// isoLine matches "2026-01-13 17:29:39,123 [WARN] message" and variants.
+	isoLine = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)\s+\[?([A-Za-z]+)\]?:?\s+(.*)$`)
+
+	// stdLine matches the standard library logger: "2026/01/13 17:29:39 file.go:12: message".
+	stdLine = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2})\s+(.*)$`)
+
+	// exceptionLine matches the first line of a Java stack trace.
+	exceptionLine = regexp.MustCompile(`^(?:Exception in thread "[^"]*" )?(?:[a-zA-Z_$][\w$]*\.)+[\w$]*(?:Exception|Error|Throwable)(?::.*)?$`)
+
+	// stackFrame matches a Java stack frame.
+	stackFrame = regexp.MustCompile(`^\s*at [\w$.<>/]+\(`)
+)
+
+// levelNames maps level spellings from the various log formats.
+var levelNames = map[string]string{
+	"TRACE":    LevelDebug,
+	"FINEST":   LevelDebug,
+	"FINER":    LevelDebug,
+	"FINE":     LevelDebug,
+	"DEBUG":    LevelDebug,
+	"CONFIG":   LevelInfo,
+	"INFO":     LevelInfo,
+	"WARN":     LevelWarn,
+	"WARNING":  LevelWarn,
+	"ERR":      LevelError,
+	"ERROR":    LevelError,
+	"SEVERE":   LevelError,
+	"FATAL":    LevelFatal,
+	"CRITICAL": LevelFatal,
+}
+
+// normalizeLevel maps a level spelling to a normalized level, or "" if it is
+// not a level.
+func normalizeLevel(s string) string {
+	return levelNames[strings.ToUpper(s)]
+}
+
+// parseTime parses a timestamp in one of the supported layouts. Times without
+// a zone are local.
+func parseTime(s string) time.Time {
+	s = strings.Replace(s, ",", ".", 1)
+	for _, layout := range []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999Z0700",
+		"2006-01-02 15:04:05.999999999Z07:00",
+		"2006-01-02 15:04:05.999999999Z0700",
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	for _, layout := range []string{
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02 15:04:05.999999999",
+		"2006/01/02 15:04:05",
+	} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseLine parses a line that starts a new entry.
+func parseLine(line string) Entry {
+	var stderr bool
+	if rest, ok := strings.CutPrefix(line, stderrPrefix); ok {
+		line, stderr = rest, true
+	}
+
+	e := Entry{Message: line}
+	if je, ok := parseJSONLine(line); ok {
+		e = je
+	} else if m := slogLine.FindStringSubmatch(line); m != nil {
+		e.Time = parseTime(m[1])
+		e.Level = normalizeLevel(m[2])
+		e.Message = slogMessage(m[3])
+	} else if m := bracketLine.FindStringSubmatch(line); m != nil {
+		e.Time = parseTime(m[1])
+		e.Level = normalizeLevel(m[2])
+		e.Message = m[3]
+	} else if m := isoLine.FindStringSubmatch(line); m != nil && normalizeLevel(m[2]) != "" {
+		e.Time = parseTime(m[1])
+		e.Level = normalizeLevel(m[2])
+		e.Message = m[3]
+	} else if m := stdLine.FindStringSubmatch(line); m != nil {
+		e.Time = parseTime(m[1])
+		e.Message = m[2]
+	}
+
+	if (stderr || exceptionLine.MatchString(e.Message)) && e.Level == "" {
+		e.Level = LevelError
+	}
+	return e
+}
+
+// parseJSONLine parses a line written by the launcher's slog JSON handler.
+// Attributes other than time, level and msg are kept after the message in
+// the order they were written, as for text lines.
+func parseJSONLine(line string) (Entry, bool) {
+	if !strings.HasPrefix(line, "{") {
+		return Entry{}, false
+	}
+
+	dec := json.NewDecoder(strings.NewReader(line))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return Entry{}, false
+	}
+
+	var e Entry
+	var msg string
+	var attrs []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return Entry{}, false
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return Entry{}, false
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return Entry{}, false
+		}
+
+		switch key {
+		case "time":
+			var t string
+			if json.Unmarshal(value, &t) == nil {
+				e.Time = parseTime(t)
+			}
+		case "level":
+			var level string
+			if json.Unmarshal(value, &level) == nil {
+				e.Level = normalizeLevel(level)
+			}
+		case "msg":
+			if json.Unmarshal(value, &msg) != nil {
+				msg = string(value)
+			}
+		default:
+			attrs = append(attrs, key+"="+jsonAttr(value))
+		}
+	}
+	if e.Time.IsZero() && e.Level == "" {
+		return Entry{}, false
+	}
+
+	e.Message = strings.TrimSpace(msg + " " + strings.Join(attrs, " "))
+	return e, true
+}
+
+// jsonAttr formats an attribute value like the text handler does: strings
+// without spaces or quotes unquoted, everything else as JSON.
+func jsonAttr(value json.RawMessage) string {
+	var s string
+	if json.Unmarshal(value, &s) == nil && s != "" && !strings.ContainsAny(s, " \t\"=") {
+		return s
+	}
+	return string(bytes.TrimSpace(value))
+}
+
+// slogMessage unquotes the msg attribute of a slog line and keeps the
+// remaining attributes after it.
+func slogMessage(s string) string {
+	rest, ok := strings.CutPrefix(s, "msg=")
+	if !ok {
+		return s
+	}
+	if strings.HasPrefix(rest, `"`) {
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return rest
+		}
+		msg, _ := strconv.Unquote(quoted)
+		return strings.TrimSpace(msg + rest[len(quoted):])
+	}
+	return rest
+}
+
+// continues reports whether line continues the entry prev rather than
+// starting a new one.
+func continues(prev *Entry, line string) bool {
+	line = strings.TrimPrefix(line, stderrPrefix)
+	switch {
+	case line == "":
+		return false
+	case line[0] == ' ' || line[0] == '\t':
+		return true
+	case strings.HasPrefix(line, "Caused by:"), strings.HasPrefix(line, "Suppressed:"), strings.HasPrefix(line, "..."):
+		return true
+	case line[0] == '#':
+		return strings.HasPrefix(prev.Message, "#")
+	case strings.HasPrefix(line, "Exception in thread"):
+		// An uncaught exception is reported on its own.
+		return false
+	}
+	return exceptionLine.MatchString(line)
+}
+
+// parser turns lines into entries, attaching continuation lines to the
+// previous entry.
+type parser struct {
+	source  string
+	lineNo  int
+	entries []Entry
+}
+
+// add parses the next line. It returns the index of the entry that changed.
+func (p *parser) add(line string) int {
+	p.lineNo++
+	line = strings.TrimRight(line, "\r")
+
+	if n := len(p.entries); n > 0 && continues(&p.entries[n-1], line) {
+		prev := &p.entries[n-1]
+		if prev.Detail != "" {
+			prev.Detail += "\n"
+		}
+		prev.Detail += strings.TrimPrefix(line, stderrPrefix)
+		return n - 1
+	}
+	if strings.TrimSpace(line) == "" {
+		return len(p.entries)
+	}
+
+	e := parseLine(line)
+	e.Source = p.source
+	e.Line = p.lineNo
+	p.entries = append(p.entries, e)
+	return len(p.entries) - 1
+}
@@ -0,0 +1,81 @@
+package logview
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		level   string
+		message string
+		timed   bool
+	}{
+		{
+			name:    "slog text",
+			line:    `time=2026-01-13T17:29:39.123+01:00 level=WARN msg="disk almost full" free=12`,
+			level:   LevelWarn,
+			message: "disk almost full free=12",
+			timed:   true,
+		},
+		{
+			name:    "slog json",
+			line:    `{"time":"2026-01-13T17:29:39.123+01:00","level":"ERROR","msg":"download failed","url":"https://example.com/a b","attempt":3}`,
+			level:   LevelError,
+			message: `download failed url="https://example.com/a b" attempt=3`,
+			timed:   true,
+		},
+		{
+			name:    "slog json without attributes",
+			line:    `{"time":"2026-01-13T17:29:39Z","level":"DEBUG","msg":"tick"}`,
+			level:   LevelDebug,
+			message: "tick",
+			timed:   true,
+		},
+		{
+			name:    "bracketed",
+			line:    "[2026/01/13 17:29:39   INFO] Server started",
+			level:   LevelInfo,
+			message: "Server started",
+			timed:   true,
+		},
+		{
+			name:    "json that is not a log record",
+			line:    `{"players":3}`,
+			message: `{"players":3}`,
+		},
+		{
+			name:    "stderr",
+			line:    stderrPrefix + "something broke",
+			level:   LevelError,
+			message: "something broke",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := parseLine(tt.line)
+			if e.Level != tt.level {
+				t.Errorf("level = %q, want %q", e.Level, tt.level)
+			}
+			if e.Message != tt.message {
+				t.Errorf("message = %q, want %q", e.Message, tt.message)
+			}
+			if e.Time.IsZero() == tt.timed {
+				t.Errorf("time = %v, want timed %v", e.Time, tt.timed)
+			}
+		})
+	}
+}
+
+func TestFilterMatchesJSONEntries(t *testing.T) {
+	e := parseLine(`{"time":"2026-01-13T17:29:39Z","level":"WARN","msg":"slow"}`)
+
+	from := time.Date(2026, 1, 13, 0, 0, 0, 0, time.UTC)
+	filter := Filter{MinLevel: LevelWarn, From: &from}
+	if !filter.matches(&e, "") {
+		t.Fatalf("filter dropped JSON entry %+v", e)
+	}
+}
@@ -0,0 +1,286 @@
+package logview
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// maxEntries caps the entries kept per source; the oldest are dropped.
+const maxEntries = 100_000
+
+// ErrUnknownSource is returned when a source ID is not found.
+var ErrUnknownSource = errors.New("unknown log source")
+
+// SourceFunc returns the log files currently available.
+type SourceFunc func() []Source
+
+// fileIndex holds the parsed entries of one log file. The file is read
+// incrementally as it grows and re-read if it shrinks or is replaced.
+type fileIndex struct {
+	src    Source
+	offset int64
+	head   []byte
+	parser parser
+
+	// pending is the index of the first entry not yet returned by Poll.
+	pending int
+}
+
+// headSize is how many leading bytes are compared to detect a replaced file.
+const headSize = 256
+
+// update reads anything appended since the last update. It returns the index
+// of the first entry that changed, or len(entries) if nothing did.
+func (fi *fileIndex) update(src Source) (int, error) {
+	if isCompressed(src.Path) {
+		return fi.updateCompressed(src)
+	}
+
+	f, err := os.Open(src.Path)
+	if err != nil {
+		return len(fi.parser.entries), fmt.Errorf("failed to open log %s: %w", src.Name, err)
+	}
+	defer f.Close()
+
+	// Start over if the file was truncated or rewritten, as the server log is
+	// on every start.
+	head := make([]byte, min(int64(headSize), src.Size))
+	if _, err := io.ReadFull(f, head); err != nil {
+		return len(fi.parser.entries), fmt.Errorf("failed to read log %s: %w", src.Name, err)
+	}
+	if src.Size < fi.offset || !bytes.HasPrefix(head, fi.head[:min(len(fi.head), len(head))]) {
+		fi.offset = 0
+		fi.parser = parser{source: src.ID}
+		fi.pending = 0
+	}
+	fi.src = src
+	fi.head = head
+
+	first := len(fi.parser.entries)
+	if src.Size == fi.offset {
+		return first, nil
+	}
+
+	if _, err := f.Seek(fi.offset, io.SeekStart); err != nil {
+		return first, err
+	}
+	data, err := io.ReadAll(io.LimitReader(f, src.Size-fi.offset))
+	if err != nil {
+		return first, fmt.Errorf("failed to read log %s: %w", src.Name, err)
+	}
+
+	// Leave a partially written last line for the next update.
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return first, nil
+	}
+	fi.offset += int64(end + 1)
+
+	for _, line := range strings.Split(string(data[:end]), "\n") {
+		first = min(first, fi.parser.add(line))
+	}
+
+	if over := len(fi.parser.entries) - maxEntries; over > 0 {
+		fi.parser.entries = append([]Entry(nil), fi.parser.entries[over:]...)
+		first = max(first-over, 0)
+		fi.pending = max(fi.pending-over, 0)
+	}
+	return first, nil
+}
+
+// updateCompressed reads a gzip-compressed log, such as a rotated launcher
+// log. Compressed logs are not appended to, so the file is only read again
+// if it was replaced.
+func (fi *fileIndex) updateCompressed(src Source) (int, error) {
+	if fi.offset == src.Size && fi.src.ModifiedAt.Equal(src.ModifiedAt) {
+		return len(fi.parser.entries), nil
+	}
+
+	f, err := os.Open(src.Path)
+	if err != nil {
+		return len(fi.parser.entries), fmt.Errorf("failed to open log %s: %w", src.Name, err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return len(fi.parser.entries), fmt.Errorf("failed to read log %s: %w", src.Name, err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return len(fi.parser.entries), fmt.Errorf("failed to read log %s: %w", src.Name, err)
+	}
+
+	fi.src = src
+	fi.offset = src.Size
+	fi.parser = parser{source: src.ID}
+	fi.pending = 0
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		fi.parser.add(line)
+	}
+	if over := len(fi.parser.entries) - maxEntries; over > 0 {
+		fi.parser.entries = append([]Entry(nil), fi.parser.entries[over:]...)
+	}
+	return 0, nil
+}
+
+// isCompressed reports whether the log at path is gzip-compressed.
+func isCompressed(path string) bool {
+	return strings.HasSuffix(path, ".gz")
+}
+
+// Service indexes log files and answers queries against them.
+type Service struct {
+	sources SourceFunc
+
+	mu    sync.Mutex
+	files map[string]*fileIndex
+}
+
+// NewService creates a Service for the log files returned by sources.
+func NewService(sources SourceFunc) *Service {
+	return &Service{
+		sources: sources,
+		files:   make(map[string]*fileIndex),
+	}
+}
+
+// Sources returns the available log files.
+func (s *Service) Sources() []Source {
+	return s.sources()
+}
+
+// refreshLocked brings every index up to date and drops vanished files.
+// Caller must hold s.mu.
+func (s *Service) refreshLocked() error {
+	current := s.sources()
+	seen := make(map[string]bool, len(current))
+
+	var errs []error
+	for _, src := range current {
+		seen[src.ID] = true
+
+		fi, ok := s.files[src.ID]
+		if !ok {
+			fi = &fileIndex{parser: parser{source: src.ID}}
+			s.files[src.ID] = fi
+		}
+		first, err := fi.update(src)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fi.pending = min(fi.pending, first)
+	}
+
+	for id := range s.files {
+		if !seen[id] {
+			delete(s.files, id)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Query returns the entries matching filter in file order, keeping the newest
+// Limit entries of each source.
+func (s *Service) Query(filter Filter) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.refreshLocked()
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	needle := strings.ToLower(filter.Text)
+
+	var result []Entry
+	for _, src := range s.sortedLocked() {
+		if !filter.matchesSource(src.src.ID) {
+			continue
+		}
+
+		var matched []Entry
+		entries := src.parser.entries
+		for i := len(entries) - 1; i >= 0 && len(matched) < limit; i-- {
+			if filter.matches(&entries[i], needle) {
+				matched = append(matched, entries[i])
+			}
+		}
+		for i := len(matched) - 1; i >= 0; i-- {
+			result = append(result, matched[i])
+		}
+	}
+	return result, err
+}
+
+// Incidents returns the stack traces and crashes found in the given sources,
+// or in all sources if none are given.
+func (s *Service) Incidents(sources []string) ([]Incident, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.refreshLocked()
+
+	filter := Filter{Sources: sources}
+	var result []Incident
+	for _, fi := range s.sortedLocked() {
+		if !filter.matchesSource(fi.src.ID) {
+			continue
+		}
+
+		if fi.src.Kind == KindCrash {
+			if inc, ok := crashFileIncident(fi.src, fi.parser.entries); ok {
+				result = append(result, inc)
+			}
+			continue
+		}
+		result = append(result, FindIncidents(fi.parser.entries)...)
+	}
+	return result, err
+}
+
+// Poll reads anything appended to the log files and returns the entries that
+// are new or changed since the last call. The first call returns everything.
+func (s *Service) Poll() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.refreshLocked()
+
+	var changed []Entry
+	for _, fi := range s.sortedLocked() {
+		changed = append(changed, fi.parser.entries[fi.pending:]...)
+		fi.pending = len(fi.parser.entries)
+	}
+	return changed, err
+}
+
+// Path returns the file path of a source.
+func (s *Service) Path(id string) (string, error) {
+	for _, src := range s.sources() {
+		if src.ID == id {
+			return src.Path, nil
+		}
+	}
+	return "", ErrUnknownSource
+}
+
+// sortedLocked returns the file indexes in source order.
+// Caller must hold s.mu.
+func (s *Service) sortedLocked() []*fileIndex {
+	var result []*fileIndex
+	for _, src := range s.sources() {
+		if fi, ok := s.files[src.ID]; ok {
+			result = append(result, fi)
+		}
+	}
+	return result
+}
@@ -0,0 +1,38 @@
+package logview
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQueryCompressedSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hytale-launcher-20260113-172939.log.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	zw.Write([]byte("time=2026-01-13T17:29:39Z level=INFO msg=starting\n" +
+		"time=2026-01-13T17:29:40Z level=ERROR msg=crashed\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	svc := NewService(func() []Source {
+		src, _ := File(KindLauncher, path)
+		return []Source{src}
+	})
+
+	for range 2 {
+		entries, err := svc.Query(Filter{MinLevel: LevelError})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].Message != "crashed" || entries[0].Line != 2 {
+			t.Fatalf("entries = %+v", entries)
+		}
+	}
+}
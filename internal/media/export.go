@@ -0,0 +1,86 @@
+package media
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+)
+
+// Export writes the given items to a .zip archive at dest, grouped by
+// folder. Media files are already compressed, so they are stored as-is.
+func (x *Index) Export(ids []string, dest string) error {
+	items := make([]Item, 0, len(ids))
+	for _, id := range ids {
+		item, err := x.Get(id)
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+
+	tmp := dest + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create media archive: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	if err := writeArchive(f, items); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to archive media: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// writeArchive writes items as a zip archive, renaming duplicate names.
+func writeArchive(out io.Writer, items []Item) error {
+	zw := zip.NewWriter(out)
+	used := make(map[string]bool, len(items))
+
+	for _, item := range items {
+		name := path.Join(Folders[item.Kind], item.Name)
+		for i := 2; used[name]; i++ {
+			ext := path.Ext(item.Name)
+			base := item.Name[:len(item.Name)-len(ext)]
+			name = path.Join(Folders[item.Kind], fmt.Sprintf("%s (%d)%s", base, i, ext))
+		}
+		used[name] = true
+
+		if err := addFile(zw, name, item.Path); err != nil {
+			return fmt.Errorf("%s: %w", item.Name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// addFile stores the file at src in the archive under name.
+func addFile(zw *zip.Writer, name, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(fi)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Store
+
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, in)
+	return err
+}
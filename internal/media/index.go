@@ -0,0 +1,325 @@
+package media
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"hytale-launcher/internal/ioutil"
+)
+
+// settleTime is how long a file must be unmodified before it is indexed, so
+// captures still being written are picked up on a later scan.
+const settleTime = 2 * time.Second
+
+// ErrNotFound is returned when an item is not in the index.
+var ErrNotFound = errors.New("media item not found")
+
+// Index is the stored media index and thumbnail cache.
+type Index struct {
+	// userDirs returns the client user directories to index: the shared
+	// UserData directory and those of profiles with their own.
+	userDirs func() []string
+
+	// dir holds index.json and the thumbs directory.
+	dir string
+
+	// scanMu serializes scans, so that a file is only decoded once.
+	scanMu sync.Mutex
+
+	mu    sync.Mutex
+	items map[string]*Item
+}
+
+// NewIndex creates an Index for the media in the user directories returned
+// by userDirs, storing its data in dir.
+func NewIndex(userDirs func() []string, dir string) *Index {
+	return &Index{
+		userDirs: userDirs,
+		dir:      dir,
+		items:    make(map[string]*Item),
+	}
+}
+
+// indexPath returns the path of the stored index.
+func (x *Index) indexPath() string {
+	return filepath.Join(x.dir, "index.json")
+}
+
+// thumbPath returns the cached thumbnail path of an item.
+func (x *Index) thumbPath(id string) string {
+	return filepath.Join(x.dir, "thumbs", id+".jpg")
+}
+
+// Load reads the stored index.
+func (x *Index) Load() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	var items []*Item
+	err := ioutil.LoadWithBackup(x.indexPath(), func(data []byte) error {
+		var loaded []*Item
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("failed to unmarshal media index: %w", err)
+		}
+		items = loaded
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read media index: %w", err)
+	}
+
+	x.items = make(map[string]*Item, len(items))
+	for _, item := range items {
+		x.items[item.ID] = item
+	}
+	return nil
+}
+
+// saveLocked saves the index without acquiring the lock.
+// Caller must hold x.mu.
+func (x *Index) saveLocked() error {
+	if err := os.MkdirAll(x.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(x.sortedLocked(Filter{}), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal media index: %w", err)
+	}
+
+	if err := ioutil.WriteFileAtomic(x.indexPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write media index: %w", err)
+	}
+	return nil
+}
+
+// sortedLocked returns copies of the items matching filter, newest first.
+// Caller must hold x.mu.
+func (x *Index) sortedLocked(filter Filter) []Item {
+	result := make([]Item, 0, len(x.items))
+	for _, item := range x.items {
+		if filter.matches(item) {
+			result = append(result, *item)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CapturedAt.Equal(result[j].CapturedAt) {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].CapturedAt.After(result[j].CapturedAt)
+	})
+	return result
+}
+
+// Scan brings the index up to date with the media folders. New files are
+// tagged with gameVersion, and thumbnails are generated for new or changed
+// screenshots. It reports whether anything changed.
+//
+// The folders are walked and thumbnails decoded without holding the index
+// lock, so listing stays responsive while a scan runs; the results are
+// committed at the end.
+func (x *Index) Scan(gameVersion string) (bool, error) {
+	x.scanMu.Lock()
+	defer x.scanMu.Unlock()
+
+	// Items indexed before IDs included the user directory keep their game
+	// version.
+	x.mu.Lock()
+	known := make(map[string]Item, len(x.items))
+	byPath := make(map[string]Item, len(x.items))
+	for id, item := range x.items {
+		known[id] = *item
+		byPath[item.Path] = *item
+	}
+	x.mu.Unlock()
+
+	seen := make(map[string]bool, len(known))
+	var updated []*Item
+	var scanErr error
+
+	for _, userDir := range x.userDirs() {
+		for kind, folder := range Folders {
+			root := filepath.Join(userDir, folder)
+			err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+				if err != nil {
+					if errors.Is(err, os.ErrNotExist) {
+						return nil
+					}
+					return err
+				}
+				if !d.Type().IsRegular() || !indexed(kind, d.Name()) {
+					return nil
+				}
+
+				info, err := d.Info()
+				if err != nil {
+					return nil
+				}
+
+				id := itemID(kind, p)
+				existing, ok := known[id]
+				if ok {
+					seen[id] = true
+					if existing.Size == info.Size() && existing.CapturedAt.Equal(info.ModTime()) {
+						// Rebuild thumbnails removed from the cache.
+						if existing.Thumbnail != "" {
+							if _, err := os.Stat(existing.Thumbnail); err != nil {
+								x.thumbnail(&existing)
+								updated = append(updated, &existing)
+							}
+						}
+						return nil
+					}
+				}
+				if time.Since(info.ModTime()) < settleTime {
+					return nil
+				}
+
+				item := &Item{
+					ID:          id,
+					Kind:        kind,
+					Name:        d.Name(),
+					Path:        p,
+					Size:        info.Size(),
+					CapturedAt:  info.ModTime(),
+					GameVersion: gameVersion,
+				}
+				if ok {
+					item.GameVersion = existing.GameVersion
+				} else if previous, ok := byPath[p]; ok {
+					item.GameVersion = previous.GameVersion
+				}
+				x.thumbnail(item)
+
+				updated = append(updated, item)
+				seen[id] = true
+				return nil
+			})
+			if err != nil {
+				scanErr = fmt.Errorf("failed to scan %s: %w", root, err)
+				break
+			}
+		}
+		if scanErr != nil {
+			break
+		}
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	changed := false
+	for _, item := range updated {
+		// Items deleted while the scan ran stay deleted.
+		if _, wasKnown := known[item.ID]; wasKnown {
+			if _, ok := x.items[item.ID]; !ok {
+				continue
+			}
+		}
+		x.items[item.ID] = item
+		changed = true
+	}
+
+	// Only a complete scan shows which files are gone.
+	if scanErr == nil {
+		for id := range known {
+			if _, ok := x.items[id]; ok && !seen[id] {
+				x.forgetLocked(id)
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return false, scanErr
+	}
+	if err := x.saveLocked(); err != nil {
+		return true, errors.Join(scanErr, err)
+	}
+	return true, scanErr
+}
+
+// thumbnail generates the thumbnail of a screenshot and records its
+// dimensions. Failures are logged and leave the item without a thumbnail.
+// It does not touch the index, so it runs without x.mu.
+func (x *Index) thumbnail(item *Item) {
+	if item.Kind != KindScreenshot {
+		return
+	}
+
+	dst := x.thumbPath(item.ID)
+	width, height, err := makeThumbnail(item.Path, dst)
+	if err != nil {
+		slog.Warn("failed to create thumbnail", "path", item.Path, "error", err)
+		os.Remove(dst)
+		item.Width, item.Height, item.Thumbnail = 0, 0, ""
+		return
+	}
+	item.Width = width
+	item.Height = height
+	item.Thumbnail = dst
+}
+
+// forgetLocked removes an item and its thumbnail from the index.
+// Caller must hold x.mu.
+func (x *Index) forgetLocked(id string) {
+	os.Remove(x.thumbPath(id))
+	delete(x.items, id)
+}
+
+// List returns the items matching filter, newest first.
+func (x *Index) List(filter Filter) []Item {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.sortedLocked(filter)
+}
+
+// Get returns an item by ID.
+func (x *Index) Get(id string) (Item, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	item, ok := x.items[id]
+	if !ok {
+		return Item{}, ErrNotFound
+	}
+	return *item, nil
+}
+
+// Delete deletes the files of the given items and removes them from the index.
+func (x *Index) Delete(ids []string) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	for _, id := range ids {
+		if _, ok := x.items[id]; !ok {
+			return ErrNotFound
+		}
+	}
+
+	var errs []error
+	for _, id := range ids {
+		item := x.items[id]
+		if err := os.Remove(item.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", item.Name, err))
+			continue
+		}
+		x.forgetLocked(id)
+	}
+
+	if err := x.saveLocked(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
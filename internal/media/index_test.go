@@ -0,0 +1,170 @@
+package media
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCapture writes a file under dir that was last modified at modTime.
+func writeCapture(t *testing.T, dir, rel string, data []byte, modTime time.Time) string {
+	t.Helper()
+
+	p := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(p, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// pngData returns a w×h PNG.
+func pngData(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	f, err := os.CreateTemp(t.TempDir(), "*.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// scan scans x and fails the test on errors or if the change report differs.
+func scan(t *testing.T, x *Index, version string, wantChanged bool) {
+	t.Helper()
+
+	changed, err := x.Scan(version)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if changed != wantChanged {
+		t.Fatalf("Scan changed = %v, want %v", changed, wantChanged)
+	}
+}
+
+func TestScan(t *testing.T) {
+	shared, profile := t.TempDir(), t.TempDir()
+	x := NewIndex(func() []string { return []string{shared, profile} }, t.TempDir())
+
+	old := time.Now().Add(-time.Hour)
+	shot := writeCapture(t, shared, "Screenshots/a.png", pngData(t, 640, 360), old)
+	clip := writeCapture(t, profile, "Recordings/b.mp4", []byte("video"), old.Add(time.Minute))
+	writeCapture(t, shared, "Screenshots/notes.txt", []byte("skip"), old)
+	writeCapture(t, shared, "Screenshots/fresh.png", pngData(t, 8, 8), time.Now())
+
+	scan(t, x, "1.0", true)
+
+	items := x.List(Filter{})
+	if len(items) != 2 || items[0].Path != clip || items[1].Path != shot {
+		t.Fatalf("items = %+v, want the recording then the screenshot", items)
+	}
+	s := items[1]
+	if s.Kind != KindScreenshot || s.Width != 640 || s.Height != 360 || s.GameVersion != "1.0" {
+		t.Errorf("screenshot = %+v", s)
+	}
+	if _, err := os.Stat(s.Thumbnail); err != nil {
+		t.Errorf("thumbnail not written: %v", err)
+	}
+	if r := items[0]; r.Kind != KindRecording || r.Thumbnail != "" {
+		t.Errorf("recording = %+v", r)
+	}
+
+	// Nothing changed.
+	scan(t, x, "1.0", false)
+
+	// A thumbnail missing from the cache is rebuilt.
+	if err := os.Remove(s.Thumbnail); err != nil {
+		t.Fatal(err)
+	}
+	scan(t, x, "1.0", true)
+	if _, err := os.Stat(s.Thumbnail); err != nil {
+		t.Errorf("thumbnail not rebuilt: %v", err)
+	}
+
+	// A changed file is re-indexed but keeps the version it was first seen
+	// with.
+	writeCapture(t, shared, "Screenshots/a.png", pngData(t, 320, 200), old.Add(time.Second))
+	scan(t, x, "2.0", true)
+	got, err := x.Get(s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Width != 320 || got.GameVersion != "1.0" {
+		t.Errorf("changed screenshot = %+v", got)
+	}
+
+	// The index survives a reload.
+	reloaded := NewIndex(x.userDirs, x.dir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.List(Filter{})) != 2 {
+		t.Fatalf("reloaded items = %+v", reloaded.List(Filter{}))
+	}
+
+	// Removed files are forgotten along with their thumbnails.
+	if err := os.Remove(shot); err != nil {
+		t.Fatal(err)
+	}
+	scan(t, x, "2.0", true)
+	if _, err := x.Get(s.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("removed screenshot still indexed: %v", err)
+	}
+	if _, err := os.Stat(s.Thumbnail); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("thumbnail of removed screenshot kept: %v", err)
+	}
+}
+
+func TestScanKeepsUnreadableScreenshots(t *testing.T) {
+	dir := t.TempDir()
+	x := NewIndex(func() []string { return []string{dir} }, t.TempDir())
+	writeCapture(t, dir, "Screenshots/broken.png", []byte("not a png"), time.Now().Add(-time.Hour))
+
+	scan(t, x, "1.0", true)
+	items := x.List(Filter{})
+	if len(items) != 1 || items[0].Thumbnail != "" || items[0].Width != 0 {
+		t.Fatalf("items = %+v, want the screenshot without a thumbnail", items)
+	}
+	scan(t, x, "1.0", false)
+}
+
+func TestScanDoesNotRestoreDeletedItems(t *testing.T) {
+	dir := t.TempDir()
+	x := NewIndex(func() []string { return []string{dir} }, t.TempDir())
+	writeCapture(t, dir, "Recordings/a.mp4", []byte("video"), time.Now().Add(-time.Hour))
+	scan(t, x, "1.0", true)
+	id := x.List(Filter{})[0].ID
+
+	// The item is deleted from the gallery once the scan has started, and
+	// the walk still finds the file, changed, as it was before.
+	x.userDirs = func() []string {
+		if err := x.Delete([]string{id}); err != nil {
+			t.Fatal(err)
+		}
+		writeCapture(t, dir, "Recordings/a.mp4", []byte("longer video"), time.Now().Add(-time.Minute))
+		return []string{dir}
+	}
+	if _, err := x.Scan("1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := x.Get(id); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get = %v, want ErrNotFound", err)
+	}
+}
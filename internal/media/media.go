@@ -0,0 +1,107 @@
+// Package media indexes the screenshots and recordings the client writes to
+// its user directories and keeps a cache of image thumbnails for the gallery.
+package media
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Kinds of media.
+const (
+	KindScreenshot = "screenshot"
+	KindRecording  = "recording"
+)
+
+// Folders maps each kind to its directory under a user directory.
+var Folders = map[string]string{
+	KindScreenshot: "Screenshots",
+	KindRecording:  "Recordings",
+}
+
+// extensions lists the file extensions indexed for each kind.
+var extensions = map[string][]string{
+	KindScreenshot: {".png", ".jpg", ".jpeg"},
+	KindRecording:  {".mp4", ".webm", ".mkv"},
+}
+
+// Item is an indexed screenshot or recording.
+type Item struct {
+	// ID identifies the item and is derived from its kind and path.
+	ID string `json:"id"`
+
+	// Kind is KindScreenshot or KindRecording.
+	Kind string `json:"kind"`
+
+	// Name is the file name.
+	Name string `json:"name"`
+
+	// Path is the file's absolute path.
+	Path string `json:"path"`
+
+	// Size is the file size in bytes.
+	Size int64 `json:"size"`
+
+	// CapturedAt is the file's modification time.
+	CapturedAt time.Time `json:"captured_at"`
+
+	// Width and Height are the image dimensions. Zero for recordings.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+
+	// GameVersion is the game version installed when the item was first indexed.
+	GameVersion string `json:"game_version,omitempty"`
+
+	// Thumbnail is the cached thumbnail's path. Empty when none is available.
+	Thumbnail string `json:"thumbnail,omitempty"`
+}
+
+// Filter selects items when listing. Zero fields match everything.
+type Filter struct {
+	// Kind matches items of this kind.
+	Kind string `json:"kind,omitempty"`
+
+	// GameVersion matches items indexed with this game version.
+	GameVersion string `json:"gameVersion,omitempty"`
+
+	// From and To bound the capture time.
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+// matches reports whether item passes the filter.
+func (f Filter) matches(item *Item) bool {
+	if f.Kind != "" && item.Kind != f.Kind {
+		return false
+	}
+	if f.GameVersion != "" && item.GameVersion != f.GameVersion {
+		return false
+	}
+	if f.From != nil && item.CapturedAt.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && item.CapturedAt.After(*f.To) {
+		return false
+	}
+	return true
+}
+
+// indexed reports whether a file name has an extension indexed for kind.
+func indexed(kind, name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range extensions[kind] {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// itemID returns the ID of a kind's file at path.
+func itemID(kind, path string) string {
+	sum := sha1.Sum([]byte(kind + "/" + filepath.ToSlash(path)))
+	return hex.EncodeToString(sum[:])
+}
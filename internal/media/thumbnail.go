@@ -0,0 +1,94 @@
+package media
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png" // register the PNG decoder
+	"os"
+	"path/filepath"
+)
+
+// thumbnailWidth is the width of generated thumbnails in pixels.
+const thumbnailWidth = 320
+
+// makeThumbnail decodes an image, writes a downscaled JPEG copy to dst and
+// returns the source dimensions.
+func makeThumbnail(src, dst string) (width, height int, err error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	thumb := scale(img, thumbnailWidth)
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return 0, 0, err
+	}
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := jpeg.Encode(out, thumb, &jpeg.Options{Quality: 80}); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return 0, 0, err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return 0, 0, err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return 0, 0, err
+	}
+
+	return bounds.Dx(), bounds.Dy(), nil
+}
+
+// scale downsizes img to the given width by averaging the source pixels
+// covered by each destination pixel. Images narrower than width are copied.
+func scale(img image.Image, width int) *image.RGBA {
+	src := img.Bounds()
+	if src.Dx() <= width {
+		width = src.Dx()
+	}
+	height := max(src.Dy()*width/max(src.Dx(), 1), 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := src.Min.Y + y*src.Dy()/height
+		y1 := max(src.Min.Y+(y+1)*src.Dy()/height, y0+1)
+
+		for x := 0; x < width; x++ {
+			x0 := src.Min.X + x*src.Dx()/width
+			x1 := max(src.Min.X+(x+1)*src.Dx()/width, x0+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(b / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
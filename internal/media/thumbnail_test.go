@@ -0,0 +1,80 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+)
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		w, h, width  int
+		wantW, wantH int
+	}{
+		{1280, 720, 320, 320, 180},
+		{640, 640, 320, 320, 320},
+		// Narrower images keep their size.
+		{200, 100, 320, 200, 100},
+		// Very wide images stay at least one pixel high.
+		{4000, 4, 320, 320, 1},
+	}
+	for _, tt := range tests {
+		got := scale(image.NewRGBA(image.Rect(0, 0, tt.w, tt.h)), tt.width).Bounds()
+		if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+			t.Errorf("scale(%dx%d, %d) = %dx%d, want %dx%d", tt.w, tt.h, tt.width, got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestScaleAverages(t *testing.T) {
+	// Columns alternate black and white, so halving the width makes grey.
+	src := image.NewRGBA(image.Rect(10, 10, 14, 12))
+	for y := 10; y < 12; y++ {
+		for x := 10; x < 14; x++ {
+			c := color.RGBA{A: 255}
+			if x%2 == 1 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+
+	dst := scale(src, 2)
+	if b := dst.Bounds(); b.Dx() != 2 || b.Dy() != 1 {
+		t.Fatalf("scaled to %v, want 2x1", b)
+	}
+	for x := 0; x < 2; x++ {
+		if c := dst.RGBAAt(x, 0); c.R != 127 || c.G != 127 || c.B != 127 || c.A != 255 {
+			t.Errorf("pixel %d = %v, want grey", x, c)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	item := &Item{Kind: KindScreenshot, GameVersion: "1.0", CapturedAt: day}
+	before, after := day.Add(-time.Hour), day.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"kind", Filter{Kind: KindScreenshot}, true},
+		{"other kind", Filter{Kind: KindRecording}, false},
+		{"version", Filter{GameVersion: "1.0"}, true},
+		{"other version", Filter{GameVersion: "2.0"}, false},
+		{"in range", Filter{From: &before, To: &after}, true},
+		{"at bounds", Filter{From: &day, To: &day}, true},
+		{"too early", Filter{From: &after}, false},
+		{"too late", Filter{To: &before}, false},
+		{"all but kind", Filter{Kind: KindRecording, GameVersion: "1.0", From: &before}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.matches(item); got != tt.want {
+			t.Errorf("%s: matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package modpack
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	"hytale-launcher/internal/download"
+	"hytale-launcher/internal/extract"
+	"hytale-launcher/internal/ioutil"
+)
+
+// Sources locates the files an exported pack is built from.
+type Sources struct {
+	// ModFiles maps mod hashes to their files.
+	ModFiles map[string]string
+
+	// EmbedMods embeds every mod in the archive. Mods without a URL are
+	// always embedded.
+	EmbedMods bool
+
+	// ConfigRoot is the directory config paths are relative to.
+	ConfigRoot string
+}
+
+// Export writes a modpack archive to dest. The hashes of config entries are
+// computed from the files under src.ConfigRoot.
+func Export(dest string, m *Manifest, src Sources) error {
+	m.FormatVersion = FormatVersion
+
+	for i := range m.Configs {
+		hash, err := hashFile(filepath.Join(src.ConfigRoot, filepath.FromSlash(m.Configs[i].Path)))
+		if err != nil {
+			return fmt.Errorf("failed to read config %s: %w", m.Configs[i].Path, err)
+		}
+		m.Configs[i].SHA256 = hash
+	}
+	for i := range m.Mods {
+		m.Mods[i].Embedded = src.EmbedMods || m.Mods[i].URL == ""
+	}
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	tmp := dest + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create modpack: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	if err := writeArchive(f, m, src); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, dest)
+}
+
+// writeArchive writes the manifest and embedded files as a zip archive.
+func writeArchive(w io.Writer, m *Manifest, src Sources) error {
+	zw := zip.NewWriter(w)
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal modpack manifest: %w", err)
+	}
+	mw, err := zw.Create(manifestName)
+	if err != nil {
+		return err
+	}
+	if _, err := mw.Write(data); err != nil {
+		return err
+	}
+
+	for _, mod := range m.Mods {
+		if !mod.Embedded {
+			continue
+		}
+		file, ok := src.ModFiles[mod.SHA256]
+		if !ok {
+			return fmt.Errorf("missing file for mod %s", mod.Name)
+		}
+		if err := addFile(zw, mod.archivePath(), file); err != nil {
+			return fmt.Errorf("failed to add mod %s: %w", mod.Name, err)
+		}
+	}
+
+	for _, cfg := range m.Configs {
+		file := filepath.Join(src.ConfigRoot, filepath.FromSlash(cfg.Path))
+		if err := addFile(zw, path.Join(configDir, cfg.Path), file); err != nil {
+			return fmt.Errorf("failed to add config %s: %w", cfg.Path, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// addFile copies a file into the zip archive under name.
+func addFile(zw *zip.Writer, name, filePath string) error {
+	in, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	return err
+}
+
+// hashFile returns the hex SHA-256 of a file.
+func hashFile(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// LookupFunc returns the path of a locally available file with the given
+// hash, such as a mod already in the library.
+type LookupFunc func(hash string) (string, bool)
+
+// ProgressFunc reports import progress for a stage.
+type ProgressFunc func(stage string, current, total int)
+
+// Pack is an extracted and verified modpack.
+type Pack struct {
+	// Manifest is the pack's manifest.
+	Manifest *Manifest
+
+	// ModFiles maps each mod hash to a verified file named after the mod.
+	ModFiles map[string]string
+
+	// ConfigFiles maps each config path to its verified file.
+	ConfigFiles map[string]string
+
+	dir string
+}
+
+// Close removes the pack's extracted files.
+func (p *Pack) Close() error {
+	return os.RemoveAll(p.dir)
+}
+
+// Open extracts a modpack archive below workDir and verifies every file
+// against the manifest. Mods missing from the archive are taken from lookup
+// or downloaded from their URL.
+func Open(ctx context.Context, archivePath, workDir string, lookup LookupFunc, progress ProgressFunc) (*Pack, error) {
+	if progress == nil {
+		progress = func(string, int, int) {}
+	}
+
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(workDir, "modpack-*")
+	if err != nil {
+		return nil, err
+	}
+
+	pack := &Pack{
+		ModFiles:    make(map[string]string),
+		ConfigFiles: make(map[string]string),
+		dir:         dir,
+	}
+	if err := pack.load(ctx, archivePath, lookup, progress); err != nil {
+		pack.Close()
+		return nil, err
+	}
+	return pack, nil
+}
+
+// load extracts the archive and resolves every file in the manifest.
+func (p *Pack) load(ctx context.Context, archivePath string, lookup LookupFunc, progress ProgressFunc) error {
+	extracted := filepath.Join(p.dir, "archive")
+	err := extract.Archive(archivePath, extracted, func(current, total int) {
+		progress("extract", current, total)
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to extract modpack: %w", err)
+	}
+
+	m, err := ReadManifest(filepath.Join(extracted, manifestName))
+	if err != nil {
+		return err
+	}
+	p.Manifest = m
+
+	for i, mod := range m.Mods {
+		progress("mods", i, len(m.Mods))
+
+		file, err := p.resolveMod(ctx, extracted, &mod, lookup)
+		if err != nil {
+			return fmt.Errorf("mod %s: %w", mod.Name, err)
+		}
+		p.ModFiles[mod.SHA256] = file
+	}
+	progress("mods", len(m.Mods), len(m.Mods))
+
+	for _, cfg := range m.Configs {
+		file := filepath.Join(extracted, configDir, filepath.FromSlash(cfg.Path))
+		if err := ioutil.VerifySHA256(file, cfg.SHA256); err != nil {
+			return fmt.Errorf("config %s: %w", cfg.Path, err)
+		}
+		p.ConfigFiles[cfg.Path] = file
+	}
+
+	return nil
+}
+
+// resolveMod finds a verified copy of a mod: embedded in the archive, already
+// available locally, or downloaded.
+func (p *Pack) resolveMod(ctx context.Context, extracted string, mod *ModEntry, lookup LookupFunc) (string, error) {
+	var embedErr error
+	if mod.Embedded {
+		file := filepath.Join(extracted, filepath.FromSlash(mod.archivePath()))
+		embedErr = verifyMod(file, mod)
+		if embedErr == nil {
+			return p.stage(file, mod)
+		}
+		slog.Warn("embedded mod failed verification", "mod", mod.Name, "error", embedErr)
+	}
+
+	if lookup != nil {
+		if file, ok := lookup(mod.SHA256); ok {
+			if err := verifyMod(file, mod); err == nil {
+				return file, nil
+			}
+		}
+	}
+
+	if mod.URL == "" {
+		if embedErr != nil {
+			return "", fmt.Errorf("file is missing or corrupted and has no download URL: %w", embedErr)
+		}
+		return "", fmt.Errorf("file is missing or corrupted and has no download URL")
+	}
+
+	slog.Info("downloading modpack mod", "mod", mod.Name, "url", mod.URL)
+	file, err := download.DownloadTemp(ctx, http.DefaultClient, p.dir, mod.URL, mod.SHA256, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := verifyMod(file, mod); err != nil {
+		os.Remove(file)
+		return "", err
+	}
+	return p.stage(file, mod)
+}
+
+// verifyMod checks that file has the size and SHA-256 the manifest pins for
+// mod.
+func verifyMod(file string, mod *ModEntry) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	if info.Size() != mod.Size {
+		return fmt.Errorf("size mismatch: expected %d bytes, got %d", mod.Size, info.Size())
+	}
+	return ioutil.VerifySHA256(file, mod.SHA256)
+}
+
+// stage moves a verified mod file to a path named after the mod, so that it
+// keeps its file name when imported into the library.
+func (p *Pack) stage(file string, mod *ModEntry) (string, error) {
+	dst := filepath.Join(p.dir, "staged", mod.SHA256, mod.FileName)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(file, dst); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
@@ -0,0 +1,210 @@
+// Package modpack defines the modpack manifest and reads and writes modpack
+// archives. A modpack pins a game build, Java runtime, mods, config files and
+// JVM options so that a setup can be reproduced exactly on another machine.
+package modpack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/jvmopts"
+)
+
+// FormatVersion is the current modpack manifest format version.
+const FormatVersion = 1
+
+// Paths inside a modpack archive.
+const (
+	manifestName = "modpack.json"
+	modsDir      = "mods"
+	configDir    = "config"
+)
+
+// Manifest describes a modpack.
+type Manifest struct {
+	// FormatVersion is the manifest format the pack was written with.
+	FormatVersion int `json:"format_version"`
+
+	// Name is the display name of the pack.
+	Name string `json:"name"`
+
+	// Description is an optional longer description.
+	Description string `json:"description,omitempty"`
+
+	// Target is the launch target the pack is for ("client" or "server").
+	Target string `json:"target"`
+
+	// CreatedAt is when the pack was exported.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Game pins the game build.
+	Game GameRef `json:"game"`
+
+	// JRE pins the Java runtime.
+	JRE RuntimeRef `json:"jre"`
+
+	// Mods are the mods in load order.
+	Mods []ModEntry `json:"mods"`
+
+	// Configs are config files overriding the target's defaults.
+	Configs []ConfigEntry `json:"configs,omitempty"`
+
+	// JVM are the JVM options the pack runs with.
+	JVM jvmopts.Options `json:"jvm"`
+}
+
+// GameRef identifies a game build.
+type GameRef struct {
+	// Version is the game version string.
+	Version string `json:"version"`
+
+	// Build is the build number, if known.
+	Build int `json:"build,omitempty"`
+}
+
+// RuntimeRef identifies a Java runtime.
+type RuntimeRef struct {
+	// Version is the full Java version (e.g., "25.0.1").
+	Version string `json:"version"`
+
+	// Major is the Java feature release the pack requires at least.
+	Major int `json:"major"`
+
+	// Vendor is the runtime vendor, for information only.
+	Vendor string `json:"vendor,omitempty"`
+}
+
+// ModEntry pins a mod by hash.
+type ModEntry struct {
+	// ID is the mod's ID.
+	ID string `json:"id"`
+
+	// Name is the display name of the mod.
+	Name string `json:"name"`
+
+	// Version is the mod version.
+	Version string `json:"version,omitempty"`
+
+	// FileName is the mod's file name.
+	FileName string `json:"file_name"`
+
+	// SHA256 is the hex SHA-256 the mod file must match.
+	SHA256 string `json:"sha256"`
+
+	// Size is the mod file size in bytes.
+	Size int64 `json:"size"`
+
+	// URL is where the mod can be downloaded if it is not embedded.
+	URL string `json:"url,omitempty"`
+
+	// Embedded is true if the archive contains a copy of the mod.
+	Embedded bool `json:"embedded"`
+}
+
+// ConfigEntry is a config file shipped with the pack.
+type ConfigEntry struct {
+	// Path is the file path relative to the target's data directory,
+	// using forward slashes.
+	Path string `json:"path"`
+
+	// SHA256 is the hex SHA-256 of the file.
+	SHA256 string `json:"sha256"`
+}
+
+// archivePath returns the path of an embedded mod inside the archive.
+func (e *ModEntry) archivePath() string {
+	return path.Join(modsDir, e.SHA256+path.Ext(e.FileName))
+}
+
+// Validate checks that the manifest can be imported.
+func (m *Manifest) Validate() error {
+	if m.FormatVersion > FormatVersion {
+		return fmt.Errorf("modpack format %d is newer than supported format %d; update the launcher", m.FormatVersion, FormatVersion)
+	}
+	if strings.TrimSpace(m.Name) == "" {
+		return errors.New("modpack has no name")
+	}
+	if m.Target != jvmopts.TargetClient && m.Target != jvmopts.TargetServer {
+		return fmt.Errorf("modpack has unknown target %q", m.Target)
+	}
+
+	for _, mod := range m.Mods {
+		if len(mod.SHA256) != 64 {
+			return fmt.Errorf("mod %s has an invalid hash", mod.Name)
+		}
+		if mod.Size <= 0 {
+			return fmt.Errorf("mod %s has an invalid size", mod.Name)
+		}
+		if mod.FileName == "" || mod.FileName != path.Base(mod.FileName) {
+			return fmt.Errorf("mod %s has an invalid file name", mod.Name)
+		}
+		if !mod.Embedded && mod.URL == "" {
+			return fmt.Errorf("mod %s is neither embedded nor downloadable", mod.Name)
+		}
+	}
+
+	for _, cfg := range m.Configs {
+		if !validConfigPath(cfg.Path) {
+			return fmt.Errorf("invalid config path %q", cfg.Path)
+		}
+		if len(cfg.SHA256) != 64 {
+			return fmt.Errorf("config %s has an invalid hash", cfg.Path)
+		}
+	}
+
+	return m.JVM.Validate()
+}
+
+// validConfigPath reports whether p is a relative path that stays inside the
+// target's data directory.
+func validConfigPath(p string) bool {
+	if p == "" || path.IsAbs(p) || strings.Contains(p, `\`) || strings.Contains(p, ":") {
+		return false
+	}
+	clean := path.Clean(p)
+	return clean == p && clean != "." && clean != ".." && !strings.HasPrefix(clean, "../")
+}
+
+// ReadManifest reads and validates a manifest file.
+func ReadManifest(filePath string) (*Manifest, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errors.New("archive is not a modpack: missing " + manifestName)
+		}
+		return nil, fmt.Errorf("failed to read modpack manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse modpack manifest: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// WriteManifest writes a manifest file, such as the record of an imported pack.
+func WriteManifest(filePath string, m *Manifest) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal modpack manifest: %w", err)
+	}
+
+	if err := ioutil.WriteFileAtomic(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write modpack manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package modpack
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"hytale-launcher/internal/jvmopts"
+)
+
+// writeFile writes content to name under dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// testPack returns a manifest with one mod and one config file, and the
+// sources to export it from.
+func testPack(t *testing.T) (*Manifest, Sources) {
+	t.Helper()
+
+	dir := t.TempDir()
+	mod := writeFile(t, dir, "mod.jar", "mod content")
+	hash, err := hashFile(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configRoot := filepath.Join(dir, "UserData")
+	writeFile(t, configRoot, "Settings/game.json", `{"fov": 90}`)
+
+	m := &Manifest{
+		Name:   "Team pack",
+		Target: jvmopts.TargetClient,
+		Mods: []ModEntry{{
+			ID:       "Team:Mod",
+			Name:     "Mod",
+			FileName: "mod.jar",
+			SHA256:   hash,
+			Size:     int64(len("mod content")),
+		}},
+		Configs: []ConfigEntry{{Path: "Settings/game.json"}},
+		JVM:     jvmopts.Options{MaxHeapMB: 4096},
+	}
+	src := Sources{
+		ModFiles:   map[string]string{hash: mod},
+		ConfigRoot: configRoot,
+	}
+	return m, src
+}
+
+// rewriteManifest replaces the manifest of the archive at path after edit
+// has changed it.
+func rewriteManifest(t *testing.T, path string, edit func(m *Manifest)) {
+	t.Helper()
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]byte)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = data
+	}
+	r.Close()
+
+	var m Manifest
+	if err := json.Unmarshal(files[manifestName], &m); err != nil {
+		t.Fatal(err)
+	}
+	edit(&m)
+	if files[manifestName], err = json.Marshal(&m); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExportOpenRoundTrip(t *testing.T) {
+	m, src := testPack(t)
+	path := filepath.Join(t.TempDir(), "pack.zip")
+	if err := Export(path, m, src); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if !m.Mods[0].Embedded {
+		t.Fatal("mod without a URL was not embedded")
+	}
+
+	pack, err := Open(context.Background(), path, t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer pack.Close()
+
+	if pack.Manifest.Name != "Team pack" || pack.Manifest.JVM.MaxHeapMB != 4096 {
+		t.Errorf("manifest = %+v", pack.Manifest)
+	}
+
+	modFile := pack.ModFiles[m.Mods[0].SHA256]
+	if filepath.Base(modFile) != "mod.jar" {
+		t.Errorf("mod staged as %s, want its file name", modFile)
+	}
+	if data, _ := os.ReadFile(modFile); string(data) != "mod content" {
+		t.Errorf("mod content = %q", data)
+	}
+	if data, _ := os.ReadFile(pack.ConfigFiles["Settings/game.json"]); string(data) != `{"fov": 90}` {
+		t.Errorf("config content = %q", data)
+	}
+}
+
+func TestOpenRejectsMismatchedMods(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(m *Manifest)
+		want string
+	}{
+		{
+			name: "size",
+			edit: func(m *Manifest) { m.Mods[0].Size++ },
+			want: "size mismatch",
+		},
+		{
+			name: "hash",
+			edit: func(m *Manifest) { m.Mods[0].SHA256 = strings.Repeat("0", 64) },
+			want: "missing or corrupted",
+		},
+		{
+			name: "zero size",
+			edit: func(m *Manifest) { m.Mods[0].Size = 0 },
+			want: "invalid size",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, src := testPack(t)
+			path := filepath.Join(t.TempDir(), "pack.zip")
+			if err := Export(path, m, src); err != nil {
+				t.Fatal(err)
+			}
+			rewriteManifest(t, path, tt.edit)
+
+			pack, err := Open(context.Background(), path, t.TempDir(), nil, nil)
+			if err == nil {
+				pack.Close()
+				t.Fatal("Open accepted a mismatched mod")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenUsesLookupOnlyForMatchingFiles(t *testing.T) {
+	m, src := testPack(t)
+	hash := m.Mods[0].SHA256
+	m.Mods[0].URL = "https://example.invalid/mod.jar"
+	path := filepath.Join(t.TempDir(), "pack.zip")
+	if err := Export(path, m, src); err != nil {
+		t.Fatal(err)
+	}
+
+	// A library copy of the mod is used instead of downloading it.
+	pack, err := Open(context.Background(), path, t.TempDir(), func(h string) (string, bool) {
+		return src.ModFiles[h], h == hash
+	}, nil)
+	if err != nil {
+		t.Fatalf("Open with the mod in the library: %v", err)
+	}
+	if pack.ModFiles[hash] != src.ModFiles[hash] {
+		t.Errorf("mod file = %s, want the library copy", pack.ModFiles[hash])
+	}
+	pack.Close()
+
+	// A local file of the wrong size is not trusted.
+	other := writeFile(t, t.TempDir(), "other.jar", "different")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if pack, err := Open(ctx, path, t.TempDir(), func(string) (string, bool) { return other, true }, nil); err == nil {
+		pack.Close()
+		t.Fatal("Open accepted a library file that does not match")
+	}
+}
+
+func TestValidateConfigPaths(t *testing.T) {
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"Settings/game.json", true},
+		{"game.json", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../escape.json", false},
+		{"Settings/../../escape.json", false},
+		{`Settings\game.json`, false},
+		{"C:/game.json", false},
+		{"./game.json", false},
+	}
+	for _, tt := range tests {
+		if got := validConfigPath(tt.path); got != tt.ok {
+			t.Errorf("validConfigPath(%q) = %v, want %v", tt.path, got, tt.ok)
+		}
+	}
+}
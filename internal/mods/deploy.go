@@ -0,0 +1,385 @@
+package mods
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"hytale-launcher/internal/ioutil"
+)
+
+// recordName is the file in a mod directory listing the files the launcher
+// placed there. Files not in the record belong to the user and are left alone.
+const recordName = ".launcher-mods.json"
+
+// pendingName is the record of a deployment that is being switched to.
+const pendingName = ".launcher-mods.pending.json"
+
+// Conflict kinds.
+const (
+	// ConflictDuplicateID means two enabled mods share an ID. The game would
+	// load only one of them, so deployment is refused.
+	ConflictDuplicateID = "duplicate_id"
+
+	// ConflictOverlappingFiles means two enabled mods provide the same files.
+	// The mod later in the load order wins.
+	ConflictOverlappingFiles = "overlapping_files"
+)
+
+// Conflict describes a problem between enabled mods.
+type Conflict struct {
+	// Kind is ConflictDuplicateID or ConflictOverlappingFiles.
+	Kind string `json:"kind"`
+
+	// Mods are the hashes of the mods involved, in load order.
+	Mods []string `json:"mods"`
+
+	// ID is the shared mod ID for duplicate ID conflicts.
+	ID string `json:"id,omitempty"`
+
+	// Files are the overlapping files, if any.
+	Files []string `json:"files,omitempty"`
+
+	// Blocking is true if the conflict prevents deployment.
+	Blocking bool `json:"blocking"`
+}
+
+// ConflictError is returned by Deploy when blocking conflicts exist.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+// Error returns the error message for ConflictError.
+func (e *ConflictError) Error() string {
+	var ids []string
+	for _, c := range e.Conflicts {
+		if c.Blocking {
+			ids = append(ids, c.ID)
+		}
+	}
+	return fmt.Sprintf("conflicting mods share the same ID: %s", strings.Join(ids, ", "))
+}
+
+// DeployResult reports what a deployment changed.
+type DeployResult struct {
+	// Target is the launch target that was deployed.
+	Target string `json:"target"`
+
+	// Set is the key of the deployed mod set.
+	Set string `json:"set"`
+
+	// Deployed are the file names placed in the mod directory.
+	Deployed []string `json:"deployed"`
+
+	// Removed are the file names of previously deployed mods that were removed.
+	Removed []string `json:"removed"`
+
+	// Conflicts are the non-blocking conflicts between enabled mods.
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+}
+
+// record is the on-disk list of deployed files, by file name and hash.
+type record struct {
+	Files map[string]string `json:"files"`
+}
+
+// Conflicts checks the enabled mods of a set for duplicate IDs and
+// overlapping files.
+func (m *Manager) Conflicts(key string) ([]Conflict, error) {
+	m.mu.RLock()
+	enabled := m.enabledLocked(key)
+	paths := make([]string, len(enabled))
+	for i := range enabled {
+		paths[i] = m.filePath(&enabled[i])
+	}
+	m.mu.RUnlock()
+
+	var conflicts []Conflict
+
+	byID := make(map[string][]string)
+	var ids []string
+	for _, mod := range enabled {
+		if _, seen := byID[mod.ID]; !seen {
+			ids = append(ids, mod.ID)
+		}
+		byID[mod.ID] = append(byID[mod.ID], mod.Hash)
+	}
+	for _, id := range ids {
+		if len(byID[id]) > 1 {
+			conflicts = append(conflicts, Conflict{
+				Kind:     ConflictDuplicateID,
+				Mods:     byID[id],
+				ID:       id,
+				Blocking: true,
+			})
+		}
+	}
+
+	owners := make(map[string]int)
+	overlaps := make(map[[2]int][]string)
+	var pairs [][2]int
+	for i, p := range paths {
+		files, err := contentFiles(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mod %s: %w", enabled[i].Name, err)
+		}
+		for _, f := range files {
+			owner, taken := owners[f]
+			if !taken {
+				owners[f] = i
+				continue
+			}
+			pair := [2]int{owner, i}
+			if _, ok := overlaps[pair]; !ok {
+				pairs = append(pairs, pair)
+			}
+			overlaps[pair] = append(overlaps[pair], f)
+		}
+	}
+	for _, pair := range pairs {
+		conflicts = append(conflicts, Conflict{
+			Kind:  ConflictOverlappingFiles,
+			Mods:  []string{enabled[pair[0]].Hash, enabled[pair[1]].Hash},
+			Files: overlaps[pair],
+		})
+	}
+
+	return conflicts, nil
+}
+
+// deployedName returns the file name of a mod at a position in the load
+// order. The numeric prefix keeps directory order equal to load order.
+func deployedName(index int, mod *Mod) string {
+	return fmt.Sprintf("%03d_%s", index+1, mod.FileName)
+}
+
+// backupDir returns where the previous deployment is kept while switching.
+func backupDir(dir string) string {
+	return filepath.Join(filepath.Dir(dir), "."+filepath.Base(dir)+"-previous")
+}
+
+// interrupt is called between the steps of a deployment. Tests replace it to
+// stop a deployment part way, as a crash would.
+var interrupt = func(step string) {}
+
+// Deploy makes dir contain exactly the enabled mods of a set. Previously
+// deployed files are moved aside first and restored if anything fails, so
+// the directory is never left with a partial mod set. Files the launcher did
+// not place are left untouched.
+func (m *Manager) Deploy(key, dir string) (*DeployResult, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	conflicts, err := m.Conflicts(key)
+	if err != nil {
+		return nil, err
+	}
+	if slices.ContainsFunc(conflicts, func(c Conflict) bool { return c.Blocking }) {
+		return nil, &ConflictError{Conflicts: conflicts}
+	}
+
+	m.mu.RLock()
+	enabled := m.enabledLocked(key)
+	sources := make(map[string]string, len(enabled))
+	wanted := record{Files: make(map[string]string, len(enabled))}
+	var names []string
+	for i := range enabled {
+		name := deployedName(i, &enabled[i])
+		sources[name] = m.filePath(&enabled[i])
+		wanted.Files[name] = enabled[i].Hash
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create mod directory: %w", err)
+	}
+
+	// Finish rolling back a deployment that was interrupted.
+	if err := Recover(dir); err != nil {
+		return nil, err
+	}
+
+	previous, err := readRecord(filepath.Join(dir, recordName))
+	if err != nil {
+		return nil, err
+	}
+
+	// Never overwrite files the user placed by hand.
+	for _, name := range names {
+		if _, managed := previous.Files[name]; managed {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return nil, fmt.Errorf("mod directory already contains %s, which was not installed by the launcher", name)
+		}
+	}
+
+	backup := backupDir(dir)
+	if err := os.MkdirAll(backup, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create mod backup: %w", err)
+	}
+
+	result := &DeployResult{Target: TargetOf(key), Set: key, Deployed: names}
+	for _, c := range conflicts {
+		if !c.Blocking {
+			result.Conflicts = append(result.Conflicts, c)
+		}
+	}
+
+	// The pending record marks the switch as in progress; while it exists
+	// Recover undoes the switch.
+	if err := writeRecord(filepath.Join(dir, pendingName), wanted); err != nil {
+		os.Remove(backup)
+		return nil, err
+	}
+	interrupt("pending")
+
+	// Move the previous deployment aside, starting with its record.
+	moved := append([]string{recordName}, slices.Collect(maps.Keys(previous.Files))...)
+	for _, name := range moved {
+		err := os.Rename(filepath.Join(dir, name), filepath.Join(backup, name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, rollback(dir, fmt.Errorf("failed to move %s aside: %w", name, err))
+		}
+		interrupt("move " + name)
+	}
+
+	for _, name := range names {
+		if err := linkOrCopy(sources[name], filepath.Join(dir, name)); err != nil {
+			return nil, rollback(dir, fmt.Errorf("failed to deploy %s: %w", name, err))
+		}
+		interrupt("place " + name)
+	}
+
+	// Commit the switch.
+	if err := os.Rename(filepath.Join(dir, pendingName), filepath.Join(dir, recordName)); err != nil {
+		return nil, rollback(dir, fmt.Errorf("failed to write mod deployment record: %w", err))
+	}
+	interrupt("commit")
+
+	if err := os.RemoveAll(backup); err != nil {
+		slog.Warn("failed to remove previous mod deployment", "path", backup, "error", err)
+	}
+
+	for name := range previous.Files {
+		if _, kept := wanted.Files[name]; !kept {
+			result.Removed = append(result.Removed, name)
+		}
+	}
+	slices.Sort(result.Removed)
+	return result, nil
+}
+
+// rollback undoes an in-progress switch and returns cause, annotated if the
+// rollback itself failed.
+func rollback(dir string, cause error) error {
+	if err := Recover(dir); err != nil {
+		return fmt.Errorf("%w (rollback failed: %v)", cause, err)
+	}
+	return cause
+}
+
+// Recover finishes an interrupted switch of dir. If the switch had not been
+// committed, the newly placed files are removed and the previous deployment
+// is restored; otherwise the leftover backup is deleted.
+func Recover(dir string) error {
+	backup := backupDir(dir)
+	entries, err := os.ReadDir(backup)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read mod backup: %w", err)
+	}
+
+	pendingPath := filepath.Join(dir, pendingName)
+	pending, err := readRecord(pendingPath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(pendingPath); errors.Is(err, os.ErrNotExist) {
+		return os.RemoveAll(backup)
+	}
+
+	slog.Info("restoring previous mod deployment", "dir", dir)
+
+	// The previous record is in the backup unless the switch stopped before
+	// moving anything.
+	previous, err := readRecord(filepath.Join(backup, recordName))
+	if err != nil {
+		return err
+	}
+	if current, err := readRecord(filepath.Join(dir, recordName)); err == nil {
+		maps.Copy(previous.Files, current.Files)
+	}
+
+	// Remove newly placed files, keeping previous ones not yet moved aside.
+	for name := range pending.Files {
+		_, wasDeployed := previous.Files[name]
+		if wasDeployed && !hasEntry(entries, name) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", name, err)
+		}
+	}
+
+	for _, e := range entries {
+		if err := os.Rename(filepath.Join(backup, e.Name()), filepath.Join(dir, e.Name())); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", e.Name(), err)
+		}
+	}
+
+	if err := ioutil.RemoveWithBackup(pendingPath); err != nil {
+		return fmt.Errorf("failed to remove pending mod record: %w", err)
+	}
+	return os.Remove(backup)
+}
+
+// hasEntry reports whether entries contains a file with the given name.
+func hasEntry(entries []os.DirEntry, name string) bool {
+	return slices.ContainsFunc(entries, func(e os.DirEntry) bool {
+		return e.Name() == name
+	})
+}
+
+// readRecord reads a deployment record file. A missing record is empty.
+func readRecord(path string) (record, error) {
+	rec := record{Files: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return rec, nil
+	}
+	if err != nil {
+		return rec, fmt.Errorf("failed to read mod deployment record: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, fmt.Errorf("failed to parse mod deployment record: %w", err)
+	}
+	if rec.Files == nil {
+		rec.Files = make(map[string]string)
+	}
+	return rec, nil
+}
+
+// writeRecord writes a deployment record file.
+func writeRecord(path string, rec record) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal mod deployment record: %w", err)
+	}
+	if err := ioutil.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write mod deployment record: %w", err)
+	}
+	return nil
+}
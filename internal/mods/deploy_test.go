@@ -0,0 +1,232 @@
+package mods
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// errInterrupted stops a deployment part way.
+var errInterrupted = errors.New("interrupted")
+
+// deployFixture is a mod directory with set A deployed and set B enabled.
+type deployFixture struct {
+	m   *Manager
+	dir string
+
+	// a and b are the file names and hashes of sets A and B when deployed.
+	a, b map[string]string
+
+	// missing is a mod in set B only.
+	missing *Mod
+}
+
+// newDeployFixture deploys A = [one, two] into a mod directory holding a file
+// of the user's, then enables B = [one', two, three], where one' has the
+// same file name as one but different content.
+func newDeployFixture(t *testing.T) *deployFixture {
+	t.Helper()
+
+	m := NewManager(t.TempDir())
+	one := importMod(t, m, "one.jar", "One", map[string]string{"one.txt": "1"})
+	two := importMod(t, m, "two.jar", "Two", map[string]string{"two.txt": "2"})
+	oneNew := importMod(t, m, "one.jar", "OneNew", map[string]string{"one.txt": "1'"})
+	three := importMod(t, m, "three.jar", "Three", map[string]string{"three.txt": "3"})
+
+	dir := filepath.Join(t.TempDir(), "Mods")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "user.jar"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetMods(TargetClient, []string{one.Hash, two.Hash}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Deploy(TargetClient, dir); err != nil {
+		t.Fatalf("Deploy(A): %v", err)
+	}
+	if err := m.SetMods(TargetClient, []string{oneNew.Hash, two.Hash, three.Hash}); err != nil {
+		t.Fatal(err)
+	}
+
+	return &deployFixture{
+		m:   m,
+		dir: dir,
+		a: map[string]string{
+			"001_one.jar": one.Hash,
+			"002_two.jar": two.Hash,
+		},
+		b: map[string]string{
+			"001_one.jar":   oneNew.Hash,
+			"002_two.jar":   two.Hash,
+			"003_three.jar": three.Hash,
+		},
+		missing: three,
+	}
+}
+
+// check fails unless the mod directory holds exactly the deployed files
+// want, the user's file and a record of want, with nothing left over from
+// the switch.
+func (f *deployFixture) check(t *testing.T, want map[string]string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, e := range entries {
+		switch e.Name() {
+		case recordName:
+			continue
+		case "user.jar":
+			data, err := os.ReadFile(filepath.Join(f.dir, e.Name()))
+			if err != nil || string(data) != "mine" {
+				t.Errorf("user file changed: %q, %v", data, err)
+			}
+			continue
+		}
+		hash, _, err := hashFile(filepath.Join(f.dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[e.Name()] = hash
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("mod directory = %v, want %v", got, want)
+	}
+
+	rec, err := readRecord(filepath.Join(f.dir, recordName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(rec.Files, want) {
+		t.Errorf("record = %v, want %v", rec.Files, want)
+	}
+
+	if _, err := os.Stat(backupDir(f.dir)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("backup directory left behind: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(f.dir, pendingName)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("pending record left behind: %v", err)
+	}
+}
+
+// deployUntil deploys the enabled set, stopping at the step with the given
+// index as a crash would. It reports the step it stopped at, or "" if the
+// deployment completed.
+func (f *deployFixture) deployUntil(t *testing.T, index int) (stopped string) {
+	t.Helper()
+
+	steps := 0
+	interrupt = func(step string) {
+		if steps == index {
+			stopped = step
+			panic(errInterrupted)
+		}
+		steps++
+	}
+	defer func() { interrupt = func(string) {} }()
+
+	defer func() {
+		if r := recover(); r != nil && r != errInterrupted {
+			panic(r)
+		}
+	}()
+
+	if _, err := f.m.Deploy(TargetClient, f.dir); err != nil {
+		t.Fatalf("Deploy(B): %v", err)
+	}
+	return ""
+}
+
+func TestDeploy(t *testing.T) {
+	f := newDeployFixture(t)
+
+	result, err := f.m.Deploy(TargetClient, f.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.check(t, f.b)
+
+	if result.Target != TargetClient || result.Set != TargetClient {
+		t.Errorf("result target, set = %q, %q", result.Target, result.Set)
+	}
+	if len(result.Removed) != 0 {
+		t.Errorf("removed = %v, want none: every name is still deployed", result.Removed)
+	}
+}
+
+func TestRecoverAfterInterruptedDeploy(t *testing.T) {
+	for index := 0; ; index++ {
+		f := newDeployFixture(t)
+
+		step := f.deployUntil(t, index)
+		if step == "" {
+			break
+		}
+
+		if err := Recover(f.dir); err != nil {
+			t.Fatalf("Recover after %s: %v", step, err)
+		}
+
+		// Once committed, the new set stays; before that, the previous
+		// set is restored.
+		want := f.a
+		if step == "commit" {
+			want = f.b
+		}
+		t.Run(step, func(t *testing.T) {
+			f.check(t, want)
+		})
+
+		// A later deployment completes normally.
+		if _, err := f.m.Deploy(TargetClient, f.dir); err != nil {
+			t.Fatalf("Deploy after recovering from %s: %v", step, err)
+		}
+		f.check(t, f.b)
+	}
+}
+
+func TestDeployRollsBackOnFailure(t *testing.T) {
+	f := newDeployFixture(t)
+
+	// Placing the last mod of B fails.
+	libPath, _ := f.m.Path(f.missing.Hash)
+	if err := os.Remove(libPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.m.Deploy(TargetClient, f.dir); err == nil {
+		t.Fatal("Deploy succeeded without a library file")
+	}
+	f.check(t, f.a)
+}
+
+func TestDeployProfileSet(t *testing.T) {
+	f := newDeployFixture(t)
+
+	// The profile has no set of its own, so it deploys the default set.
+	alex := Key(TargetClient, "alex")
+	result, err := f.m.Deploy(alex, f.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Target != TargetClient || result.Set != alex {
+		t.Errorf("result target, set = %q, %q", result.Target, result.Set)
+	}
+	f.check(t, f.b)
+
+	if err := f.m.SetMods(alex, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.m.Deploy(alex, f.dir); err != nil {
+		t.Fatal(err)
+	}
+	f.check(t, map[string]string{})
+}
@@ -0,0 +1,409 @@
+package mods
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"hytale-launcher/internal/ioutil"
+)
+
+// config is the persisted library index and mod sets.
+type config struct {
+	// Mods are the mods in the library.
+	Mods []Mod `json:"mods"`
+
+	// Sets maps a set key to the ordered hashes of its enabled mods.
+	Sets map[string][]string `json:"sets,omitempty"`
+}
+
+// Manager manages the mod library and the mod sets of each launch target
+// and launch profile.
+type Manager struct {
+	dir string
+
+	mu  sync.RWMutex
+	cfg config
+}
+
+// NewManager creates a Manager storing its library and index in dir.
+func NewManager(dir string) *Manager {
+	return &Manager{
+		dir: dir,
+		cfg: config{Sets: make(map[string][]string)},
+	}
+}
+
+// indexPath returns the path of the library index file.
+func (m *Manager) indexPath() string {
+	return filepath.Join(m.dir, "mods.json")
+}
+
+// filePath returns the library path of a mod file.
+func (m *Manager) filePath(mod *Mod) string {
+	return filepath.Join(m.dir, "library", mod.Hash+strings.ToLower(filepath.Ext(mod.FileName)))
+}
+
+// Load loads the library index from disk.
+func (m *Manager) Load() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var cfg config
+	err := ioutil.LoadWithBackup(m.indexPath(), func(data []byte) error {
+		var loaded config
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("failed to unmarshal mod library: %w", err)
+		}
+		cfg = loaded
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read mod library: %w", err)
+	}
+	if cfg.Sets == nil {
+		cfg.Sets = make(map[string][]string)
+	}
+
+	m.cfg = cfg
+	return nil
+}
+
+// saveLocked saves the library index without acquiring the lock.
+// Caller must hold m.mu.
+func (m *Manager) saveLocked() error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m.cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal mod library: %w", err)
+	}
+
+	if err := ioutil.WriteFileAtomic(m.indexPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write mod library: %w", err)
+	}
+	return nil
+}
+
+// List returns all mods in the library sorted by name.
+func (m *Manager) List() []Mod {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := slices.Clone(m.cfg.Mods)
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
+	})
+	return result
+}
+
+// findLocked returns the library mod with the given hash.
+// Caller must hold m.mu.
+func (m *Manager) findLocked(hash string) (*Mod, error) {
+	for i := range m.cfg.Mods {
+		if m.cfg.Mods[i].Hash == hash {
+			return &m.cfg.Mods[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Get returns the library mod with the given hash.
+func (m *Manager) Get(hash string) (*Mod, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mod, err := m.findLocked(hash)
+	if err != nil {
+		return nil, err
+	}
+	copied := *mod
+	return &copied, nil
+}
+
+// Path returns the library file of the mod with the given hash.
+func (m *Manager) Path(hash string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mod, err := m.findLocked(hash)
+	if err != nil {
+		return "", false
+	}
+	return m.filePath(mod), true
+}
+
+// Import copies a mod file into the library and reads its metadata.
+// Importing a file that is already in the library returns the existing mod.
+func (m *Manager) Import(filePath, source string) (*Mod, error) {
+	if !isModFile(filePath) {
+		return nil, fmt.Errorf("unsupported mod file %q: expected .jar or .zip", filepath.Base(filePath))
+	}
+
+	hash, size, err := hashFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash mod: %w", err)
+	}
+
+	mod := Mod{
+		Hash:     hash,
+		Size:     size,
+		FileName: filepath.Base(filePath),
+		Source:   source,
+		AddedAt:  time.Now().UTC(),
+	}
+	if err := readMetadata(filePath, &mod); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, err := m.findLocked(hash); err == nil {
+		copied := *existing
+		return &copied, nil
+	}
+
+	dst := m.filePath(&mod)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create mod library: %w", err)
+	}
+	if err := copyFile(filePath, dst); err != nil {
+		return nil, fmt.Errorf("failed to copy mod into library: %w", err)
+	}
+
+	m.cfg.Mods = append(m.cfg.Mods, mod)
+	if err := m.saveLocked(); err != nil {
+		os.Remove(dst)
+		m.cfg.Mods = m.cfg.Mods[:len(m.cfg.Mods)-1]
+		return nil, err
+	}
+	return &mod, nil
+}
+
+// Remove deletes a mod from the library and from every mod set.
+// Deployed copies are removed on the next deployment.
+func (m *Manager) Remove(hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mod, err := m.findLocked(hash)
+	if err != nil {
+		return err
+	}
+	libPath := m.filePath(mod)
+
+	m.cfg.Mods = slices.DeleteFunc(m.cfg.Mods, func(mod Mod) bool {
+		return mod.Hash == hash
+	})
+	for target, set := range m.cfg.Sets {
+		m.cfg.Sets[target] = slices.DeleteFunc(set, func(h string) bool {
+			return h == hash
+		})
+	}
+
+	if err := m.saveLocked(); err != nil {
+		return err
+	}
+	if err := os.Remove(libPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete mod file: %w", err)
+	}
+	return nil
+}
+
+// Key returns the key of the mod set of a launch profile of target. An
+// empty profile is the target's default set, which is also used by profiles
+// without a set of their own.
+func Key(target, profile string) string {
+	if profile == "" {
+		return target
+	}
+	return target + ":" + profile
+}
+
+// TargetOf returns the launch target of a set key.
+func TargetOf(key string) string {
+	target, _, _ := strings.Cut(key, ":")
+	return target
+}
+
+// validKey reports whether key names a mod set.
+func validKey(key string) error {
+	if target := TargetOf(key); target != TargetClient && target != TargetServer {
+		return fmt.Errorf("unknown target %q", target)
+	}
+	return nil
+}
+
+// setLocked returns the hashes of the set with the given key, falling back
+// to the target's default set if the key has no set of its own.
+// Caller must hold m.mu.
+func (m *Manager) setLocked(key string) []string {
+	if set, ok := m.cfg.Sets[key]; ok {
+		return set
+	}
+	return m.cfg.Sets[TargetOf(key)]
+}
+
+// HasOwnSet reports whether key has a set of its own rather than using its
+// target's default set.
+func (m *Manager) HasOwnSet(key string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.cfg.Sets[key]
+	return ok
+}
+
+// Enabled returns the enabled mods of a set in load order.
+func (m *Manager) Enabled(key string) []Mod {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enabledLocked(key)
+}
+
+// enabledLocked returns the enabled mods of a set in load order.
+// Caller must hold m.mu.
+func (m *Manager) enabledLocked(key string) []Mod {
+	var result []Mod
+	for _, hash := range m.setLocked(key) {
+		if mod, err := m.findLocked(hash); err == nil {
+			result = append(result, *mod)
+		}
+	}
+	return result
+}
+
+// SetEnabled enables or disables a mod in a set. Newly enabled mods are
+// appended to the end of the load order. A profile without a set of its own
+// gets a copy of its target's default set first.
+func (m *Manager) SetEnabled(key, hash string, enabled bool) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.findLocked(hash); err != nil {
+		return err
+	}
+
+	set := slices.Clone(m.setLocked(key))
+	switch {
+	case enabled && !slices.Contains(set, hash):
+		set = append(set, hash)
+	case !enabled:
+		set = slices.DeleteFunc(set, func(h string) bool {
+			return h == hash
+		})
+	}
+	return m.storeLocked(key, set)
+}
+
+// SetOrder replaces the load order of a set's enabled mods. hashes must
+// contain exactly the currently enabled mods.
+func (m *Manager) SetOrder(key string, hashes []string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := slices.Clone(m.setLocked(key))
+	ordered := slices.Clone(hashes)
+	slices.Sort(current)
+	slices.Sort(ordered)
+	if !slices.Equal(current, ordered) {
+		return errors.New("order must list exactly the enabled mods")
+	}
+
+	return m.storeLocked(key, slices.Clone(hashes))
+}
+
+// SetMods replaces the enabled mods of a set with hashes, in load order.
+func (m *Manager) SetMods(key string, hashes []string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, hash := range hashes {
+		if _, err := m.findLocked(hash); err != nil {
+			return fmt.Errorf("%w: %s", err, hash)
+		}
+	}
+
+	return m.storeLocked(key, slices.Compact(slices.Clone(hashes)))
+}
+
+// storeLocked replaces a set and saves the index, restoring the previous
+// set if saving fails. Caller must hold m.mu.
+func (m *Manager) storeLocked(key string, set []string) error {
+	previous, had := m.cfg.Sets[key]
+	if set == nil {
+		set = []string{}
+	}
+	m.cfg.Sets[key] = set
+
+	if err := m.saveLocked(); err != nil {
+		if had {
+			m.cfg.Sets[key] = previous
+		} else {
+			delete(m.cfg.Sets, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteSet removes a profile's own set, so that it uses its target's
+// default set again. Default sets cannot be deleted.
+func (m *Manager) DeleteSet(key string) error {
+	if key == TargetOf(key) {
+		return fmt.Errorf("cannot delete the default %s mod set", key)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.cfg.Sets[key]; !ok {
+		return nil
+	}
+	delete(m.cfg.Sets, key)
+	return m.saveLocked()
+}
+
+// MoveSet moves a profile's own set to a new key, as when the profile is
+// renamed. Nothing happens if from has no set of its own.
+func (m *Manager) MoveSet(from, to string) error {
+	if err := validKey(to); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set, ok := m.cfg.Sets[from]
+	if !ok || from == to {
+		return nil
+	}
+	delete(m.cfg.Sets, from)
+	m.cfg.Sets[to] = set
+	return m.saveLocked()
+}
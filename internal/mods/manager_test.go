@@ -0,0 +1,170 @@
+package mods
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeMod writes a mod archive with a manifest for id and the given content
+// files, and returns its path.
+func writeMod(t *testing.T, dir, fileName, id string, files map[string]string) string {
+	t.Helper()
+
+	p := filepath.Join(dir, fileName)
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(manifestName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.NewEncoder(w).Encode(manifest{Group: "Test", Name: id, Version: "1.0"}); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// importMod writes a mod and imports it into m.
+func importMod(t *testing.T, m *Manager, fileName, id string, files map[string]string) *Mod {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	mod, err := m.Import(writeMod(t, dir, fileName, id, files), "test")
+	if err != nil {
+		t.Fatalf("Import(%s): %v", id, err)
+	}
+	return mod
+}
+
+// hashes returns the hashes of mods in order.
+func hashes(mods []Mod) []string {
+	var result []string
+	for _, mod := range mods {
+		result = append(result, mod.Hash)
+	}
+	return result
+}
+
+func TestProfileSetFallsBackToTargetDefault(t *testing.T) {
+	m := NewManager(t.TempDir())
+	a := importMod(t, m, "a.jar", "A", map[string]string{"a.txt": "a"})
+	b := importMod(t, m, "b.jar", "B", map[string]string{"b.txt": "b"})
+
+	if err := m.SetMods(TargetClient, []string{a.Hash}); err != nil {
+		t.Fatal(err)
+	}
+
+	alex := Key(TargetClient, "alex")
+	if m.HasOwnSet(alex) {
+		t.Fatal("profile has its own set before it was changed")
+	}
+	if got := hashes(m.Enabled(alex)); !slices.Equal(got, []string{a.Hash}) {
+		t.Fatalf("profile set = %v, want the default set", got)
+	}
+
+	// Changing the profile's set copies the default and leaves it alone.
+	if err := m.SetEnabled(alex, b.Hash, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := hashes(m.Enabled(alex)); !slices.Equal(got, []string{a.Hash, b.Hash}) {
+		t.Fatalf("profile set = %v, want [a b]", got)
+	}
+	if got := hashes(m.Enabled(TargetClient)); !slices.Equal(got, []string{a.Hash}) {
+		t.Fatalf("default set = %v, want [a]", got)
+	}
+
+	// An emptied profile set stays empty instead of falling back.
+	if err := m.SetMods(alex, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Enabled(alex); len(got) != 0 {
+		t.Fatalf("emptied profile set = %v", hashes(got))
+	}
+
+	// The sets survive a reload.
+	reloaded := NewManager(m.dir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.HasOwnSet(alex) || len(reloaded.Enabled(alex)) != 0 {
+		t.Fatal("empty profile set lost on reload")
+	}
+
+	if err := m.DeleteSet(alex); err != nil {
+		t.Fatal(err)
+	}
+	if got := hashes(m.Enabled(alex)); !slices.Equal(got, []string{a.Hash}) {
+		t.Fatalf("profile set after DeleteSet = %v, want the default set", got)
+	}
+	if err := m.DeleteSet(TargetClient); err == nil {
+		t.Fatal("deleted the default set")
+	}
+}
+
+func TestMoveSet(t *testing.T) {
+	m := NewManager(t.TempDir())
+	a := importMod(t, m, "a.jar", "A", nil)
+
+	from, to := Key(TargetClient, "alex"), Key(TargetClient, "sam")
+	if err := m.SetMods(from, []string{a.Hash}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.MoveSet(from, to); err != nil {
+		t.Fatal(err)
+	}
+	if m.HasOwnSet(from) || !m.HasOwnSet(to) {
+		t.Fatal("set not moved")
+	}
+	if got := hashes(m.Enabled(to)); !slices.Equal(got, []string{a.Hash}) {
+		t.Fatalf("moved set = %v", got)
+	}
+}
+
+func TestRemoveDropsModFromEverySet(t *testing.T) {
+	m := NewManager(t.TempDir())
+	a := importMod(t, m, "a.jar", "A", nil)
+
+	for _, key := range []string{TargetClient, TargetServer, Key(TargetClient, "alex")} {
+		if err := m.SetMods(key, []string{a.Hash}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Remove(a.Hash); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{TargetClient, TargetServer, Key(TargetClient, "alex")} {
+		if got := m.Enabled(key); len(got) != 0 {
+			t.Errorf("%s still enables %v", key, hashes(got))
+		}
+	}
+}
+
+func TestRejectsUnknownTarget(t *testing.T) {
+	m := NewManager(t.TempDir())
+	if err := m.SetMods(Key("launcher", "alex"), nil); err == nil {
+		t.Fatal("set for an unknown target accepted")
+	}
+}
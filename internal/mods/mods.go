@@ -0,0 +1,181 @@
+// Package mods keeps a library of mod files and deploys ordered sets of them
+// into the client's or server's mod directory before launch. Each launch
+// target has a default set; launch profiles may have a set of their own.
+package mods
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Launch targets that have a default mod set.
+const (
+	TargetClient = "client"
+	TargetServer = "server"
+)
+
+// manifestName is the metadata file inside a mod archive.
+const manifestName = "manifest.json"
+
+// ErrNotFound is returned when a mod is not in the library.
+var ErrNotFound = errors.New("mod not found")
+
+// Mod is a mod file stored in the library.
+type Mod struct {
+	// ID identifies the mod across versions (e.g., "Group:Name").
+	ID string `json:"id"`
+
+	// Name is the display name of the mod.
+	Name string `json:"name"`
+
+	// Version is the version reported by the mod's manifest.
+	Version string `json:"version,omitempty"`
+
+	// Hash is the SHA-256 of the mod file and its key in the library.
+	Hash string `json:"hash"`
+
+	// Size is the file size in bytes.
+	Size int64 `json:"size"`
+
+	// FileName is the file name used when the mod is deployed.
+	FileName string `json:"file_name"`
+
+	// Source is where the mod was imported from (a path or URL).
+	Source string `json:"source,omitempty"`
+
+	// AddedAt is when the mod was added to the library.
+	AddedAt time.Time `json:"added_at"`
+}
+
+// manifest is the subset of a mod manifest the launcher reads.
+type manifest struct {
+	Group   string `json:"Group"`
+	Name    string `json:"Name"`
+	Version string `json:"Version"`
+}
+
+// isModFile reports whether name has a supported mod file extension.
+func isModFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jar", ".zip":
+		return true
+	default:
+		return false
+	}
+}
+
+// readMetadata fills in the ID, name and version of a mod from its manifest,
+// falling back to the file name when the archive has none.
+func readMetadata(filePath string, mod *Mod) error {
+	stem := strings.TrimSuffix(mod.FileName, filepath.Ext(mod.FileName))
+	mod.ID = stem
+	mod.Name = stem
+
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return fmt.Errorf("not a valid mod archive: %w", err)
+	}
+	defer r.Close()
+
+	f, err := r.Open(manifestName)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var m manifest
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		return fmt.Errorf("invalid mod manifest: %w", err)
+	}
+
+	if m.Name != "" {
+		mod.Name = m.Name
+		mod.ID = m.Name
+		if m.Group != "" {
+			mod.ID = m.Group + ":" + m.Name
+		}
+	}
+	mod.Version = m.Version
+	return nil
+}
+
+// contentFiles lists the files a mod archive provides, excluding its own
+// metadata, for overlap detection.
+func contentFiles(filePath string) ([]string, error) {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var files []string
+	for _, f := range r.File {
+		name := path.Clean(f.Name)
+		if f.FileInfo().IsDir() || name == manifestName || strings.HasPrefix(name, "META-INF/") {
+			continue
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+// hashFile returns the hex SHA-256 and size of a file.
+func hashFile(filePath string) (string, int64, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// copyFile copies src to dst, syncing the result.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// linkOrCopy hard-links src to dst, copying when linking is not possible
+// (e.g., across volumes).
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	return copyFile(src, dst)
+}
@@ -0,0 +1,92 @@
+//go:build linux
+
+package notifications
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// The freedesktop notification service.
+const (
+	dbusDest   = "org.freedesktop.Notifications"
+	dbusPath   = "/org/freedesktop/Notifications"
+	dbusNotify = dbusDest + ".Notify"
+)
+
+// Urgency levels of the freedesktop notification spec.
+const (
+	urgencyNormal   byte = 1
+	urgencyCritical byte = 2
+)
+
+// DBusNotifier shows notifications through the freedesktop notification
+// service on a D-Bus connection.
+type DBusNotifier struct {
+	conn *dbus.Conn
+}
+
+// NewDBusNotifier creates a DBusNotifier using conn.
+func NewDBusNotifier(conn *dbus.Conn) *DBusNotifier {
+	return &DBusNotifier{conn: conn}
+}
+
+// DialDBus connects to the bus at address and returns a DBusNotifier for
+// it. An empty address uses the session bus.
+func DialDBus(address string) (*DBusNotifier, error) {
+	var conn *dbus.Conn
+	var err error
+	if address == "" {
+		conn, err = dbus.ConnectSessionBus()
+	} else {
+		conn, err = dbus.Connect(address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to D-Bus: %w", err)
+	}
+	return NewDBusNotifier(conn), nil
+}
+
+// NewDesktopNotifier returns a notifier for the desktop session bus.
+func NewDesktopNotifier() (Notifier, error) {
+	return DialDBus("")
+}
+
+// urgency returns the urgency hint for a notification type.
+func urgency(t NotificationType) byte {
+	if t == TypeError {
+		return urgencyCritical
+	}
+	return urgencyNormal
+}
+
+// Send implements Notifier by calling Notify on the notification service.
+func (d *DBusNotifier) Send(n Notification) error {
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(urgency(n.Type)),
+	}
+	if n.Event != "" {
+		hints["category"] = dbus.MakeVariant("x-hytale-launcher." + n.Event)
+	}
+
+	call := d.conn.Object(dbusDest, dbusPath).Call(dbusNotify, 0,
+		"Hytale Launcher", // app_name
+		uint32(0),         // replaces_id
+		"",                // app_icon
+		n.Title,
+		n.Message,
+		[]string{}, // actions
+		hints,
+		int32(-1), // expire_timeout, server default
+	)
+	if call.Err != nil {
+		return fmt.Errorf("failed to send desktop notification: %w", call.Err)
+	}
+	return nil
+}
+
+// Close closes the D-Bus connection.
+func (d *DBusNotifier) Close() error {
+	return d.conn.Close()
+}
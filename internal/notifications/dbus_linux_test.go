@@ -0,0 +1,119 @@
+//go:build linux
+
+package notifications
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+
+	"hytale-launcher/internal/events"
+)
+
+// fakeNotificationServer records Notify calls like a desktop notification
+// daemon.
+type fakeNotificationServer struct {
+	calls chan fakeNotification
+}
+
+// fakeNotification is a recorded Notify call.
+type fakeNotification struct {
+	appName, summary, body string
+	hints                  map[string]dbus.Variant
+}
+
+// Notify implements org.freedesktop.Notifications.Notify.
+func (f *fakeNotificationServer) Notify(appName string, replacesID uint32, icon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	f.calls <- fakeNotification{appName: appName, summary: summary, body: body, hints: hints}
+	return 1, nil
+}
+
+// startPrivateBus runs a private dbus-daemon for the test and returns its
+// address. The test is skipped if dbus-daemon is not installed.
+func startPrivateBus(t *testing.T) string {
+	t.Helper()
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+
+	cmd := exec.Command(daemon, "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+func TestDBusNotifier(t *testing.T) {
+	address := startPrivateBus(t)
+
+	// Serve the notification interface on the private bus.
+	server, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	fake := &fakeNotificationServer{calls: make(chan fakeNotification, 1)}
+	if err := server.Export(fake, dbusPath, dbusDest); err != nil {
+		t.Fatal(err)
+	}
+	if reply, err := server.RequestName(dbusDest, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("RequestName = %v, %v", reply, err)
+	}
+
+	notifier, err := DialDBus(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer notifier.Close()
+
+	err = notifier.Send(Notification{
+		Event:   events.ServerCrashed,
+		Type:    TypeError,
+		Title:   "Server crashed",
+		Message: "exit code 1",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	got := <-fake.calls
+	if got.appName != "Hytale Launcher" || got.summary != "Server crashed" || got.body != "exit code 1" {
+		t.Fatalf("notification = %+v", got)
+	}
+	if u, ok := got.hints["urgency"].Value().(byte); !ok || u != urgencyCritical {
+		t.Fatalf("urgency = %v, want critical", got.hints["urgency"])
+	}
+	if c := got.hints["category"].Value(); c != "x-hytale-launcher."+events.ServerCrashed {
+		t.Fatalf("category = %v", c)
+	}
+}
+
+func TestDBusNotifierWithoutService(t *testing.T) {
+	notifier, err := DialDBus(startPrivateBus(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer notifier.Close()
+
+	if err := notifier.Send(Notification{Title: "t", Message: "m"}); err == nil {
+		t.Fatal("Send succeeded without a notification service")
+	}
+}
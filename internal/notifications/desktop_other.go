@@ -0,0 +1,11 @@
+//go:build !linux
+
+package notifications
+
+import "errors"
+
+// NewDesktopNotifier returns a notifier for the desktop. Only Linux desktops
+// are supported so far.
+func NewDesktopNotifier() (Notifier, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -16,6 +16,10 @@ type Notification struct {
 
 	// Type indicates the notification type (info, warning, error, success).
 	Type NotificationType `json:"type"`
+
+	// Event is the launcher event that caused the notification, one of the
+	// names in package events. Routes select backends by event.
+	Event string `json:"event,omitempty"`
 }
 
 // NotificationType represents the type/severity of a notification.
@@ -0,0 +1,157 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"hytale-launcher/internal/events"
+)
+
+// Backend names used in routes.
+const (
+	// BackendLog writes notifications to the launcher log.
+	BackendLog = "log"
+
+	// BackendDesktop shows notifications on the desktop.
+	BackendDesktop = "desktop"
+
+	// BackendWebhook posts notifications to the configured webhook.
+	BackendWebhook = "webhook"
+)
+
+// Backends returns the backend names that may be used in routes.
+func Backends() []string {
+	return []string{BackendLog, BackendDesktop, BackendWebhook}
+}
+
+// DefaultRoutes sends every event to the desktop.
+const DefaultRoutes = "*=desktop"
+
+// Route sends the events matching Pattern to Backends. Patterns are those of
+// package events: an event name, a prefix ending in "*" such as "server:*",
+// or "*" alone.
+type Route struct {
+	Pattern  string
+	Backends []string
+}
+
+// Matches reports whether event matches the route's pattern.
+func (r Route) Matches(event string) bool {
+	return events.Match(r.Pattern, event)
+}
+
+// ParseRoutes parses routes written as
+// "server:crashed=desktop+webhook,update:*=desktop". An empty backend list
+// ("server:ready=") drops the matching events.
+func ParseRoutes(s string) ([]Route, error) {
+	var routes []Route
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		pattern, backends, ok := strings.Cut(part, "=")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("%q is not event=backend", part)
+		}
+		if err := events.ValidatePattern(pattern); err != nil {
+			return nil, err
+		}
+
+		route := Route{Pattern: pattern}
+		for _, name := range strings.Split(backends, "+") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if !slices.Contains(Backends(), name) {
+				return nil, fmt.Errorf("unknown notification backend %q for %s", name, pattern)
+			}
+			route.Backends = append(route.Backends, name)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// Router is a Notifier that sends each notification to the backends of the
+// first route matching its event. Notifications matching no route, or whose
+// route names only unavailable backends, are logged.
+type Router struct {
+	mu       sync.RWMutex
+	backends map[string]Notifier
+	routes   []Route
+}
+
+// NewRouter creates a Router that only has the log backend.
+func NewRouter() *Router {
+	return &Router{
+		backends: map[string]Notifier{BackendLog: &logNotifier{}},
+	}
+}
+
+// SetBackend registers n under name. A nil n removes the backend; routes
+// naming a missing backend skip it, and notifications whose route names no
+// registered backend are logged instead.
+func (r *Router) SetBackend(name string, n Notifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n == nil {
+		delete(r.backends, name)
+		return
+	}
+	r.backends[name] = n
+}
+
+// SetRoutes replaces the routes.
+func (r *Router) SetRoutes(routes []Route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = routes
+}
+
+// targets returns the backends that n should be sent to.
+func (r *Router) targets(n Notification) map[string]Notifier {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := []string{BackendLog}
+	for _, route := range r.routes {
+		if route.Matches(n.Event) {
+			names = route.Backends
+			break
+		}
+	}
+
+	targets := make(map[string]Notifier, len(names))
+	for _, name := range names {
+		if backend, ok := r.backends[name]; ok {
+			targets[name] = backend
+		} else {
+			slog.Debug("notification backend not available", "backend", name, "event", n.Event)
+		}
+	}
+	if len(targets) == 0 && len(names) > 0 {
+		targets[BackendLog] = r.backends[BackendLog]
+	}
+	return targets
+}
+
+// Send implements Notifier by sending n to every routed backend. A failing
+// backend does not stop the others.
+func (r *Router) Send(n Notification) error {
+	var errs []error
+	for name, backend := range r.targets(n) {
+		if err := backend.Send(n); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,47 @@
+package notifications
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"hytale-launcher/internal/events"
+)
+
+// recorder is a Notifier that records the events it was sent.
+type recorder struct {
+	sent []string
+}
+
+func (r *recorder) Send(n Notification) error {
+	r.sent = append(r.sent, n.Event)
+	return nil
+}
+
+func TestRouterTargets(t *testing.T) {
+	routes, err := ParseRoutes("server:ready=,server:*=desktop+webhook,update:*=webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRouter()
+	r.SetRoutes(routes)
+	r.SetBackend(BackendDesktop, &recorder{})
+
+	tests := []struct {
+		event string
+		want  []string
+	}{
+		{events.ServerReady, nil},
+		{events.ServerCrashed, []string{BackendDesktop}},
+		// No webhook is registered, so the update is logged, not dropped.
+		{events.UpdateComplete, []string{BackendLog}},
+		{"game:exited", []string{BackendLog}},
+	}
+	for _, tt := range tests {
+		got := slices.Sorted(maps.Keys(r.targets(Notification{Event: tt.event})))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("targets(%s) = %v, want %v", tt.event, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"hytale-launcher/internal/build"
+)
+
+// webhookTimeout bounds a single webhook delivery.
+const webhookTimeout = 10 * time.Second
+
+// WebhookPayload is the JSON body posted by WebhookNotifier.
+type WebhookPayload struct {
+	Event     string           `json:"event,omitempty"`
+	Title     string           `json:"title"`
+	Message   string           `json:"message"`
+	Type      NotificationType `json:"type"`
+	Timestamp time.Time        `json:"timestamp"`
+	Launcher  string           `json:"launcher"`
+}
+
+// WebhookNotifier posts notifications as JSON to a URL.
+//
+// Unlike the chat integrations in package integrations, it does not queue
+// through their outbox. A notification stands in for a desktop popup about
+// something happening now, such as an update finishing; one posted hours
+// later after retries or a restart would be stale, and the log backend
+// already keeps a record. So each notification gets a single attempt, and a
+// failure is reported to the caller and logged.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a WebhookNotifier posting to url. A nil client
+// uses one with a short timeout.
+func NewWebhookNotifier(url string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	return &WebhookNotifier{url: url, client: client}
+}
+
+// Send implements Notifier by posting n to the webhook. Any status other
+// than 2xx is an error.
+func (w *WebhookNotifier) Send(n Notification) error {
+	body, err := json.Marshal(WebhookPayload{
+		Event:     n.Event,
+		Title:     n.Title,
+		Message:   n.Message,
+		Type:      n.Type,
+		Timestamp: time.Now().UTC(),
+		Launcher:  build.Version,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", build.UserAgent())
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
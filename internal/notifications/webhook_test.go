@@ -0,0 +1,52 @@
+package notifications
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hytale-launcher/internal/events"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	payloads := make(chan WebhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		var p WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		payloads <- p
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	err := NewWebhookNotifier(server.URL, nil).Send(Notification{
+		Event:   events.UpdateComplete,
+		Type:    TypeSuccess,
+		Title:   "Update complete",
+		Message: "The game is up to date.",
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	p := <-payloads
+	if p.Event != events.UpdateComplete || p.Type != TypeSuccess || p.Title != "Update complete" || p.Timestamp.IsZero() {
+		t.Fatalf("payload = %+v", p)
+	}
+}
+
+func TestWebhookNotifierStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL, nil).Send(Notification{Title: "t"}); err == nil {
+		t.Fatal("Send succeeded on a 500")
+	}
+}
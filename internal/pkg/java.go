@@ -6,13 +6,13 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
-	"path/filepath"
 
 	"hytale-launcher/internal/appstate"
 	"hytale-launcher/internal/build"
 	"hytale-launcher/internal/download"
 	"hytale-launcher/internal/hytale"
 	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/javart"
 
 	"github.com/getsentry/sentry-go"
 )
@@ -163,7 +163,10 @@ func (u *javaUpdate) uninstall(ctx context.Context, state *appstate.State) {
 	state.SetDependency("jre", u.Channel, nil)
 }
 
-// validateBin validates the Java binary by running it with --version.
+// validateBin validates the Java binary by probing its version and architecture.
+// The bundled runtime is the one the game ships with, so a failed or negative
+// probe is only logged; the binary merely has to run. Runtimes added by the
+// user are held to the probe when they are selected.
 func (u *javaUpdate) validateBin(ctx context.Context, javaBin string) error {
 	// Skip validation in dev mode if environment variable is set
 	if build.IsDev() {
@@ -179,6 +182,35 @@ func (u *javaUpdate) validateBin(ctx context.Context, javaBin string) error {
 		"bin", javaBin,
 	)
 
+	// Read the runtime properties and check them against the game's requirements
+	rt, err := javart.Probe(ctx, javaBin, javart.SourceBundled)
+	if err != nil {
+		slog.Warn("failed to probe bundled java runtime, checking that it runs",
+			"bin", javaBin,
+			"error", err,
+		)
+		return u.testRun(ctx, javaBin)
+	}
+
+	slog.Debug("validated Java binary",
+		"version", rt.Version,
+		"vendor", rt.Vendor,
+		"arch", rt.Arch,
+	)
+
+	if !rt.Compatible {
+		slog.Warn("bundled java runtime does not meet the game's requirements",
+			"version", rt.Version,
+			"arch", rt.Arch,
+			"problem", rt.Problem,
+		)
+	}
+
+	return nil
+}
+
+// testRun checks that the Java binary runs by printing its version.
+func (u *javaUpdate) testRun(ctx context.Context, javaBin string) error {
 	// Create process with stdin/stdout/stderr
 	cmd := exec.CommandContext(ctx, javaBin, "--version")
 	cmd.Stdin = os.Stdin
@@ -204,5 +236,5 @@ func (u *javaUpdate) validateBin(ctx context.Context, javaBin string) error {
 // javaBinaryPath returns the path to the Java binary within the installation directory.
 func (u *javaUpdate) javaBinaryPath(javaDir string) string {
 	// Platform-specific path
-	return filepath.Join(javaDir, "bin", "java")
+	return javart.BinaryIn(javaDir)
 }
@@ -0,0 +1,206 @@
+package playerprofile
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Name limits for player profiles.
+const (
+	MinNameLen = 3
+	MaxNameLen = 16
+)
+
+var (
+	// ErrNotFound is returned when a profile does not exist.
+	ErrNotFound = errors.New("player profile not found")
+
+	// ErrExists is returned when a profile name is already taken.
+	ErrExists = errors.New("player profile already exists")
+)
+
+// namePattern matches the characters allowed in player names.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// reservedNames cannot be used as player names, compared case-insensitively.
+var reservedNames = []string{
+	"admin",
+	"console",
+	"hytale",
+	"null",
+	"operator",
+	"player",
+	"server",
+	"system",
+	"unknown",
+}
+
+// ValidateName checks that name can be used as a player name.
+func ValidateName(name string) error {
+	if n := len(name); n < MinNameLen || n > MaxNameLen {
+		return fmt.Errorf("player name must be %d to %d characters", MinNameLen, MaxNameLen)
+	}
+	if !namePattern.MatchString(name) {
+		return errors.New("player name may only contain letters, digits and underscores")
+	}
+	if slices.Contains(reservedNames, strings.ToLower(name)) {
+		return fmt.Errorf("player name %q is reserved", name)
+	}
+	return nil
+}
+
+// findLocked returns the profile whose name matches name case-insensitively.
+// Caller must hold m.mu.
+func (m *Manager) findLocked(name string) *PlayerProfile {
+	for existing, profile := range m.profiles {
+		if strings.EqualFold(existing, name) {
+			return profile
+		}
+	}
+	return nil
+}
+
+// Create validates name and adds a new profile for it. The first profile
+// becomes the default.
+func (m *Manager) Create(name string) (*PlayerProfile, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findLocked(name) != nil {
+		return nil, fmt.Errorf("%w: %s", ErrExists, name)
+	}
+
+	profile := m.newProfileLocked(name)
+	profile.Default = len(m.profiles) == 0
+	m.profiles[name] = profile
+
+	if err := m.saveLocked(); err != nil {
+		delete(m.profiles, name)
+		return nil, err
+	}
+	return profile.clone(), nil
+}
+
+// Rename changes a profile's name, keeping its UUID and user directory so
+// the player keeps their identity on servers.
+func (m *Manager) Rename(oldName, newName string) (*PlayerProfile, error) {
+	if err := ValidateName(newName); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	profile := m.findLocked(oldName)
+	if profile == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, oldName)
+	}
+	if other := m.findLocked(newName); other != nil && other != profile {
+		return nil, fmt.Errorf("%w: %s", ErrExists, newName)
+	}
+
+	previous := profile.Name
+	delete(m.profiles, previous)
+	profile.Name = newName
+	m.profiles[newName] = profile
+
+	if err := m.saveLocked(); err != nil {
+		delete(m.profiles, newName)
+		profile.Name = previous
+		m.profiles[previous] = profile
+		return nil, err
+	}
+	return profile.clone(), nil
+}
+
+// SetDefault makes the named profile the default.
+func (m *Manager) SetDefault(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	selected := m.findLocked(name)
+	if selected == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	for _, profile := range m.profiles {
+		profile.Default = profile == selected
+	}
+	return m.saveLocked()
+}
+
+// Default returns the default profile, or nil if there is none.
+func (m *Manager) Default() *PlayerProfile {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, profile := range m.profiles {
+		if profile.Default {
+			return profile.clone()
+		}
+	}
+	return nil
+}
+
+// SetUserDir sets the user directory of the named profile. An empty dir
+// makes the profile use the shared user directory again.
+func (m *Manager) SetUserDir(name, dir string) (*PlayerProfile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	profile := m.findLocked(name)
+	if profile == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	profile.UserDir = dir
+	if err := m.saveLocked(); err != nil {
+		return nil, err
+	}
+	return profile.clone(), nil
+}
+
+// SetSkin selects a skin or cosmetic preset for the named profile. An empty
+// id restores the default appearance.
+func (m *Manager) SetSkin(name, id string) (*PlayerProfile, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	profile := m.findLocked(name)
+	if profile == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	previous := profile.Skin
+	profile.Skin = id
+	if err := m.saveLocked(); err != nil {
+		profile.Skin = previous
+		return nil, err
+	}
+	return profile.clone(), nil
+}
+
+// ClearSkin removes a skin from every profile that selected it and returns
+// the names of those profiles.
+func (m *Manager) ClearSkin(id string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var cleared []string
+	for name, profile := range m.profiles {
+		if profile.Skin == id {
+			profile.Skin = ""
+			cleared = append(cleared, name)
+		}
+	}
+	if len(cleared) == 0 {
+		return nil, nil
+	}
+	return cleared, m.saveLocked()
+}
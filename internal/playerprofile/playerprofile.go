@@ -6,27 +6,45 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+
+	"hytale-launcher/internal/ioutil"
 )
 
 // PlayerProfile represents a player's offline profile with their UUID.
 type PlayerProfile struct {
 	// Name is the player's name.
 	Name string `json:"name"`
-	// UUID is the unique identifier for this player (UUID v5 based on name).
+	// UUID is the unique identifier for this player, derived by Scheme.
 	UUID string `json:"uuid"`
+	// Scheme is how UUID was derived. Empty means SchemeV5.
+	Scheme string `json:"scheme,omitempty"`
 	// CreatedAt is when the profile was created (ISO 8601 timestamp).
 	CreatedAt string `json:"created_at"`
+	// Default marks the profile used when no player is chosen.
+	Default bool `json:"default,omitempty"`
+	// UserDir is the profile's own game user directory, relative to the
+	// storage directory. Empty means the shared UserData directory.
+	UserDir string `json:"user_dir,omitempty"`
+	// Skin is the ID of the selected skin or cosmetic preset in the skin
+	// library. Empty means the game's default appearance.
+	Skin string `json:"skin,omitempty"`
 }
 
-// Manager manages player profiles.
+// Manager manages player profiles. Profiles it returns are copies: changes
+// go through its methods, which save them.
 type Manager struct {
 	profiles map[string]*PlayerProfile
 	filePath string
+	scheme   string
 	mu       sync.RWMutex
 }
 
@@ -35,6 +53,7 @@ func New(filePath string) *Manager {
 	return &Manager{
 		profiles: make(map[string]*PlayerProfile),
 		filePath: filePath,
+		scheme:   DefaultScheme,
 	}
 }
 
@@ -43,7 +62,15 @@ func (m *Manager) Load() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	data, err := os.ReadFile(m.filePath)
+	var profiles map[string]*PlayerProfile
+	err := ioutil.LoadWithBackup(m.filePath, func(data []byte) error {
+		var loaded map[string]*PlayerProfile
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("failed to unmarshal player profiles: %w", err)
+		}
+		profiles = loaded
+		return nil
+	})
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			// File doesn't exist yet, that's fine
@@ -52,11 +79,9 @@ func (m *Manager) Load() error {
 		return fmt.Errorf("failed to read player profiles: %w", err)
 	}
 
-	var profiles map[string]*PlayerProfile
-	if err := json.Unmarshal(data, &profiles); err != nil {
-		return fmt.Errorf("failed to unmarshal player profiles: %w", err)
+	if profiles == nil {
+		profiles = make(map[string]*PlayerProfile)
 	}
-
 	m.profiles = profiles
 	return nil
 }
@@ -77,7 +102,7 @@ func (m *Manager) Save() error {
 		return fmt.Errorf("failed to marshal player profiles: %w", err)
 	}
 
-	if err := os.WriteFile(m.filePath, data, 0600); err != nil {
+	if err := ioutil.WriteFileAtomic(m.filePath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write player profiles: %w", err)
 	}
 
@@ -85,45 +110,59 @@ func (m *Manager) Save() error {
 }
 
 // GetOrCreateProfile gets an existing profile or creates a new one with a unique UUID.
-// The UUID is generated using UUID v5 (SHA-1 based) with a fixed namespace for consistent results.
+// The UUID is derived from the name by the manager's scheme, UUID v5 by default.
+// Existing profiles are matched case-insensitively.
 func (m *Manager) GetOrCreateProfile(playerName string) (*PlayerProfile, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Check if profile already exists
-	if profile, exists := m.profiles[playerName]; exists {
-		return profile, nil
-	}
-
-	// Create namespace UUID for Hytale players
-	// Using a fixed UUID so the same player name always generates the same UUID
-	hytaleNamespace := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8") // DNS namespace
-
-	// Generate UUID v5 based on player name
-	playerUUID := uuid.NewSHA1(hytaleNamespace, []byte(playerName))
-
-	profile := &PlayerProfile{
-		Name:      playerName,
-		UUID:      playerUUID.String(),
-		CreatedAt: getTodayISO8601(),
+	if profile := m.findLocked(playerName); profile != nil {
+		return profile.clone(), nil
 	}
 
+	profile := m.newProfileLocked(playerName)
 	m.profiles[playerName] = profile
 
 	// Save to disk
 	if err := m.saveLocked(); err != nil {
+		delete(m.profiles, playerName)
 		return nil, err
 	}
 
-	return profile, nil
+	return profile.clone(), nil
+}
+
+// clone returns a copy of the profile, or nil for a nil profile.
+func (p *PlayerProfile) clone() *PlayerProfile {
+	if p == nil {
+		return nil
+	}
+	copied := *p
+	return &copied
+}
+
+// newProfileLocked creates a profile with a UUID derived by the manager's
+// scheme. Caller must hold m.mu.
+func (m *Manager) newProfileLocked(playerName string) *PlayerProfile {
+	// The scheme is always valid, so derivation cannot fail.
+	playerUUID, _ := DeriveUUID(m.scheme, playerName)
+
+	return &PlayerProfile{
+		Name:      playerName,
+		UUID:      playerUUID,
+		Scheme:    m.scheme,
+		CreatedAt: getTodayISO8601(),
+	}
 }
 
-// GetProfile returns an existing profile or nil if not found.
+// GetProfile returns an existing profile, matched case-insensitively, or nil
+// if not found.
 func (m *Manager) GetProfile(playerName string) *PlayerProfile {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return m.profiles[playerName]
+	return m.findLocked(playerName).clone()
 }
 
 // GetUUID returns the UUID for a player name, or generates one if it doesn't exist.
@@ -135,30 +174,79 @@ func (m *Manager) GetUUID(playerName string) (string, error) {
 	return profile.UUID, nil
 }
 
-// ListProfiles returns all stored player profiles.
+// ListProfiles returns all stored player profiles sorted by name.
 func (m *Manager) ListProfiles() []*PlayerProfile {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	profiles := make([]*PlayerProfile, 0, len(m.profiles))
 	for _, profile := range m.profiles {
-		profiles = append(profiles, profile)
+		profiles = append(profiles, profile.clone())
 	}
 
+	slices.SortFunc(profiles, func(a, b *PlayerProfile) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
 	return profiles
 }
 
-// DeleteProfile deletes a player profile.
+// DeleteProfile deletes a player profile. If it was the default, the first
+// remaining profile by name becomes the default.
 func (m *Manager) DeleteProfile(playerName string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	delete(m.profiles, playerName)
+	profile := m.findLocked(playerName)
+	if profile == nil {
+		return nil
+	}
+	delete(m.profiles, profile.Name)
+
+	if profile.Default && len(m.profiles) > 0 {
+		names := slices.SortedFunc(maps.Keys(m.profiles), func(a, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		})
+		m.profiles[names[0]].Default = true
+	}
 
 	// Save to disk
 	return m.saveLocked()
 }
 
+// Import adds profiles from another launcher. A profile whose name already
+// exists with a different UUID is a conflict: it is kept as is unless replace
+// is set. The names of conflicting profiles are returned.
+func (m *Manager) Import(profiles []*PlayerProfile, replace bool) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.profiles == nil {
+		m.profiles = make(map[string]*PlayerProfile)
+	}
+
+	var conflicts []string
+	for _, profile := range profiles {
+		if profile == nil || profile.Name == "" || uuid.Validate(profile.UUID) != nil {
+			continue
+		}
+		if existing := m.findLocked(profile.Name); existing != nil {
+			if existing.UUID == profile.UUID {
+				continue
+			}
+			conflicts = append(conflicts, profile.Name)
+			if !replace {
+				continue
+			}
+			delete(m.profiles, existing.Name)
+		}
+		imported := *profile
+		imported.Default = false
+		m.profiles[profile.Name] = &imported
+	}
+
+	return conflicts, m.saveLocked()
+}
+
 // saveLocked saves profiles without acquiring the lock.
 // Caller must hold m.mu.
 func (m *Manager) saveLocked() error {
@@ -172,16 +260,14 @@ func (m *Manager) saveLocked() error {
 		return fmt.Errorf("failed to marshal player profiles: %w", err)
 	}
 
-	if err := os.WriteFile(m.filePath, data, 0600); err != nil {
+	if err := ioutil.WriteFileAtomic(m.filePath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write player profiles: %w", err)
 	}
 
 	return nil
 }
 
-// getTodayISO8601 returns today's date in ISO 8601 format.
+// getTodayISO8601 returns the current time in ISO 8601 format.
 func getTodayISO8601() string {
-	// Simple implementation - returns current time in ISO 8601 format
-	// In production, you'd use time.Now().Format(time.RFC3339)
-	return "2026-01-15T00:00:00Z"
+	return time.Now().UTC().Format(time.RFC3339)
 }
@@ -0,0 +1,111 @@
+package playerprofile
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// newTestManager creates a Manager storing its profiles in a temporary file.
+func newTestManager(t *testing.T, names ...string) *Manager {
+	t.Helper()
+	m := New(filepath.Join(t.TempDir(), "profiles.json"))
+	for _, name := range names {
+		if _, err := m.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return m
+}
+
+func TestLookupsIgnoreCase(t *testing.T) {
+	m := newTestManager(t, "Steve", "Alex")
+
+	profile, err := m.GetOrCreateProfile("steve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Name != "Steve" || len(m.ListProfiles()) != 2 {
+		t.Fatalf("GetOrCreateProfile created %+v instead of finding Steve", profile)
+	}
+	if p := m.GetProfile("STEVE"); p == nil || *p != *profile {
+		t.Fatal("GetProfile is case-sensitive")
+	}
+
+	if err := m.SetDefault("alex"); err != nil {
+		t.Fatal(err)
+	}
+	if d := m.Default(); d == nil || d.Name != "Alex" {
+		t.Fatalf("default = %+v, want Alex", d)
+	}
+	if _, err := m.SetUserDir("alex", "profiles/alex/UserData"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.SetSkin("ALEX", "skin-1"); err != nil {
+		t.Fatal(err)
+	}
+	if p := m.GetProfile("Alex"); p.UserDir == "" || p.Skin != "skin-1" {
+		t.Fatalf("profile = %+v", p)
+	}
+}
+
+func TestDeleteDefaultPromotesAnother(t *testing.T) {
+	m := newTestManager(t, "Steve", "Alex", "bob")
+	if d := m.Default(); d == nil || d.Name != "Steve" {
+		t.Fatalf("default = %+v, want Steve", d)
+	}
+
+	if err := m.DeleteProfile("steve"); err != nil {
+		t.Fatal(err)
+	}
+	if m.GetProfile("Steve") != nil {
+		t.Fatal("profile not deleted")
+	}
+	if d := m.Default(); d == nil || d.Name != "Alex" {
+		t.Fatalf("default = %+v, want Alex", d)
+	}
+
+	// The promotion is saved.
+	reloaded := New(m.filePath)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if d := reloaded.Default(); d == nil || d.Name != "Alex" {
+		t.Fatalf("reloaded default = %+v, want Alex", d)
+	}
+}
+
+func TestProfilesAreCopies(t *testing.T) {
+	m := newTestManager(t, "Steve")
+
+	created, err := m.GetOrCreateProfile("Alex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed, err := m.Rename("Steve", "Steven")
+	if err != nil {
+		t.Fatal(err)
+	}
+	skinned, err := m.SetSkin("Alex", "skin-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	returned := []*PlayerProfile{created, renamed, skinned, m.GetProfile("Alex"), m.Default()}
+	returned = append(returned, m.ListProfiles()...)
+	for _, p := range returned {
+		p.UUID = "changed"
+		p.Default = true
+		p.UserDir = "elsewhere"
+	}
+
+	for _, p := range m.ListProfiles() {
+		if p.UUID == "changed" || p.UserDir != "" {
+			t.Errorf("stored profile changed through a returned copy: %+v", p)
+		}
+	}
+	if d := m.Default(); d == nil || d.Name != "Steven" {
+		t.Errorf("default = %+v, want Steven", d)
+	}
+	if p := m.GetProfile("Alex"); p.Skin != "skin-1" {
+		t.Errorf("Alex = %+v, want the skin set through the manager", p)
+	}
+}
@@ -0,0 +1,219 @@
+package playerprofile
+
+import (
+	"crypto/md5"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Offline UUID schemes control how a profile's UUID is derived.
+const (
+	// SchemeV5 derives a version 5 UUID from the name in the DNS namespace.
+	// Profiles without a recorded scheme use it.
+	SchemeV5 = "v5"
+
+	// SchemeOfflinePlayer derives a version 3 UUID from "OfflinePlayer:<name>",
+	// as common community server software does for offline players.
+	SchemeOfflinePlayer = "offline_player"
+
+	// SchemeRandom assigns a random UUID once per profile.
+	SchemeRandom = "random"
+
+	// SchemeImported keeps a UUID that was set explicitly.
+	SchemeImported = "imported"
+)
+
+// DefaultScheme is the scheme used for new profiles unless configured.
+const DefaultScheme = SchemeV5
+
+// Schemes returns the schemes new profiles can be created with.
+func Schemes() []string {
+	return []string{SchemeV5, SchemeOfflinePlayer, SchemeRandom}
+}
+
+// ValidScheme reports whether scheme can be used for new profiles.
+func ValidScheme(scheme string) bool {
+	return slices.Contains(Schemes(), scheme)
+}
+
+// v5Namespace is the namespace of SchemeV5 UUIDs.
+var v5Namespace = uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8") // DNS namespace
+
+// DeriveUUID returns the UUID of a player name under scheme. Random UUIDs
+// differ on every call.
+func DeriveUUID(scheme, name string) (string, error) {
+	switch scheme {
+	case SchemeV5, "":
+		return uuid.NewSHA1(v5Namespace, []byte(name)).String(), nil
+	case SchemeOfflinePlayer:
+		return offlinePlayerUUID(name).String(), nil
+	case SchemeRandom:
+		return uuid.NewString(), nil
+	case SchemeImported:
+		return "", fmt.Errorf("scheme %q requires an explicit UUID", scheme)
+	default:
+		return "", fmt.Errorf("unknown UUID scheme %q", scheme)
+	}
+}
+
+// offlinePlayerUUID returns the name-based version 3 UUID of
+// "OfflinePlayer:<name>". Unlike uuid.NewMD5 it hashes no namespace, which
+// matches Java's UUID.nameUUIDFromBytes.
+func offlinePlayerUUID(name string) uuid.UUID {
+	id := uuid.UUID(md5.Sum([]byte("OfflinePlayer:" + name)))
+	id[6] = (id[6] & 0x0f) | 0x30 // version 3
+	id[8] = (id[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return id
+}
+
+// schemeOf returns the scheme a profile was created with.
+func schemeOf(profile *PlayerProfile) string {
+	if profile.Scheme == "" {
+		return SchemeV5
+	}
+	return profile.Scheme
+}
+
+// UUIDChange describes how a profile's UUID would change under a scheme.
+type UUIDChange struct {
+	// Name is the profile name.
+	Name string `json:"name"`
+
+	// FromScheme is the profile's current scheme.
+	FromScheme string `json:"from_scheme"`
+
+	// OldUUID is the profile's current UUID.
+	OldUUID string `json:"old_uuid"`
+
+	// NewUUID is the UUID under the new scheme. It is empty when a random
+	// UUID will be assigned.
+	NewUUID string `json:"new_uuid,omitempty"`
+
+	// Changes is set if the profile's UUID would change.
+	Changes bool `json:"changes"`
+
+	// Skipped is set for profiles that keep their UUID, such as imported ones.
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// planLocked computes the UUID changes of every profile under scheme.
+// Caller must hold m.mu.
+func (m *Manager) planLocked(scheme string) []UUIDChange {
+	changes := make([]UUIDChange, 0, len(m.profiles))
+	for name, profile := range m.profiles {
+		change := UUIDChange{
+			Name:       name,
+			FromScheme: schemeOf(profile),
+			OldUUID:    profile.UUID,
+		}
+
+		switch {
+		case change.FromScheme == SchemeImported:
+			// Explicit UUIDs are never replaced by a scheme.
+			change.Skipped = true
+		case scheme == SchemeRandom && change.FromScheme == SchemeRandom:
+			// Random UUIDs are assigned once.
+		case scheme == SchemeRandom:
+			change.Changes = true
+		default:
+			change.NewUUID, _ = DeriveUUID(scheme, name)
+			change.Changes = !strings.EqualFold(change.NewUUID, profile.UUID)
+		}
+		changes = append(changes, change)
+	}
+
+	slices.SortFunc(changes, func(a, b UUIDChange) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return changes
+}
+
+// PlanScheme reports which profiles would change UUID if they were moved to
+// scheme, without changing anything.
+func (m *Manager) PlanScheme(scheme string) ([]UUIDChange, error) {
+	if !ValidScheme(scheme) {
+		return nil, fmt.Errorf("unknown UUID scheme %q", scheme)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.planLocked(scheme), nil
+}
+
+// ApplyScheme moves every profile except imported ones to scheme and
+// returns the changes made.
+func (m *Manager) ApplyScheme(scheme string) ([]UUIDChange, error) {
+	if !ValidScheme(scheme) {
+		return nil, fmt.Errorf("unknown UUID scheme %q", scheme)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	changes := m.planLocked(scheme)
+	previous := make(map[string]PlayerProfile, len(m.profiles))
+	for i := range changes {
+		change := &changes[i]
+		if change.Skipped {
+			continue
+		}
+
+		profile := m.profiles[change.Name]
+		previous[change.Name] = *profile
+		if change.Changes && change.NewUUID == "" {
+			change.NewUUID = uuid.NewString()
+		}
+		if change.Changes {
+			profile.UUID = change.NewUUID
+		}
+		profile.Scheme = scheme
+	}
+
+	if err := m.saveLocked(); err != nil {
+		for name, profile := range previous {
+			*m.profiles[name] = profile
+		}
+		return nil, err
+	}
+	return changes, nil
+}
+
+// SetUUID gives a profile an explicit UUID, such as one issued by a server,
+// and marks it as imported so that schemes never replace it.
+func (m *Manager) SetUUID(name, id string) (*PlayerProfile, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UUID %q: %w", id, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	profile := m.findLocked(name)
+	if profile == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+
+	previous := *profile
+	profile.UUID = parsed.String()
+	profile.Scheme = SchemeImported
+	if err := m.saveLocked(); err != nil {
+		*profile = previous
+		return nil, err
+	}
+	return profile.clone(), nil
+}
+
+// SetScheme sets the scheme used for profiles created from now on.
+func (m *Manager) SetScheme(scheme string) {
+	if !ValidScheme(scheme) {
+		scheme = DefaultScheme
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scheme = scheme
+}
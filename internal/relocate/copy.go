@@ -0,0 +1,148 @@
+package relocate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// copiedFile records a copied file and the hash of its source content.
+type copiedFile struct {
+	rel  string
+	size int64
+	hash []byte
+}
+
+// copyAndVerify copies the tree at from into to and then checks every copied
+// file against the hash taken while reading the source.
+func copyAndVerify(ctx context.Context, from, to string, total int64, progress ProgressFunc) error {
+	if progress == nil {
+		progress = func(string, int64, int64) {}
+	}
+
+	if err := os.MkdirAll(to, 0755); err != nil {
+		return fmt.Errorf("failed to create target directory: %w", err)
+	}
+
+	var files []copiedFile
+	var done int64
+
+	err := filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(to, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(dest, info.Mode().Perm()|0700)
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(target, dest)
+		case !info.Mode().IsRegular():
+			return nil
+		}
+
+		hash, err := copyFile(path, dest, info)
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", rel, err)
+		}
+
+		files = append(files, copiedFile{rel: rel, size: info.Size(), hash: hash})
+		done += info.Size()
+		progress("copy", done, total)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	done = 0
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		hash, err := hashFile(filepath.Join(to, f.rel))
+		if err != nil {
+			return fmt.Errorf("failed to verify %s: %w", f.rel, err)
+		}
+		if !bytes.Equal(hash, f.hash) {
+			return fmt.Errorf("verification failed for %s: content differs from source", f.rel)
+		}
+
+		done += f.size
+		progress("verify", done, total)
+	}
+
+	return nil
+}
+
+// copyFile copies a regular file, preserving its mode and modification time,
+// and returns the SHA-256 hash of the source content.
+func copyFile(src, dest string, info fs.FileInfo) ([]byte, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), in); err != nil {
+		out.Close()
+		return nil, err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := os.Chtimes(dest, info.ModTime(), info.ModTime()); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// hashFile returns the SHA-256 hash of a file's content.
+func hashFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,308 @@
+// Package relocate moves the launcher storage directory to another location.
+// The move copies and verifies the whole tree before switching the bootstrap
+// pointer, and keeps a journal so an interrupted move can be rolled back.
+package relocate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/ioutil"
+)
+
+// journalFileName is the name of the relocation journal, kept next to the
+// bootstrap pointer file.
+const journalFileName = "hytale-relocation.json"
+
+// freeSpaceMargin is the fraction of extra space required beyond the data size.
+const freeSpaceMargin = 0.05
+
+// Phase is the stage a relocation has reached.
+type Phase string
+
+const (
+	// PhaseCopying means files are being copied; the move can be rolled back.
+	PhaseCopying Phase = "copying"
+
+	// PhaseCommitted means the pointer file has been switched and the old
+	// tree only needs to be removed.
+	PhaseCommitted Phase = "committed"
+)
+
+// journal records an in-progress relocation.
+type journal struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Phase     Phase     `json:"phase"`
+	CreatedTo bool      `json:"created_to"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// ProgressFunc is called as files are copied and verified.
+// done and total are byte counts; stage is "copy" or "verify".
+type ProgressFunc func(stage string, done, total int64)
+
+// ErrPending is returned when a previous relocation has not been finished.
+var ErrPending = errors.New("a storage relocation is already pending")
+
+// journalPath returns the path of the relocation journal.
+func journalPath() (string, error) {
+	loc, err := hytale.LocationFile()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(loc), journalFileName), nil
+}
+
+// readJournal reads the relocation journal, returning nil if there is none.
+func readJournal() (*journal, error) {
+	path, err := journalPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var j journal
+	err = ioutil.LoadWithBackup(path, func(data []byte) error {
+		var loaded journal
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("invalid relocation journal: %w", err)
+		}
+		j = loaded
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// writeJournal persists the relocation journal.
+func writeJournal(j *journal) error {
+	path, err := journalPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFileAtomic(path, data, 0644)
+}
+
+// removeJournal deletes the relocation journal.
+func removeJournal() error {
+	path, err := journalPath()
+	if err != nil {
+		return err
+	}
+	return ioutil.RemoveWithBackup(path)
+}
+
+// Pending reports whether a committed relocation is waiting for a restart.
+func Pending() bool {
+	j, err := readJournal()
+	return err == nil && j != nil && j.Phase == PhaseCommitted
+}
+
+// Move copies the storage tree from one directory to another, verifies the
+// copy and switches the bootstrap pointer to the new location.
+// The old tree is removed by Recover on the next start, so the caller must
+// restart before anything else is written to it.
+// If the move fails or ctx is cancelled, the partial copy is removed.
+func Move(ctx context.Context, from, to string, progress ProgressFunc) error {
+	from, to = filepath.Clean(from), filepath.Clean(to)
+
+	if j, err := readJournal(); err != nil {
+		return err
+	} else if j != nil {
+		return ErrPending
+	}
+
+	createdTo, err := validateTarget(from, to)
+	if err != nil {
+		return err
+	}
+
+	total, err := ioutil.DirSize(from)
+	if err != nil {
+		return fmt.Errorf("failed to measure storage directory: %w", err)
+	}
+	if err := checkFreeSpace(to, total); err != nil {
+		return err
+	}
+
+	slog.Info("relocating storage directory", "from", from, "to", to, "bytes", total)
+
+	j := &journal{
+		From:      from,
+		To:        to,
+		Phase:     PhaseCopying,
+		CreatedTo: createdTo,
+		StartedAt: time.Now(),
+	}
+	if err := writeJournal(j); err != nil {
+		return fmt.Errorf("failed to write relocation journal: %w", err)
+	}
+
+	if err := copyAndVerify(ctx, from, to, total, progress); err != nil {
+		slog.Error("storage relocation failed, rolling back", "error", err)
+		if rbErr := rollback(j); rbErr != nil {
+			slog.Error("failed to roll back storage relocation", "error", rbErr)
+		}
+		return err
+	}
+
+	if err := hytale.WriteLocation(hytale.Location{StorageDir: to}); err != nil {
+		if rbErr := rollback(j); rbErr != nil {
+			slog.Error("failed to roll back storage relocation", "error", rbErr)
+		}
+		return fmt.Errorf("failed to record new storage location: %w", err)
+	}
+
+	j.Phase = PhaseCommitted
+	if err := writeJournal(j); err != nil {
+		slog.Warn("failed to mark relocation as committed", "error", err)
+	}
+
+	slog.Info("storage directory relocated, restart required", "to", to)
+	return nil
+}
+
+// Recover finishes or rolls back a relocation left behind by a previous run.
+// It must be called at startup before anything resolves the storage
+// directory, since it may change which directory the pointer names.
+func Recover() error {
+	j, err := readJournal()
+	if err != nil || j == nil {
+		return err
+	}
+
+	current, err := currentLocation()
+	if err != nil {
+		return err
+	}
+
+	// The pointer is switched before the journal is marked committed, so a
+	// run that stopped in between left a finished move behind.
+	if j.Phase != PhaseCommitted && current != j.To {
+		slog.Warn("rolling back interrupted storage relocation", "from", j.From, "to", j.To)
+		return rollback(j)
+	}
+
+	if info, err := os.Stat(j.To); current != j.To || err != nil || !info.IsDir() {
+		slog.Warn("relocated storage directory is not in use, keeping old directory",
+			"from", j.From,
+			"to", j.To,
+		)
+		return removeJournal()
+	}
+
+	slog.Info("removing old storage directory after relocation", "dir", j.From)
+	if err := os.RemoveAll(j.From); err != nil {
+		return fmt.Errorf("failed to remove old storage directory: %w", err)
+	}
+	return removeJournal()
+}
+
+// currentLocation returns the storage directory the bootstrap pointer names.
+func currentLocation() (string, error) {
+	loc, err := hytale.ReadLocation()
+	if err != nil {
+		return "", err
+	}
+	if loc == nil {
+		return hytale.DefaultStorageDir()
+	}
+	return filepath.Clean(loc.StorageDir), nil
+}
+
+// rollback removes a partial copy and forgets the relocation.
+func rollback(j *journal) error {
+	if j.CreatedTo {
+		if err := os.RemoveAll(j.To); err != nil {
+			return err
+		}
+	} else if err := clearDir(j.To); err != nil {
+		return err
+	}
+
+	if err := hytale.WriteLocation(hytale.Location{StorageDir: j.From}); err != nil {
+		return err
+	}
+	return removeJournal()
+}
+
+// clearDir removes the contents of dir but keeps dir itself.
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTarget checks that to is a usable destination for from.
+// It returns true if the destination directory does not exist yet.
+func validateTarget(from, to string) (bool, error) {
+	if !filepath.IsAbs(to) {
+		return false, errors.New("target directory must be an absolute path")
+	}
+	if from == to {
+		return false, errors.New("target directory is the current storage directory")
+	}
+	if isWithin(to, from) || isWithin(from, to) {
+		return false, errors.New("target directory must not contain or be inside the current storage directory")
+	}
+
+	entries, err := os.ReadDir(to)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to read target directory: %w", err)
+	}
+	if len(entries) > 0 {
+		return false, errors.New("target directory is not empty")
+	}
+	return false, nil
+}
+
+// isWithin reports whether path is inside dir.
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+// checkFreeSpace returns an error if the volume holding dir cannot fit size bytes.
+func checkFreeSpace(dir string, size int64) error {
+	free, err := ioutil.FreeSpace(dir)
+	if err != nil {
+		return fmt.Errorf("unable to determine free space: %w", err)
+	}
+
+	required := uint64(float64(size) * (1 + freeSpaceMargin))
+	if free < required {
+		return fmt.Errorf("not enough free space: %d bytes required, %d available", required, free)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package relocate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"hytale-launcher/internal/hytale"
+)
+
+// setupRelocation creates a default storage directory and an empty target
+// under a temporary app data directory, with a journal in the given phase.
+func setupRelocation(t *testing.T, phase Phase) (from, to string) {
+	t.Helper()
+
+	appData := t.TempDir()
+	t.Setenv("APPDATA", "")
+	t.Setenv("XDG_DATA_HOME", appData)
+
+	from, err := hytale.DefaultStorageDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to = filepath.Join(appData, "moved")
+	for _, dir := range []string{from, to} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "settings.json"), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = writeJournal(&journal{From: from, To: to, Phase: phase, CreatedTo: true, StartedAt: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return from, to
+}
+
+// assertExists fails unless path exists as wanted.
+func assertExists(t *testing.T, path string, want bool) {
+	t.Helper()
+	_, err := os.Stat(path)
+	if got := err == nil; got != want {
+		t.Fatalf("%s exists = %v, want %v", path, got, want)
+	}
+}
+
+func TestRecoverRollsBackInterruptedCopy(t *testing.T) {
+	from, to := setupRelocation(t, PhaseCopying)
+
+	if err := Recover(); err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	assertExists(t, from, true)
+	assertExists(t, to, false)
+	if j, _ := readJournal(); j != nil {
+		t.Fatalf("journal kept: %+v", j)
+	}
+}
+
+func TestRecoverTreatsSwitchedPointerAsCommitted(t *testing.T) {
+	// The pointer was switched but the journal still says copying.
+	from, to := setupRelocation(t, PhaseCopying)
+	if err := hytale.WriteLocation(hytale.Location{StorageDir: to}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Recover(); err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	assertExists(t, to, true)
+	assertExists(t, from, false)
+	if loc, _ := hytale.ReadLocation(); loc == nil || loc.StorageDir != to {
+		t.Fatalf("pointer = %+v, want %s", loc, to)
+	}
+}
+
+func TestRecoverKeepsOldDirectoryWhenTargetIsMissing(t *testing.T) {
+	from, to := setupRelocation(t, PhaseCommitted)
+	if err := hytale.WriteLocation(hytale.Location{StorageDir: to}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(to); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Recover(); err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	assertExists(t, from, true)
+}
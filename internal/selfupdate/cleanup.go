@@ -10,6 +10,7 @@ import (
 
 	"hytale-launcher/internal/crypto"
 	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/ioutil"
 )
 
 // cleanupNoteFile returns the path to the cleanup note file.
@@ -39,7 +40,7 @@ func (n *cleanupNote) WriteFile() error {
 // It returns the cleanup note if one exists, or nil if not.
 func consumeCleanupNote() (*cleanupNote, error) {
 	defer func() {
-		os.Remove(cleanupNoteFile())
+		ioutil.RemoveWithBackup(cleanupNoteFile())
 	}()
 
 	data, err := crypto.ReadFile(cleanupNoteFile(), cleanupNoteKeyName)
@@ -0,0 +1,81 @@
+package serverout
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Player is a player online on the server.
+type Player struct {
+	// Name is the player name.
+	Name string `json:"name"`
+
+	// JoinedAt is when the player joined.
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Players tracks the players online from parsed join and leave entries.
+type Players struct {
+	mu     sync.RWMutex
+	online map[string]Player
+}
+
+// NewPlayers creates an empty player list.
+func NewPlayers() *Players {
+	return &Players{online: make(map[string]Player)}
+}
+
+// Apply updates the list from an entry and reports whether it changed.
+func (p *Players) Apply(e Entry) bool {
+	name := e.Fields["player"]
+	if name == "" {
+		return false
+	}
+	key := strings.ToLower(name)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch e.Event {
+	case EventPlayerJoined:
+		if _, ok := p.online[key]; ok {
+			return false
+		}
+		p.online[key] = Player{Name: name, JoinedAt: time.Now()}
+		return true
+	case EventPlayerLeft:
+		if _, ok := p.online[key]; !ok {
+			return false
+		}
+		delete(p.online, key)
+		return true
+	}
+	return false
+}
+
+// Reset empties the list and reports whether it had any players.
+func (p *Players) Reset() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	had := len(p.online) > 0
+	clear(p.online)
+	return had
+}
+
+// List returns the players online, in the order they joined.
+func (p *Players) List() []Player {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	result := make([]Player, 0, len(p.online))
+	for _, player := range p.online {
+		result = append(result, player)
+	}
+	slices.SortFunc(result, func(a, b Player) int {
+		return a.JoinedAt.Compare(b.JoinedAt)
+	})
+	return result
+}
@@ -0,0 +1,251 @@
+// Package serverout parses the output of the launcher-managed server into
+// structured events, such as players joining or chatting, and keeps the list
+// of players online. The patterns are rules that can be replaced from a file
+// when the server's log format changes.
+package serverout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"hytale-launcher/internal/ioutil"
+)
+
+// Events parsed from server output.
+const (
+	EventReady        = "server:ready"
+	EventPlayerJoined = "server:player_joined"
+	EventPlayerLeft   = "server:player_left"
+	EventChat         = "server:chat"
+	EventWarning      = "server:warning"
+	EventError        = "server:error"
+	EventLag          = "server:lag"
+)
+
+// Events returns the events rules may produce.
+func Events() []string {
+	return []string{EventReady, EventPlayerJoined, EventPlayerLeft, EventChat, EventWarning, EventError, EventLag}
+}
+
+// requiredGroups lists the named groups a rule's pattern must capture for
+// its event.
+var requiredGroups = map[string][]string{
+	EventPlayerJoined: {"player"},
+	EventPlayerLeft:   {"player"},
+	EventChat:         {"player", "message"},
+}
+
+// Rule turns the lines matching Pattern into Event. The pattern's named
+// groups become the event's fields.
+type Rule struct {
+	// Event is one of the Event constants.
+	Event string `json:"event"`
+
+	// Pattern is a regular expression in Go syntax.
+	Pattern string `json:"pattern"`
+
+	// Threshold, if set, only lets the rule match when a numeric field is
+	// below a limit.
+	Threshold *Threshold `json:"threshold,omitempty"`
+}
+
+// Threshold limits a rule to lines whose Field parses as a number below
+// Below, such as a TPS report that is low enough to mean lag.
+type Threshold struct {
+	// Field is a named group of the rule's pattern.
+	Field string `json:"field"`
+
+	// Below is the exclusive upper limit of the field.
+	Below float64 `json:"below"`
+}
+
+// LagTPS is the tick rate below which the default rules report lag.
+const LagTPS = 15
+
+// levelPrefix matches the "[2026/01/13 17:29:39   INFO] " prefix of server
+// lines up to the level.
+const levelPrefix = `^\[\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}\s+`
+
+// infoPrefix matches the prefix of INFO lines, including an optional
+// "[Logger] " tag after it.
+const infoPrefix = levelPrefix + `INFO\]\s*(?:\[[^\]]*\]\s*)?`
+
+// playerName matches a player name.
+const playerName = `(?P<player>[A-Za-z0-9_]{3,16})`
+
+// DefaultRules are used unless a rules file replaces them. Rules are tried
+// in order: warnings and errors come first so their messages are never taken
+// for chat or joins, and chat comes before joins so players cannot fake them.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Event: EventReady, Pattern: regexp.QuoteMeta("Hytale Server Booted!")},
+		{Event: EventWarning, Pattern: levelPrefix + `WARN(?:ING)?\]\s*(?P<message>.*)$`},
+		{Event: EventError, Pattern: levelPrefix + `(?:ERROR|SEVERE|FATAL)\]\s*(?P<message>.*)$`},
+		{Event: EventChat, Pattern: infoPrefix + `<` + playerName + `>\s(?P<message>.*)$`},
+		{Event: EventPlayerJoined, Pattern: `(?i)\bplayer\s+'?` + playerName + `'?\s+(?:has\s+)?(?:joined|connected)\b`},
+		{Event: EventPlayerLeft, Pattern: `(?i)\bplayer\s+'?` + playerName + `'?\s+(?:has\s+)?(?:left|disconnected)\b(?:.*?\breason:?\s*(?P<reason>[^)]+))?`},
+		{Event: EventLag, Pattern: `(?i)(?:can't keep up|running behind)\b(?:.*?(?P<behind_ms>\d+)\s*ms)?`},
+		{
+			Event:     EventLag,
+			Pattern:   `(?i)\bTPS:?\s*(?P<tps>\d+(?:\.\d+)?)`,
+			Threshold: &Threshold{Field: "tps", Below: LagTPS},
+		},
+	}
+}
+
+// compile checks a rule and compiles its pattern.
+func (r Rule) compile() (*regexp.Regexp, error) {
+	if !slices.Contains(Events(), r.Event) {
+		return nil, fmt.Errorf("unknown event %q", r.Event)
+	}
+
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern for %s: %w", r.Event, err)
+	}
+	for _, group := range requiredGroups[r.Event] {
+		if re.SubexpIndex(group) < 0 {
+			return nil, fmt.Errorf("pattern for %s must capture (?P<%s>...)", r.Event, group)
+		}
+	}
+	if r.Threshold != nil && re.SubexpIndex(r.Threshold.Field) < 0 {
+		return nil, fmt.Errorf("threshold field %q is not captured by the pattern for %s", r.Threshold.Field, r.Event)
+	}
+	return re, nil
+}
+
+// Entry is a line of server output that matched a rule.
+type Entry struct {
+	// Event is the rule's event.
+	Event string `json:"event"`
+
+	// Fields are the non-empty named groups of the match.
+	Fields map[string]string `json:"fields,omitempty"`
+
+	// Line is the matched line.
+	Line string `json:"line"`
+}
+
+// compiledRule is a rule with its compiled pattern.
+type compiledRule struct {
+	event     string
+	re        *regexp.Regexp
+	threshold *Threshold
+}
+
+// below reports whether a match passes the rule's threshold, if any.
+func (r compiledRule) below(match []string) bool {
+	if r.threshold == nil {
+		return true
+	}
+	value, err := strconv.ParseFloat(match[r.re.SubexpIndex(r.threshold.Field)], 64)
+	return err == nil && value < r.threshold.Below
+}
+
+// Parser matches lines of server output against rules. It is safe for
+// concurrent use.
+type Parser struct {
+	rules []compiledRule
+}
+
+// NewParser compiles rules into a Parser.
+func NewParser(rules []Rule) (*Parser, error) {
+	p := &Parser{}
+	for n, rule := range rules {
+		re, err := rule.compile()
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", n+1, err)
+		}
+		p.rules = append(p.rules, compiledRule{event: rule.Event, re: re, threshold: rule.Threshold})
+	}
+	return p, nil
+}
+
+// Parse returns the entry for the first rule matching line, or false if no
+// rule matches.
+func (p *Parser) Parse(line string) (Entry, bool) {
+	for _, rule := range p.rules {
+		match := rule.re.FindStringSubmatch(line)
+		if match == nil || !rule.below(match) {
+			continue
+		}
+
+		entry := Entry{Event: rule.event, Line: line}
+		for n, name := range rule.re.SubexpNames() {
+			if name == "" || match[n] == "" {
+				continue
+			}
+			if entry.Fields == nil {
+				entry.Fields = make(map[string]string)
+			}
+			entry.Fields[name] = strings.TrimSpace(match[n])
+		}
+		return entry, true
+	}
+	return Entry{}, false
+}
+
+// ValidateRules checks that every rule compiles and that one of them detects
+// the server finishing its boot, which the launcher waits for.
+func ValidateRules(rules []Rule) error {
+	if _, err := NewParser(rules); err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(rules, func(r Rule) bool { return r.Event == EventReady }) {
+		return fmt.Errorf("rules must include a rule for %s", EventReady)
+	}
+	return nil
+}
+
+// LoadRules reads the rules file at path, returning the default rules if
+// there is none.
+func LoadRules(path string) ([]Rule, error) {
+	var rules []Rule
+	err := ioutil.LoadWithBackup(path, func(data []byte) error {
+		var loaded []Rule
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("failed to unmarshal server output rules: %w", err)
+		}
+		if err := ValidateRules(loaded); err != nil {
+			return err
+		}
+		rules = loaded
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return DefaultRules(), nil
+		}
+		return nil, fmt.Errorf("failed to read server output rules: %w", err)
+	}
+	return rules, nil
+}
+
+// SaveRules validates rules and writes them to path, where they replace the
+// default rules.
+func SaveRules(path string, rules []Rule) error {
+	if err := ValidateRules(rules); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal server output rules: %w", err)
+	}
+
+	if err := ioutil.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write server output rules: %w", err)
+	}
+	return nil
+}
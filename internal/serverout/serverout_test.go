@@ -0,0 +1,95 @@
+package serverout
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultRules(t *testing.T) {
+	p, err := NewParser(DefaultRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line   string
+		event  string
+		fields map[string]string
+	}{
+		{
+			line:  "[2026/01/13 17:29:39   INFO] Hytale Server Booted!",
+			event: EventReady,
+		},
+		{
+			line:   "[2026/01/13 17:29:39   INFO] [Chat] <Steve> hello there",
+			event:  EventChat,
+			fields: map[string]string{"player": "Steve", "message": "hello there"},
+		},
+		{
+			line:   "[2026/01/13 17:29:39   INFO] <Steve> player Alex has joined",
+			event:  EventChat,
+			fields: map[string]string{"player": "Steve", "message": "player Alex has joined"},
+		},
+		{
+			line:   "[2026/01/13 17:29:39   INFO] Player 'Alex' joined the world",
+			event:  EventPlayerJoined,
+			fields: map[string]string{"player": "Alex"},
+		},
+		{
+			line:   "[2026/01/13 17:29:39   WARN] Unchecked cast to Map<String> value",
+			event:  EventWarning,
+			fields: map[string]string{"message": "Unchecked cast to Map<String> value"},
+		},
+		{
+			line:   "[2026/01/13 17:29:39  SEVERE] World save failed",
+			event:  EventError,
+			fields: map[string]string{"message": "World save failed"},
+		},
+		{
+			line:   "[2026/01/13 17:29:39   INFO] TPS: 9.5",
+			event:  EventLag,
+			fields: map[string]string{"tps": "9.5"},
+		},
+		// Not chat: Java generics and stack traces outside INFO lines.
+		{line: "	at java.util.List<Item> get(List.java:12)"},
+		{line: "Caused by: java.lang.ClassCastException: <Item> cannot be cast"},
+		// Not lag: a healthy tick rate.
+		{line: "[2026/01/13 17:29:39   INFO] TPS: 30.0"},
+	}
+
+	for _, tt := range tests {
+		entry, ok := p.Parse(tt.line)
+		if tt.event == "" {
+			if ok {
+				t.Errorf("%q parsed as %s", tt.line, entry.Event)
+			}
+			continue
+		}
+		if !ok || entry.Event != tt.event {
+			t.Errorf("%q parsed as %q, want %s", tt.line, entry.Event, tt.event)
+			continue
+		}
+		for k, v := range tt.fields {
+			if entry.Fields[k] != v {
+				t.Errorf("%q: field %s = %q, want %q", tt.line, k, entry.Fields[k], v)
+			}
+		}
+	}
+}
+
+func TestValidateRulesRequiresReady(t *testing.T) {
+	rules := []Rule{{Event: EventChat, Pattern: `<(?P<player>\w+)> (?P<message>.*)`}}
+	if err := SaveRules(filepath.Join(t.TempDir(), "rules.json"), rules); err == nil {
+		t.Fatal("rules without a ready rule were saved")
+	}
+}
+
+func TestValidateRulesChecksThreshold(t *testing.T) {
+	rules := []Rule{
+		{Event: EventReady, Pattern: "Booted"},
+		{Event: EventLag, Pattern: `TPS (?P<tps>\d+)`, Threshold: &Threshold{Field: "ticks", Below: 10}},
+	}
+	if err := ValidateRules(rules); err == nil {
+		t.Fatal("threshold on a missing field accepted")
+	}
+}
@@ -0,0 +1,214 @@
+package servers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// pingTimeout is how long a single probe waits for a reply.
+	pingTimeout = 3 * time.Second
+
+	// probeVersion is a reserved QUIC version (RFC 9000 section 15) that no
+	// server implements, forcing a Version Negotiation reply.
+	probeVersion = 0x1a2a3a4a
+
+	// probeSize is the minimum datagram size a QUIC server responds to.
+	probeSize = 1200
+
+	// connIDLen is the length of the connection IDs in the probe.
+	connIDLen = 8
+)
+
+// Status is the result of probing a server.
+type Status struct {
+	// ID is the ID of the probed server.
+	ID string `json:"id"`
+
+	// Reachable is true if the server answered the probe.
+	Reachable bool `json:"reachable"`
+
+	// LatencyMs is the round-trip time of the probe in milliseconds.
+	LatencyMs int64 `json:"latency_ms"`
+
+	// Error describes why the server is unreachable, if it is.
+	Error string `json:"error,omitempty"`
+
+	// CheckedAt is when the probe completed.
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// ProbeFunc probes a server address and returns the measured round-trip time.
+type ProbeFunc func(ctx context.Context, addr string) (time.Duration, error)
+
+// Probe sends a QUIC packet with an unsupported version to addr and waits for
+// the Version Negotiation packet every QUIC server must answer with.
+// It returns the round-trip time on success.
+func Probe(ctx context.Context, addr string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	packet, scid, err := buildProbe()
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	if _, err := conn.Write(packet); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return 0, errors.New("no response from server")
+			}
+			return 0, err
+		}
+
+		if isVersionNegotiation(buf[:n], scid) {
+			return time.Since(start), nil
+		}
+	}
+}
+
+// buildProbe builds a padded QUIC long-header packet with a reserved version.
+// It returns the packet and the source connection ID the reply must echo.
+func buildProbe() ([]byte, []byte, error) {
+	ids := make([]byte, 2*connIDLen+1)
+	if _, err := rand.Read(ids); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate connection IDs: %w", err)
+	}
+	dcid, scid, first := ids[:connIDLen], ids[connIDLen:2*connIDLen], ids[2*connIDLen]
+
+	var b bytes.Buffer
+	b.WriteByte(0xc0 | first&0x3f)
+	binary.Write(&b, binary.BigEndian, uint32(probeVersion))
+	b.WriteByte(connIDLen)
+	b.Write(dcid)
+	b.WriteByte(connIDLen)
+	b.Write(scid)
+	b.Write(make([]byte, probeSize-b.Len()))
+
+	return b.Bytes(), scid, nil
+}
+
+// isVersionNegotiation reports whether p is a Version Negotiation packet
+// addressed to the given connection ID.
+func isVersionNegotiation(p, scid []byte) bool {
+	if len(p) < 6 || p[0]&0x80 == 0 {
+		return false
+	}
+	if binary.BigEndian.Uint32(p[1:5]) != 0 {
+		return false
+	}
+
+	dcidLen := int(p[5])
+	if len(p) < 6+dcidLen {
+		return false
+	}
+	return bytes.Equal(p[6:6+dcidLen], scid)
+}
+
+// Pinger probes every server in a List and keeps the latest results.
+type Pinger struct {
+	list  *List
+	probe ProbeFunc
+
+	mu       sync.RWMutex
+	statuses map[string]Status
+}
+
+// NewPinger creates a Pinger for the given list.
+// If probe is nil, Probe is used.
+func NewPinger(list *List, probe ProbeFunc) *Pinger {
+	if probe == nil {
+		probe = Probe
+	}
+	return &Pinger{
+		list:     list,
+		probe:    probe,
+		statuses: make(map[string]Status),
+	}
+}
+
+// PingAll probes all servers concurrently and returns the fresh results.
+func (p *Pinger) PingAll(ctx context.Context) map[string]Status {
+	servers := p.list.List()
+
+	var wg sync.WaitGroup
+	results := make(chan Status, len(servers))
+
+	for _, s := range servers {
+		wg.Add(1)
+		go func(s Server) {
+			defer wg.Done()
+			results <- p.ping(ctx, s)
+		}(s)
+	}
+
+	wg.Wait()
+	close(results)
+
+	statuses := make(map[string]Status, len(servers))
+	for status := range results {
+		statuses[status.ID] = status
+	}
+
+	p.mu.Lock()
+	p.statuses = statuses
+	p.mu.Unlock()
+
+	return statuses
+}
+
+// Statuses returns the results of the last PingAll.
+func (p *Pinger) Statuses() map[string]Status {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	result := make(map[string]Status, len(p.statuses))
+	for id, status := range p.statuses {
+		result[id] = status
+	}
+	return result
+}
+
+// ping probes a single server.
+func (p *Pinger) ping(ctx context.Context, s Server) Status {
+	status := Status{ID: s.ID}
+
+	rtt, err := p.probe(ctx, s.Address())
+	status.CheckedAt = time.Now()
+	if err != nil {
+		slog.Debug("server probe failed", "server", s.Address(), "error", err)
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Reachable = true
+	status.LatencyMs = rtt.Milliseconds()
+	return status
+}
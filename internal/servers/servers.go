@@ -0,0 +1,328 @@
+// Package servers provides the stored list of multiplayer servers the player
+// can join directly from the launcher, along with a reachability pinger.
+package servers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hytale-launcher/internal/ioutil"
+)
+
+// DefaultPort is the port Hytale servers listen on unless configured otherwise.
+const DefaultPort = 5520
+
+// ErrNotFound is returned when a server ID does not exist in the list.
+var ErrNotFound = errors.New("server not found")
+
+// Server is a single entry in the server list.
+type Server struct {
+	// ID uniquely identifies the entry.
+	ID string `json:"id"`
+
+	// Name is the display name chosen by the user.
+	Name string `json:"name"`
+
+	// Host is the hostname or IP address of the server.
+	Host string `json:"host"`
+
+	// Port is the server port.
+	Port int `json:"port"`
+
+	// Notes is free-form text attached by the user.
+	Notes string `json:"notes,omitempty"`
+
+	// Favourite marks the entry as pinned to the top of the list.
+	Favourite bool `json:"favourite,omitempty"`
+
+	// LastJoined is when the player last launched the game into this server.
+	LastJoined *time.Time `json:"last_joined,omitempty"`
+
+	// AddedAt is when the entry was created.
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Address returns the host:port address of the server.
+func (s *Server) Address() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// validate normalizes and checks the user-editable fields of the server.
+func (s *Server) validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Host = strings.TrimSpace(s.Host)
+
+	if s.Host == "" {
+		return errors.New("server host is required")
+	}
+	if strings.ContainsAny(s.Host, " /\\") {
+		return fmt.Errorf("invalid server host %q", s.Host)
+	}
+	if s.Port == 0 {
+		s.Port = DefaultPort
+	}
+	if s.Port < 1 || s.Port > 65535 {
+		return fmt.Errorf("invalid server port %d", s.Port)
+	}
+	if s.Name == "" {
+		s.Name = s.Host
+	}
+	return nil
+}
+
+// List manages the stored server list.
+type List struct {
+	servers  []*Server
+	filePath string
+	mu       sync.RWMutex
+}
+
+// New creates a new List with the given storage file path.
+func New(filePath string) *List {
+	return &List{
+		filePath: filePath,
+	}
+}
+
+// Load loads the server list from disk.
+func (l *List) Load() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var servers []*Server
+	err := ioutil.LoadWithBackup(l.filePath, func(data []byte) error {
+		var loaded []*Server
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("failed to unmarshal server list: %w", err)
+		}
+		servers = loaded
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read server list: %w", err)
+	}
+
+	l.servers = servers
+	return nil
+}
+
+// saveLocked saves the server list without acquiring the lock.
+// Caller must hold l.mu.
+func (l *List) saveLocked() error {
+	if err := os.MkdirAll(filepath.Dir(l.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(l.servers, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal server list: %w", err)
+	}
+
+	if err := ioutil.WriteFileAtomic(l.filePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write server list: %w", err)
+	}
+
+	return nil
+}
+
+// findLocked returns the index of the server with the given ID, or -1.
+// Caller must hold l.mu.
+func (l *List) findLocked(id string) int {
+	return slices.IndexFunc(l.servers, func(s *Server) bool {
+		return s.ID == id
+	})
+}
+
+// List returns a copy of all servers. Favourites come first, followed by the
+// most recently joined servers and then by name.
+func (l *List) List() []Server {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	result := make([]Server, 0, len(l.servers))
+	for _, s := range l.servers {
+		result = append(result, *s)
+	}
+
+	slices.SortStableFunc(result, func(a, b Server) int {
+		if a.Favourite != b.Favourite {
+			if a.Favourite {
+				return -1
+			}
+			return 1
+		}
+		if ta, tb := joinedAt(a), joinedAt(b); !ta.Equal(tb) {
+			return tb.Compare(ta)
+		}
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+
+	return result
+}
+
+// Get returns a copy of the server with the given ID.
+func (l *List) Get(id string) (Server, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	idx := l.findLocked(id)
+	if idx < 0 {
+		return Server{}, ErrNotFound
+	}
+	return *l.servers[idx], nil
+}
+
+// Add validates and stores a new server entry, assigning it a fresh ID.
+func (l *List) Add(s Server) (Server, error) {
+	if err := s.validate(); err != nil {
+		return Server{}, err
+	}
+
+	s.ID = uuid.NewString()
+	s.AddedAt = time.Now()
+	s.LastJoined = nil
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.servers = append(l.servers, &s)
+	if err := l.saveLocked(); err != nil {
+		return Server{}, err
+	}
+	return s, nil
+}
+
+// Update replaces the user-editable fields of an existing entry.
+func (l *List) Update(s Server) (Server, error) {
+	if err := s.validate(); err != nil {
+		return Server{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	idx := l.findLocked(s.ID)
+	if idx < 0 {
+		return Server{}, ErrNotFound
+	}
+
+	existing := l.servers[idx]
+	existing.Name = s.Name
+	existing.Host = s.Host
+	existing.Port = s.Port
+	existing.Notes = s.Notes
+	existing.Favourite = s.Favourite
+
+	if err := l.saveLocked(); err != nil {
+		return Server{}, err
+	}
+	return *existing, nil
+}
+
+// Import adds servers from another launcher. A server whose address is
+// already listed is a conflict: the existing entry is kept unless replace is
+// set, in which case its user-editable fields are overwritten. The addresses
+// of conflicting servers are returned.
+func (l *List) Import(servers []Server, replace bool) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var conflicts []string
+	for _, s := range servers {
+		if err := s.validate(); err != nil {
+			slog.Warn("skipping invalid imported server", "server", s.Name, "error", err)
+			continue
+		}
+
+		idx := slices.IndexFunc(l.servers, func(existing *Server) bool {
+			return strings.EqualFold(existing.Address(), s.Address())
+		})
+		if idx < 0 {
+			s.ID = uuid.NewString()
+			if s.AddedAt.IsZero() {
+				s.AddedAt = time.Now()
+			}
+			l.servers = append(l.servers, &s)
+			continue
+		}
+
+		existing := l.servers[idx]
+		if existing.Name == s.Name && existing.Notes == s.Notes && existing.Favourite == s.Favourite {
+			continue
+		}
+		conflicts = append(conflicts, s.Address())
+		if replace {
+			existing.Name = s.Name
+			existing.Notes = s.Notes
+			existing.Favourite = s.Favourite
+		}
+	}
+
+	return conflicts, l.saveLocked()
+}
+
+// Remove deletes the server with the given ID.
+func (l *List) Remove(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	idx := l.findLocked(id)
+	if idx < 0 {
+		return ErrNotFound
+	}
+
+	l.servers = slices.Delete(l.servers, idx, idx+1)
+	return l.saveLocked()
+}
+
+// SetFavourite marks or unmarks a server as favourite.
+func (l *List) SetFavourite(id string, favourite bool) error {
+	return l.modify(id, func(s *Server) {
+		s.Favourite = favourite
+	})
+}
+
+// MarkJoined records that the player has just joined the given server.
+func (l *List) MarkJoined(id string) error {
+	return l.modify(id, func(s *Server) {
+		now := time.Now()
+		s.LastJoined = &now
+	})
+}
+
+// modify applies fn to the server with the given ID and saves the list.
+func (l *List) modify(id string, fn func(s *Server)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	idx := l.findLocked(id)
+	if idx < 0 {
+		return ErrNotFound
+	}
+
+	fn(l.servers[idx])
+	return l.saveLocked()
+}
+
+// joinedAt returns the last joined time of a server, or the zero time.
+func joinedAt(s Server) time.Time {
+	if s.LastJoined == nil {
+		return time.Time{}
+	}
+	return *s.LastJoined
+}
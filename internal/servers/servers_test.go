@@ -0,0 +1,105 @@
+package servers
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// startQUICStandIn starts a UDP listener that answers QUIC long-header
+// packets with a Version Negotiation packet, as a Hytale server does, and
+// returns its host and port.
+func startQUICStandIn(t *testing.T) (string, int) {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			p := buf[:n]
+			if n < 7 || p[0]&0x80 == 0 {
+				continue
+			}
+			dcid := p[6 : 6+int(p[5])]
+			rest := p[6+len(dcid):]
+			scid := rest[1 : 1+int(rest[0])]
+
+			// The reply swaps the connection IDs and lists QUIC v1.
+			reply := []byte{0x80, 0, 0, 0, 0, byte(len(scid))}
+			reply = append(reply, scid...)
+			reply = append(reply, byte(len(dcid)))
+			reply = append(reply, dcid...)
+			reply = binary.BigEndian.AppendUint32(reply, 1)
+			conn.WriteTo(reply, addr)
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, _ := strconv.Atoi(port)
+	return host, n
+}
+
+// closedUDPPort returns a local UDP port nothing listens on.
+func closedUDPPort(t *testing.T) int {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := conn.LocalAddr().(*net.UDPAddr).Port
+	conn.Close()
+	return port
+}
+
+func TestPingAndJoin(t *testing.T) {
+	host, port := startQUICStandIn(t)
+
+	list := New(filepath.Join(t.TempDir(), "servers.json"))
+	up, err := list.Add(Server{Name: "Up", Host: host, Port: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	down, err := list.Add(Server{Name: "Down", Host: "127.0.0.1", Port: closedUDPPort(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := NewPinger(list, nil).PingAll(context.Background())
+	if s := statuses[up.ID]; !s.Reachable || s.Error != "" {
+		t.Fatalf("stand-in status = %+v, want reachable", s)
+	}
+	if s := statuses[down.ID]; s.Reachable {
+		t.Fatalf("closed port status = %+v, want unreachable", s)
+	}
+
+	// Joining moves a server ahead of ones never joined, and is saved.
+	if err := list.MarkJoined(down.ID); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := New(list.filePath)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	servers := reloaded.List()
+	if len(servers) != 2 || servers[0].ID != down.ID || servers[0].LastJoined == nil {
+		t.Fatalf("servers after join = %+v", servers)
+	}
+	if time.Since(*servers[0].LastJoined) > time.Minute {
+		t.Fatalf("last joined = %v", servers[0].LastJoined)
+	}
+}
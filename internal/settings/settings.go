@@ -0,0 +1,226 @@
+// Package settings provides the persistent, typed launcher settings store.
+// Settings are kept as versioned JSON in the storage directory, validated on
+// every change, and broadcast to subscribers so they apply without a restart.
+package settings
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"hytale-launcher/internal/logging"
+	"hytale-launcher/internal/notifications"
+	"hytale-launcher/internal/playerprofile"
+)
+
+// SchemaVersion is the current version of the settings file format.
+const SchemaVersion = 1
+
+// Close behaviours control what happens when the launcher window is closed.
+const (
+	CloseExit     = "exit"
+	CloseMinimize = "minimize"
+)
+
+// Launch behaviours control what the launcher does after starting the game.
+const (
+	LaunchMinimize = "minimize"
+	LaunchKeepOpen = "keep_open"
+	LaunchClose    = "close"
+)
+
+// Keyring modes select where launcher secrets are stored.
+const (
+	// KeyringAuto uses the OS keyring when it is usable and the secrets
+	// file otherwise.
+	KeyringAuto = "auto"
+
+	// KeyringSystem always uses the OS keyring.
+	KeyringSystem = "system"
+
+	// KeyringFile keeps secrets in a passphrase-protected file.
+	KeyringFile = "file"
+
+	// KeyringDisabled never touches the OS keyring.
+	KeyringDisabled = "disabled"
+)
+
+// Log formats select how launcher log lines are written.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Settings is the full set of user-configurable launcher settings.
+type Settings struct {
+	// Version is the schema version the settings were written with.
+	Version int `json:"version"`
+
+	// Language is the UI language tag (e.g., "en" or "pt-BR").
+	Language string `json:"language"`
+
+	// CloseBehaviour is what happens when the launcher window is closed.
+	CloseBehaviour string `json:"close_behaviour"`
+
+	// LaunchBehaviour is what the launcher does once the game has started.
+	LaunchBehaviour string `json:"launch_behaviour"`
+
+	// DownloadLimitKBps caps download speed in KiB/s. Zero means unlimited.
+	DownloadLimitKBps int `json:"download_limit_kbps"`
+
+	// StorageDir overrides the storage directory. Empty means the default.
+	StorageDir string `json:"storage_dir,omitempty"`
+
+	// Keyring selects where launcher secrets are stored.
+	Keyring string `json:"keyring"`
+
+	// Telemetry enables crash and error reporting.
+	Telemetry bool `json:"telemetry"`
+
+	// DebugLogging enables debug-level launcher logs.
+	DebugLogging bool `json:"debug_logging"`
+
+	// LogFormat is the launcher log output format.
+	LogFormat string `json:"log_format"`
+
+	// LogLevels overrides the log level per package, as
+	// "download=debug,auth=warn".
+	LogLevels string `json:"log_levels,omitempty"`
+
+	// LogRetention is how many rotated launcher logs to keep.
+	LogRetention int `json:"log_retention"`
+
+	// SnapshotRetention is how many UserData snapshots to keep. Zero
+	// disables automatic snapshots.
+	SnapshotRetention int `json:"snapshot_retention"`
+
+	// OfflineUUIDScheme is how UUIDs of new offline player profiles are
+	// derived.
+	OfflineUUIDScheme string `json:"offline_uuid_scheme"`
+
+	// NotificationRoutes selects the notification backends per event, as
+	// "server:crashed=desktop+webhook,*=desktop".
+	NotificationRoutes string `json:"notification_routes"`
+
+	// NotificationWebhookURL is where the webhook backend posts
+	// notifications. Empty disables it.
+	NotificationWebhookURL string `json:"notification_webhook_url"`
+}
+
+// Defaults returns the settings used when no settings file exists.
+func Defaults() Settings {
+	return Settings{
+		Version:            SchemaVersion,
+		Language:           "en",
+		CloseBehaviour:     CloseExit,
+		LaunchBehaviour:    LaunchMinimize,
+		Keyring:            KeyringAuto,
+		Telemetry:          true,
+		SnapshotRetention:  10,
+		LogFormat:          LogFormatText,
+		LogRetention:       logging.DefaultRetention,
+		OfflineUUIDScheme:  playerprofile.DefaultScheme,
+		NotificationRoutes: notifications.DefaultRoutes,
+	}
+}
+
+// ValidationError describes a setting that failed validation.
+type ValidationError struct {
+	// Field is the JSON name of the offending setting.
+	Field string `json:"field"`
+
+	// Message explains why the value was rejected.
+	Message string `json:"message"`
+}
+
+// Error returns the error message for ValidationError.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid setting %q: %s", e.Field, e.Message)
+}
+
+// languagePattern matches simple BCP 47 language tags.
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// maxDownloadLimitKBps is the largest accepted download limit (1 GiB/s).
+const maxDownloadLimitKBps = 1024 * 1024
+
+// maxSnapshotRetention is the largest number of snapshots that may be kept.
+const maxSnapshotRetention = 100
+
+// maxLogRetention is the largest number of rotated logs that may be kept.
+const maxLogRetention = 50
+
+// Validate checks every setting and returns the first ValidationError found.
+func (s *Settings) Validate() error {
+	if !languagePattern.MatchString(s.Language) {
+		return &ValidationError{Field: "language", Message: fmt.Sprintf("%q is not a language tag", s.Language)}
+	}
+
+	if !slices.Contains([]string{CloseExit, CloseMinimize}, s.CloseBehaviour) {
+		return &ValidationError{Field: "close_behaviour", Message: fmt.Sprintf("unknown behaviour %q", s.CloseBehaviour)}
+	}
+
+	if !slices.Contains([]string{LaunchMinimize, LaunchKeepOpen, LaunchClose}, s.LaunchBehaviour) {
+		return &ValidationError{Field: "launch_behaviour", Message: fmt.Sprintf("unknown behaviour %q", s.LaunchBehaviour)}
+	}
+
+	if s.DownloadLimitKBps < 0 || s.DownloadLimitKBps > maxDownloadLimitKBps {
+		return &ValidationError{Field: "download_limit_kbps", Message: fmt.Sprintf("must be between 0 and %d", maxDownloadLimitKBps)}
+	}
+
+	if s.StorageDir != "" && !filepath.IsAbs(s.StorageDir) {
+		return &ValidationError{Field: "storage_dir", Message: "must be an absolute path"}
+	}
+
+	if !slices.Contains([]string{KeyringAuto, KeyringSystem, KeyringFile, KeyringDisabled}, s.Keyring) {
+		return &ValidationError{Field: "keyring", Message: fmt.Sprintf("unknown keyring mode %q", s.Keyring)}
+	}
+
+	if s.SnapshotRetention < 0 || s.SnapshotRetention > maxSnapshotRetention {
+		return &ValidationError{Field: "snapshot_retention", Message: fmt.Sprintf("must be between 0 and %d", maxSnapshotRetention)}
+	}
+
+	if !slices.Contains([]string{LogFormatText, LogFormatJSON}, s.LogFormat) {
+		return &ValidationError{Field: "log_format", Message: fmt.Sprintf("unknown log format %q", s.LogFormat)}
+	}
+
+	if _, err := logging.ParseLevels(s.LogLevels); err != nil {
+		return &ValidationError{Field: "log_levels", Message: err.Error()}
+	}
+
+	if s.LogRetention < 1 || s.LogRetention > maxLogRetention {
+		return &ValidationError{Field: "log_retention", Message: fmt.Sprintf("must be between 1 and %d", maxLogRetention)}
+	}
+
+	if !playerprofile.ValidScheme(s.OfflineUUIDScheme) {
+		return &ValidationError{Field: "offline_uuid_scheme", Message: fmt.Sprintf("unknown UUID scheme %q", s.OfflineUUIDScheme)}
+	}
+
+	if _, err := notifications.ParseRoutes(s.NotificationRoutes); err != nil {
+		return &ValidationError{Field: "notification_routes", Message: err.Error()}
+	}
+
+	if s.NotificationWebhookURL != "" {
+		u, err := url.Parse(s.NotificationWebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &ValidationError{Field: "notification_webhook_url", Message: "must be an http or https URL"}
+		}
+	}
+
+	return nil
+}
+
+// normalize trims user-entered strings before validation.
+func (s *Settings) normalize() {
+	s.Language = strings.TrimSpace(s.Language)
+	s.StorageDir = strings.TrimSpace(s.StorageDir)
+	s.LogLevels = strings.TrimSpace(s.LogLevels)
+	s.NotificationRoutes = strings.TrimSpace(s.NotificationRoutes)
+	s.NotificationWebhookURL = strings.TrimSpace(s.NotificationWebhookURL)
+	if s.StorageDir != "" {
+		s.StorageDir = filepath.Clean(s.StorageDir)
+	}
+}
@@ -0,0 +1,316 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"hytale-launcher/internal/ioutil"
+	"hytale-launcher/internal/throttle"
+)
+
+// SubscriberFunc is called after settings change with the old and new values.
+type SubscriberFunc func(old, new Settings)
+
+// Store holds the current settings and persists them to disk.
+type Store struct {
+	filePath string
+
+	mu      sync.RWMutex
+	current Settings
+	modTime time.Time
+
+	subMu  sync.Mutex
+	subs   map[int]SubscriberFunc
+	nextID int
+
+	watcher *throttle.Refresher
+}
+
+// NewStore creates a Store backed by the given file, initialized to Defaults.
+func NewStore(filePath string) *Store {
+	return &Store{
+		filePath: filePath,
+		current:  Defaults(),
+		subs:     make(map[int]SubscriberFunc),
+	}
+}
+
+// Load reads the settings file. A missing file leaves the defaults in place.
+// Subscribers are notified if the loaded settings differ from the current ones.
+func (s *Store) Load() error {
+	// A file from a newer launcher is not corrupt, so its backup is not
+	// loaded in its place.
+	var loaded Settings
+	var newerErr error
+	err := ioutil.LoadWithBackup(s.filePath, func(data []byte) error {
+		settings, err := decode(data)
+		if errors.Is(err, errNewerVersion) {
+			newerErr = err
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		loaded = settings
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read settings file: %w", err)
+	}
+	if newerErr != nil {
+		return newerErr
+	}
+
+	var modTime time.Time
+	if info, err := os.Stat(s.filePath); err == nil {
+		modTime = info.ModTime()
+	}
+
+	s.mu.Lock()
+	old := s.current
+	s.current = loaded
+	s.modTime = modTime
+	s.mu.Unlock()
+
+	if old != loaded {
+		s.notify(old, loaded)
+	}
+	return nil
+}
+
+// errNewerVersion is returned for settings files written by a newer launcher.
+var errNewerVersion = errors.New("settings file is newer than supported")
+
+// decode parses a settings file, filling in defaults for missing fields and
+// upgrading older schema versions.
+func decode(data []byte) (Settings, error) {
+	settings := Defaults()
+	settings.Version = 0
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return Settings{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	if settings.Version > SchemaVersion {
+		return Settings{}, fmt.Errorf("%w: file version %d, supported version %d", errNewerVersion, settings.Version, SchemaVersion)
+	}
+	settings.Version = SchemaVersion
+
+	settings.normalize()
+	if err := settings.Validate(); err != nil {
+		return Settings{}, err
+	}
+	return settings, nil
+}
+
+// Get returns a copy of the current settings.
+func (s *Store) Get() Settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// Update validates and stores a complete set of settings.
+func (s *Store) Update(settings Settings) error {
+	return s.apply(func(Settings) (Settings, error) {
+		return settings, nil
+	})
+}
+
+// Set changes a single setting identified by its JSON name.
+// The value must be JSON-compatible with the setting's type.
+func (s *Store) Set(key string, value any) error {
+	return s.apply(func(current Settings) (Settings, error) {
+		return withField(current, key, value)
+	})
+}
+
+// Reset restores all settings to their defaults.
+func (s *Store) Reset() error {
+	return s.Update(Defaults())
+}
+
+// apply computes new settings from the current ones, validates, saves and
+// notifies subscribers.
+func (s *Store) apply(fn func(current Settings) (Settings, error)) error {
+	s.mu.Lock()
+
+	old := s.current
+	updated, err := fn(old)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	updated.Version = SchemaVersion
+	updated.normalize()
+	if err := updated.Validate(); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	if err := s.saveLocked(updated); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.current = updated
+	s.mu.Unlock()
+
+	if old != updated {
+		s.notify(old, updated)
+	}
+	return nil
+}
+
+// saveLocked writes settings to disk without acquiring the lock.
+// Caller must hold s.mu.
+func (s *Store) saveLocked(settings Settings) error {
+	if err := os.MkdirAll(filepath.Dir(s.filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	if err := ioutil.WriteFileAtomic(s.filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+
+	if info, err := os.Stat(s.filePath); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// withField returns a copy of settings with the JSON field key set to value.
+func withField(settings Settings, key string, value any) (Settings, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return Settings{}, err
+	}
+
+	if !fieldNames()[key] {
+		return Settings{}, &ValidationError{Field: key, Message: "unknown setting"}
+	}
+	if key == "version" {
+		return Settings{}, &ValidationError{Field: key, Message: "setting is read-only"}
+	}
+	fields[key] = value
+
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return Settings{}, &ValidationError{Field: key, Message: err.Error()}
+	}
+
+	var updated Settings
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&updated); err != nil {
+		return Settings{}, &ValidationError{Field: key, Message: "value has the wrong type"}
+	}
+	return updated, nil
+}
+
+// fieldNames returns the JSON names of all settings, including those
+// omitted from JSON when empty.
+var fieldNames = sync.OnceValue(func() map[string]bool {
+	names := make(map[string]bool)
+	t := reflect.TypeFor[Settings]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+})
+
+// Subscribe registers fn to be called whenever settings change.
+// The returned function removes the subscription.
+func (s *Store) Subscribe(fn SubscriberFunc) func() {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subs[id] = fn
+
+	return func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		delete(s.subs, id)
+	}
+}
+
+// notify calls every subscriber with the old and new settings.
+func (s *Store) notify(old, updated Settings) {
+	s.subMu.Lock()
+	subs := make([]SubscriberFunc, 0, len(s.subs))
+	for _, fn := range s.subs {
+		subs = append(subs, fn)
+	}
+	s.subMu.Unlock()
+
+	for _, fn := range subs {
+		fn(old, updated)
+	}
+}
+
+// Watch starts polling the settings file for external edits, reloading it
+// when its modification time changes.
+func (s *Store) Watch(interval time.Duration) {
+	s.watcher = throttle.NewRefresher(s.reloadIfChanged)
+	s.watcher.Start(interval)
+}
+
+// StopWatching stops polling the settings file.
+func (s *Store) StopWatching() {
+	if s.watcher != nil {
+		s.watcher.Stop()
+		s.watcher = nil
+	}
+}
+
+// reloadIfChanged reloads the settings file if it was modified on disk.
+func (s *Store) reloadIfChanged() error {
+	info, err := os.Stat(s.filePath)
+	if err != nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	unchanged := info.ModTime().Equal(s.modTime)
+	s.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	slog.Info("settings file changed on disk, reloading", "path", s.filePath)
+	if err := s.Load(); err != nil {
+		// Remember the bad file so it is not reported on every poll.
+		s.mu.Lock()
+		s.modTime = info.ModTime()
+		s.mu.Unlock()
+		return fmt.Errorf("failed to reload settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package settings
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"hytale-launcher/internal/notifications"
+	"hytale-launcher/internal/playerprofile"
+)
+
+func TestSetEveryField(t *testing.T) {
+	changed := Settings{
+		Language:               "de",
+		CloseBehaviour:         CloseMinimize,
+		LaunchBehaviour:        LaunchClose,
+		DownloadLimitKBps:      512,
+		StorageDir:             filepath.Join(t.TempDir(), "storage"),
+		Keyring:                KeyringFile,
+		Telemetry:              false,
+		DebugLogging:           true,
+		LogFormat:              LogFormatJSON,
+		LogLevels:              "download=debug",
+		LogRetention:           3,
+		SnapshotRetention:      5,
+		OfflineUUIDScheme:      playerprofile.SchemeRandom,
+		NotificationRoutes:     "*=" + notifications.BackendLog,
+		NotificationWebhookURL: "https://example.com/hook",
+	}
+	data, err := json.Marshal(changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		t.Fatal(err)
+	}
+
+	for name := range fieldNames() {
+		if name == "version" {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			value, ok := values[name]
+			if !ok {
+				t.Fatalf("no test value for setting %q", name)
+			}
+
+			s := NewStore(filepath.Join(t.TempDir(), "settings.json"))
+			if err := s.Set(name, value); err != nil {
+				t.Fatalf("Set(%q, %v): %v", name, value, err)
+			}
+
+			data, err := json.Marshal(s.Get())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got[name] != value {
+				t.Fatalf("%s = %v, want %v", name, got[name], value)
+			}
+		})
+	}
+}
+
+func TestSetRejects(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "settings.json"))
+
+	if err := s.Set("no_such_setting", true); err == nil {
+		t.Fatal("unknown setting was accepted")
+	}
+	if err := s.Set("version", 2); err == nil {
+		t.Fatal("version was changed")
+	}
+	if err := s.Set("log_levels", "download=loud"); err == nil {
+		t.Fatal("invalid log level was accepted")
+	}
+}
@@ -0,0 +1,248 @@
+package skins
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"hytale-launcher/internal/ioutil"
+)
+
+// Library is the stored skin library.
+type Library struct {
+	// dir holds index.json, the item files and their previews.
+	dir string
+
+	mu    sync.RWMutex
+	items map[string]*Skin
+}
+
+// NewLibrary creates a Library stored in dir.
+func NewLibrary(dir string) *Library {
+	return &Library{
+		dir:   dir,
+		items: make(map[string]*Skin),
+	}
+}
+
+// indexPath returns the path of the stored index.
+func (l *Library) indexPath() string {
+	return filepath.Join(l.dir, "index.json")
+}
+
+// previewPath returns the path of an item's preview.
+func (l *Library) previewPath(id string) string {
+	return filepath.Join(l.dir, "previews", id+".png")
+}
+
+// Load reads the stored index.
+func (l *Library) Load() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var items []*Skin
+	err := ioutil.LoadWithBackup(l.indexPath(), func(data []byte) error {
+		var loaded []*Skin
+		if err := json.Unmarshal(data, &loaded); err != nil {
+			return fmt.Errorf("failed to unmarshal skin library: %w", err)
+		}
+		items = loaded
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read skin library: %w", err)
+	}
+
+	l.items = make(map[string]*Skin, len(items))
+	for _, item := range items {
+		l.items[item.ID] = item
+	}
+	return nil
+}
+
+// saveLocked saves the index without acquiring the lock.
+// Caller must hold l.mu.
+func (l *Library) saveLocked() error {
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(l.listLocked(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal skin library: %w", err)
+	}
+
+	if err := ioutil.WriteFileAtomic(l.indexPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write skin library: %w", err)
+	}
+	return nil
+}
+
+// listLocked returns copies of all items sorted by name.
+// Caller must hold l.mu.
+func (l *Library) listLocked() []Skin {
+	result := make([]Skin, 0, len(l.items))
+	for _, item := range l.items {
+		result = append(result, *item)
+	}
+	slices.SortFunc(result, func(a, b Skin) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return result
+}
+
+// List returns all items sorted by name.
+func (l *Library) List() []Skin {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.listLocked()
+}
+
+// Get returns a copy of the item with the given ID.
+func (l *Library) Get(id string) (Skin, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	item, ok := l.items[id]
+	if !ok {
+		return Skin{}, ErrNotFound
+	}
+	return *item, nil
+}
+
+// Path returns the stored file of an item.
+func (l *Library) Path(id string) (string, error) {
+	item, err := l.Get(id)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, item.FileName()), nil
+}
+
+// Preview returns an item's preview PNG, or nil for presets.
+func (l *Library) Preview(id string) ([]byte, error) {
+	item, err := l.Get(id)
+	if err != nil || item.Kind != KindSkin {
+		return nil, err
+	}
+	return os.ReadFile(l.previewPath(id))
+}
+
+// Import validates a skin texture or cosmetic preset and copies it into the
+// library. Importing a file already in the library returns the existing item.
+// An empty name uses the file name.
+func (l *Library) Import(path, name string) (*Skin, error) {
+	kind, err := kindOf(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Read one byte past the limit so oversized files are detected.
+	limit := int64(max(MaxSkinSize, MaxPresetSize))
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read skin: %w", err)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	sum := sha256.Sum256(data)
+	skin := &Skin{
+		ID:         hex.EncodeToString(sum[:8]),
+		Kind:       kind,
+		Name:       name,
+		Size:       int64(len(data)),
+		ImportedAt: time.Now(),
+	}
+	if err := validate(kind, data, skin); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if existing, ok := l.items[skin.ID]; ok {
+		copied := *existing
+		return &copied, nil
+	}
+
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := ioutil.WriteFileAtomic(filepath.Join(l.dir, skin.FileName()), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to store skin: %w", err)
+	}
+	if kind == KindSkin {
+		l.writePreview(skin.ID, data)
+	}
+
+	l.items[skin.ID] = skin
+	if err := l.saveLocked(); err != nil {
+		delete(l.items, skin.ID)
+		return nil, err
+	}
+
+	copied := *skin
+	return &copied, nil
+}
+
+// writePreview stores the preview of a skin. A missing preview only affects
+// the library view, so failures are logged.
+func (l *Library) writePreview(id string, data []byte) {
+	preview, err := makePreview(data)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(l.previewPath(id)), 0755)
+	}
+	if err == nil {
+		err = ioutil.WriteFileAtomic(l.previewPath(id), preview, 0644)
+	}
+	if err != nil {
+		slog.Warn("failed to create skin preview", "id", id, "error", err)
+	}
+}
+
+// Delete removes an item and its files from the library.
+func (l *Library) Delete(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	item, ok := l.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	delete(l.items, id)
+	if err := l.saveLocked(); err != nil {
+		l.items[id] = item
+		return err
+	}
+
+	for _, path := range []string{filepath.Join(l.dir, item.FileName()), l.previewPath(id)} {
+		if err := ioutil.RemoveWithBackup(path); err != nil {
+			slog.Warn("failed to delete skin file", "path", path, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package skins keeps a local library of player skins and cosmetic presets
+// that offline player profiles can select.
+package skins
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"math/bits"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Kinds of library items.
+const (
+	// KindSkin is a PNG skin texture.
+	KindSkin = "skin"
+
+	// KindPreset is a JSON cosmetic preset.
+	KindPreset = "preset"
+)
+
+// Size limits for imported files.
+const (
+	MaxSkinSize   = 2 << 20
+	MaxPresetSize = 64 << 10
+
+	// MinSkinDim and MaxSkinDim bound the width and height of a skin.
+	MinSkinDim = 32
+	MaxSkinDim = 1024
+)
+
+// extensions maps each kind to the extension its files are stored with.
+var extensions = map[string]string{
+	KindSkin:   ".png",
+	KindPreset: ".json",
+}
+
+// ErrNotFound is returned when an item is not in the library.
+var ErrNotFound = errors.New("skin not found")
+
+// Skin is a skin or cosmetic preset in the library.
+type Skin struct {
+	// ID identifies the item and is derived from its contents.
+	ID string `json:"id"`
+
+	// Kind is KindSkin or KindPreset.
+	Kind string `json:"kind"`
+
+	// Name is the display name.
+	Name string `json:"name"`
+
+	// Size is the file size in bytes.
+	Size int64 `json:"size"`
+
+	// Width and Height are the texture dimensions of a skin.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+
+	// ImportedAt is when the item was added to the library.
+	ImportedAt time.Time `json:"imported_at"`
+}
+
+// FileName returns the name the item is stored under.
+func (s *Skin) FileName() string {
+	return s.ID + extensions[s.Kind]
+}
+
+// kindOf returns the kind of a file from its extension.
+func kindOf(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return KindSkin, nil
+	case ".json":
+		return KindPreset, nil
+	default:
+		return "", errors.New("skins must be .png textures or .json cosmetic presets")
+	}
+}
+
+// validate checks the contents of a file of the given kind and fills in the
+// item's dimensions.
+func validate(kind string, data []byte, skin *Skin) error {
+	switch kind {
+	case KindSkin:
+		return validateSkin(data, skin)
+	case KindPreset:
+		return validatePreset(data)
+	default:
+		return fmt.Errorf("unknown skin kind %q", kind)
+	}
+}
+
+// validateSkin checks that data is a PNG with power-of-two dimensions
+// within the allowed range.
+func validateSkin(data []byte, skin *Skin) error {
+	if len(data) > MaxSkinSize {
+		return fmt.Errorf("skin is larger than %d KiB", MaxSkinSize>>10)
+	}
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("skin is not a valid PNG: %w", err)
+	}
+	for _, dim := range []int{cfg.Width, cfg.Height} {
+		if dim < MinSkinDim || dim > MaxSkinDim || bits.OnesCount(uint(dim)) != 1 {
+			return fmt.Errorf("skin is %dx%d; width and height must be powers of two from %d to %d",
+				cfg.Width, cfg.Height, MinSkinDim, MaxSkinDim)
+		}
+	}
+
+	// Decode fully so truncated files are rejected.
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("skin is not a valid PNG: %w", err)
+	}
+
+	skin.Width, skin.Height = cfg.Width, cfg.Height
+	return nil
+}
+
+// validatePreset checks that data is a JSON object.
+func validatePreset(data []byte) error {
+	if len(data) > MaxPresetSize {
+		return fmt.Errorf("cosmetic preset is larger than %d KiB", MaxPresetSize>>10)
+	}
+
+	var preset map[string]any
+	if err := json.Unmarshal(data, &preset); err != nil {
+		return fmt.Errorf("cosmetic preset is not a JSON object: %w", err)
+	}
+	return nil
+}
+
+// previewSize is the longest side of generated previews in pixels.
+const previewSize = 256
+
+// makePreview scales a skin texture up with nearest-neighbour sampling so
+// its pixels stay sharp, and returns it as PNG.
+func makePreview(data []byte) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	src := img.Bounds()
+	factor := max(previewSize/max(src.Dx(), src.Dy()), 1)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, src.Dx()*factor, src.Dy()*factor))
+	for y := 0; y < dst.Bounds().Dy(); y++ {
+		for x := 0; x < dst.Bounds().Dx(); x++ {
+			dst.Set(x, y, img.At(src.Min.X+x/factor, src.Min.Y+y/factor))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
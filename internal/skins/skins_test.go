@@ -0,0 +1,201 @@
+package skins
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testSkin returns a w×h PNG whose left half is red and right half blue.
+func testSkin(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidate(t *testing.T) {
+	skin := testSkin(t, 64, 32)
+	tests := []struct {
+		name string
+		kind string
+		data []byte
+		want string
+	}{
+		{"skin", KindSkin, skin, ""},
+		{"largest skin", KindSkin, testSkin(t, MaxSkinDim, MaxSkinDim), ""},
+		{"not a png", KindSkin, []byte("GIF89a"), "not a valid PNG"},
+		{"truncated", KindSkin, skin[:len(skin)-20], "not a valid PNG"},
+		{"too small", KindSkin, testSkin(t, 16, 16), "powers of two"},
+		{"too large", KindSkin, testSkin(t, 2*MaxSkinDim, MaxSkinDim), "powers of two"},
+		{"not a power of two", KindSkin, testSkin(t, 64, 48), "powers of two"},
+		{"oversized file", KindSkin, make([]byte, MaxSkinSize+1), "larger than"},
+		{"preset", KindPreset, []byte(`{"hair": "braids"}`), ""},
+		{"preset array", KindPreset, []byte(`["hair"]`), "not a JSON object"},
+		{"preset syntax", KindPreset, []byte(`{"hair":`), "not a JSON object"},
+		{"oversized preset", KindPreset, []byte(`{"x": "` + strings.Repeat("a", MaxPresetSize) + `"}`), "larger than"},
+		{"unknown kind", "cape", skin, "unknown skin kind"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Skin
+			err := validate(tt.kind, tt.data, &s)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("validate = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSetsDimensions(t *testing.T) {
+	var s Skin
+	if err := validate(KindSkin, testSkin(t, 64, 32), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Width != 64 || s.Height != 32 {
+		t.Fatalf("dimensions = %dx%d, want 64x32", s.Width, s.Height)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	for path, want := range map[string]string{
+		"steve.png":   KindSkin,
+		"STEVE.PNG":   KindSkin,
+		"outfit.json": KindPreset,
+	} {
+		if got, err := kindOf(path); err != nil || got != want {
+			t.Errorf("kindOf(%s) = %q, %v, want %q", path, got, err, want)
+		}
+	}
+	if _, err := kindOf("steve.jpg"); err == nil {
+		t.Error("kindOf accepted a .jpg")
+	}
+}
+
+func TestMakePreview(t *testing.T) {
+	tests := []struct {
+		w, h          int
+		wantW, wantH  int
+		wantBoundaryX int
+	}{
+		{64, 32, 256, 128, 128},
+		{32, 32, 256, 256, 128},
+		// Textures at least as large as a preview are kept at their size.
+		{512, 256, 512, 256, 256},
+	}
+	for _, tt := range tests {
+		data, err := makePreview(testSkin(t, tt.w, tt.h))
+		if err != nil {
+			t.Fatalf("makePreview(%dx%d): %v", tt.w, tt.h, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+			t.Fatalf("preview of %dx%d is %dx%d, want %dx%d", tt.w, tt.h, b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+		}
+
+		// Pixels are repeated, not blended, so the halves stay sharp.
+		left := color.NRGBAModel.Convert(img.At(tt.wantBoundaryX-1, 0)).(color.NRGBA)
+		right := color.NRGBAModel.Convert(img.At(tt.wantBoundaryX, 0)).(color.NRGBA)
+		if left != (color.NRGBA{R: 255, A: 255}) || right != (color.NRGBA{B: 255, A: 255}) {
+			t.Errorf("preview of %dx%d has %v|%v at the boundary", tt.w, tt.h, left, right)
+		}
+	}
+
+	if _, err := makePreview([]byte("not a png")); err == nil {
+		t.Error("makePreview accepted invalid data")
+	}
+}
+
+func TestLibrary(t *testing.T) {
+	src := t.TempDir()
+	skinPath := filepath.Join(src, "steve.png")
+	if err := os.WriteFile(skinPath, testSkin(t, 64, 64), 0644); err != nil {
+		t.Fatal(err)
+	}
+	presetPath := filepath.Join(src, "outfit.json")
+	if err := os.WriteFile(presetPath, []byte(`{"hair": "braids"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	badPath := filepath.Join(src, "bad.png")
+	if err := os.WriteFile(badPath, testSkin(t, 64, 48), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLibrary(filepath.Join(t.TempDir(), "skins"))
+	skin, err := l.Import(skinPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skin.Name != "steve" || skin.Kind != KindSkin {
+		t.Errorf("imported skin = %+v", skin)
+	}
+	again, err := l.Import(skinPath, "other name")
+	if err != nil || again.ID != skin.ID || again.Name != "steve" {
+		t.Errorf("re-import = %+v, %v, want the existing item", again, err)
+	}
+	preset, err := l.Import(presetPath, "Outfit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Import(badPath, ""); err == nil {
+		t.Error("imported an invalid skin")
+	}
+
+	if preview, err := l.Preview(skin.ID); err != nil || len(preview) == 0 {
+		t.Errorf("skin preview = %d bytes, %v", len(preview), err)
+	}
+	if preview, err := l.Preview(preset.ID); err != nil || preview != nil {
+		t.Errorf("preset preview = %d bytes, %v, want none", len(preview), err)
+	}
+
+	// The index survives a reload.
+	reloaded := NewLibrary(l.dir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.List(); len(got) != 2 || got[0].Name != "Outfit" || got[1].Name != "steve" {
+		t.Fatalf("reloaded list = %+v", got)
+	}
+
+	if err := l.Delete(skin.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Get(skin.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if _, err := os.Stat(l.previewPath(skin.ID)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("preview left after Delete: %v", err)
+	}
+	if err := l.Delete(skin.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,209 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Create snapshots the files under src and records them as source. Contents
+// already stored by an earlier snapshot are not stored again. If nothing
+// changed since the newest snapshot of source, that snapshot is returned
+// instead of a new one. A missing src returns nil.
+func (s *Store) Create(src, source, reason, gameVersion string) (*Snapshot, error) {
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snaps, err := s.loadSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reuse hashes of files whose size and modification time are unchanged.
+	known := make(map[string]File)
+	if len(snaps) > 0 {
+		for _, f := range snaps[0].Files {
+			known[f.Path] = f
+		}
+	}
+
+	var files []File
+	err = filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if isExcluded(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		f := File{Path: rel, Size: info.Size(), ModTime: info.ModTime().UTC()}
+		if prev, ok := known[rel]; ok && prev.Size == f.Size && prev.ModTime.Equal(f.ModTime) {
+			f.Hash = prev.Hash
+		} else if f.Hash, err = hashFile(p); err != nil {
+			return err
+		}
+
+		if err := s.storeObject(p, f.Hash); err != nil {
+			return fmt.Errorf("failed to store %s: %w", rel, err)
+		}
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot user data: %w", err)
+	}
+
+	if len(snaps) > 0 && sameFiles(snaps[0].Files, files) {
+		return snaps[0], nil
+	}
+
+	now := time.Now()
+	snap := &Snapshot{
+		ID:          newID(now),
+		CreatedAt:   now.UTC(),
+		Reason:      reason,
+		Source:      source,
+		GameVersion: gameVersion,
+		FileCount:   len(files),
+		Worlds:      worldsIn(files),
+		Files:       files,
+	}
+	for _, f := range files {
+		snap.Size += f.Size
+	}
+
+	if err := s.writeManifest(snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// sameFiles reports whether two file lists have the same paths and contents.
+func sameFiles(a, b []File) bool {
+	return slices.EqualFunc(a, b, func(x, y File) bool {
+		return x.Path == y.Path && x.Hash == y.Hash
+	})
+}
+
+// writeManifest writes a snapshot manifest.
+func (s *Store) writeManifest(snap *Snapshot) error {
+	if err := os.MkdirAll(s.indexDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot index: %w", err)
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	tmp := s.manifestPath(snap.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return os.Rename(tmp, s.manifestPath(snap.ID))
+}
+
+// storeObject copies a file into the object store unless its hash is
+// already present.
+func (s *Store) storeObject(src, hash string) error {
+	dst := s.objectPath(hash)
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	tmp := dst + ".tmp"
+	if err := copyFile(src, tmp, time.Time{}); err != nil {
+		return err
+	}
+
+	// The file may have changed since it was hashed; keep only a matching copy.
+	if actual, err := hashFile(tmp); err != nil || actual != hash {
+		os.Remove(tmp)
+		return fmt.Errorf("file changed while it was being copied")
+	}
+	return os.Rename(tmp, dst)
+}
+
+// copyFile copies src to dst, syncing it and setting its modification time
+// if modTime is non-zero.
+func copyFile(src, dst string, modTime time.Time) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	if !modTime.IsZero() {
+		return os.Chtimes(dst, modTime, modTime)
+	}
+	return nil
+}
+
+// hashFile returns the hex SHA-256 of a file.
+func hashFile(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,107 @@
+package snapshot
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Restore writes a snapshot back to dst. If world is non-empty only that
+// world is restored; otherwise all snapshotted files are. Files in the
+// restored scope that are not in the snapshot are deleted. Excluded
+// directories such as Logs are never touched.
+func (s *Store) Restore(id, dst, world string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap, err := s.load(id)
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	if world != "" {
+		if !slices.Contains(snap.Worlds, world) {
+			return fmt.Errorf("world %q is not in snapshot %s", world, id)
+		}
+		prefix = worldPrefix(world)
+	}
+
+	var files []File
+	for _, f := range snap.Files {
+		if strings.HasPrefix(f.Path, prefix) {
+			files = append(files, f)
+		}
+	}
+
+	// Make sure every object is present before changing anything.
+	for _, f := range files {
+		if _, err := os.Stat(s.objectPath(f.Hash)); err != nil {
+			return fmt.Errorf("snapshot %s is incomplete: missing contents of %s", id, f.Path)
+		}
+	}
+
+	wanted := make(map[string]bool, len(files))
+	for _, f := range files {
+		wanted[f.Path] = true
+
+		target := filepath.Join(dst, filepath.FromSlash(f.Path))
+		if current, err := hashFile(target); err == nil && current == f.Hash {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		tmp := target + ".restore"
+		if err := copyFile(s.objectPath(f.Hash), tmp, f.ModTime); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", f.Path, err)
+		}
+		if err := os.Rename(tmp, target); err != nil {
+			os.Remove(tmp)
+			return fmt.Errorf("failed to restore %s: %w", f.Path, err)
+		}
+	}
+
+	return removeExtra(dst, prefix, wanted)
+}
+
+// removeExtra deletes files under dst within prefix that are not wanted.
+func removeExtra(dst, prefix string, wanted map[string]bool) error {
+	root := filepath.Join(dst, filepath.FromSlash(prefix))
+
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		rel, err := filepath.Rel(dst, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if isExcluded(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || wanted[rel] {
+			return nil
+		}
+
+		if err := os.Remove(p); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", rel, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,346 @@
+// Package snapshot takes deduplicated snapshots of the client's UserData
+// directory and restores them. File contents are stored once by SHA-256, so
+// unchanged files cost no extra disk space across snapshots.
+package snapshot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reasons a snapshot was taken.
+const (
+	ReasonLaunch  = "launch"
+	ReasonUpdate  = "update"
+	ReasonRestore = "restore"
+	ReasonImport  = "import"
+	ReasonManual  = "manual"
+)
+
+// SavesDir is the UserData subdirectory holding one directory per world.
+const SavesDir = "Saves"
+
+// excluded lists top-level UserData entries that are not snapshotted:
+// logs are not user data and mods are redeployed from the mod library.
+var excluded = []string{"Logs", "Mods"}
+
+// ErrNotFound is returned when a snapshot does not exist.
+var ErrNotFound = errors.New("snapshot not found")
+
+// File is a file recorded in a snapshot.
+type File struct {
+	// Path is the slash-separated path relative to UserData.
+	Path string `json:"path"`
+
+	// Hash is the SHA-256 of the contents and the object key.
+	Hash string `json:"hash"`
+
+	// Size is the file size in bytes.
+	Size int64 `json:"size"`
+
+	// ModTime is the file's modification time.
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Snapshot describes a snapshot of UserData.
+type Snapshot struct {
+	// ID identifies the snapshot.
+	ID string `json:"id"`
+
+	// CreatedAt is when the snapshot was taken.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Reason is why the snapshot was taken.
+	Reason string `json:"reason"`
+
+	// Source identifies the directory that was snapshotted, as given to
+	// Create. Empty is the shared UserData directory.
+	Source string `json:"source,omitempty"`
+
+	// GameVersion is the game version installed at the time.
+	GameVersion string `json:"game_version,omitempty"`
+
+	// Size is the total size of the snapshotted files in bytes.
+	Size int64 `json:"size"`
+
+	// FileCount is the number of files in the snapshot.
+	FileCount int `json:"file_count"`
+
+	// Worlds are the world names in the snapshot.
+	Worlds []string `json:"worlds"`
+
+	// Files are the snapshotted files. Omitted when listing.
+	Files []File `json:"files,omitempty"`
+}
+
+// Filter selects snapshots when listing. Zero fields match everything.
+type Filter struct {
+	// Source matches snapshots of this source when set.
+	Source *string `json:"source,omitempty"`
+
+	// GameVersion matches snapshots taken with this game version.
+	GameVersion string `json:"gameVersion,omitempty"`
+
+	// From and To bound the creation time.
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+// matches reports whether s passes the filter.
+func (f Filter) matches(s *Snapshot) bool {
+	if f.Source != nil && s.Source != *f.Source {
+		return false
+	}
+	if f.GameVersion != "" && s.GameVersion != f.GameVersion {
+		return false
+	}
+	if f.From != nil && s.CreatedAt.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && s.CreatedAt.After(*f.To) {
+		return false
+	}
+	return true
+}
+
+// Store keeps snapshots and their deduplicated file objects in a directory.
+type Store struct {
+	dir string
+
+	mu sync.Mutex
+}
+
+// NewStore creates a Store in dir.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// indexDir returns the directory holding one manifest per snapshot.
+func (s *Store) indexDir() string {
+	return filepath.Join(s.dir, "index")
+}
+
+// manifestPath returns the manifest file of a snapshot.
+func (s *Store) manifestPath(id string) string {
+	return filepath.Join(s.indexDir(), id+".json")
+}
+
+// objectPath returns the object file for a content hash.
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.dir, "objects", hash[:2], hash)
+}
+
+// newID returns a sortable, unique snapshot ID.
+func newID(t time.Time) string {
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	return t.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// validID reports whether id is safe to use as a file name.
+func validID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\.:`)
+}
+
+// load reads a snapshot manifest.
+func (s *Store) load(id string) (*Snapshot, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+
+	data, err := os.ReadFile(s.manifestPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", id, err)
+	}
+	return &snap, nil
+}
+
+// loadAll reads every snapshot manifest, newest first.
+func (s *Store) loadAll() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(s.indexDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshots: %w", err)
+	}
+
+	var snaps []*Snapshot
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok {
+			continue
+		}
+		snap, err := s.load(id)
+		if err != nil {
+			slog.Warn("skipping unreadable snapshot", "id", id, "error", err)
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].CreatedAt.After(snaps[j].CreatedAt)
+	})
+	return snaps, nil
+}
+
+// loadSource reads the snapshot manifests of source, newest first.
+func (s *Store) loadSource(source string) ([]*Snapshot, error) {
+	snaps, err := s.loadAll()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(snaps, func(snap *Snapshot) bool {
+		return snap.Source != source
+	}), nil
+}
+
+// List returns the snapshots matching filter, newest first, without their
+// file lists.
+func (s *Store) List(filter Filter) ([]Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snaps, err := s.loadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Snapshot
+	for _, snap := range snaps {
+		if filter.matches(snap) {
+			summary := *snap
+			summary.Files = nil
+			result = append(result, summary)
+		}
+	}
+	return result, nil
+}
+
+// Get returns a snapshot including its file list.
+func (s *Store) Get(id string) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(id)
+}
+
+// Delete removes a snapshot and any objects no other snapshot uses.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.load(id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.manifestPath(id)); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return s.collectLocked()
+}
+
+// Prune deletes all but the newest keep snapshots of source and returns how
+// many were deleted.
+func (s *Store) Prune(source string, keep int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snaps, err := s.loadSource(source)
+	if err != nil {
+		return 0, err
+	}
+	if len(snaps) <= keep {
+		return 0, nil
+	}
+
+	var removed int
+	for _, snap := range snaps[keep:] {
+		if err := os.Remove(s.manifestPath(snap.ID)); err != nil {
+			return removed, fmt.Errorf("failed to delete snapshot %s: %w", snap.ID, err)
+		}
+		removed++
+	}
+	return removed, s.collectLocked()
+}
+
+// collectLocked deletes objects that no snapshot references.
+// Caller must hold s.mu.
+func (s *Store) collectLocked() error {
+	snaps, err := s.loadAll()
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]bool)
+	for _, snap := range snaps {
+		for _, f := range snap.Files {
+			used[f.Hash] = true
+		}
+	}
+
+	objects := filepath.Join(s.dir, "objects")
+	return filepath.WalkDir(objects, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || used[d.Name()] {
+			return nil
+		}
+		if err := os.Remove(p); err != nil {
+			slog.Warn("failed to delete unused snapshot object", "path", p, "error", err)
+		}
+		return nil
+	})
+}
+
+// worldsIn returns the world names found in a file list.
+func worldsIn(files []File) []string {
+	var worlds []string
+	for _, f := range files {
+		rest, ok := strings.CutPrefix(f.Path, SavesDir+"/")
+		if !ok {
+			continue
+		}
+		world, _, nested := strings.Cut(rest, "/")
+		if nested && !slices.Contains(worlds, world) {
+			worlds = append(worlds, world)
+		}
+	}
+	slices.Sort(worlds)
+	return worlds
+}
+
+// isExcluded reports whether a UserData-relative path is not snapshotted.
+func isExcluded(rel string) bool {
+	top, _, _ := strings.Cut(rel, "/")
+	return slices.Contains(excluded, top)
+}
+
+// worldPrefix returns the path prefix of a world's files.
+func worldPrefix(world string) string {
+	return path.Join(SavesDir, world) + "/"
+}
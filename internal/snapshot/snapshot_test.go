@@ -0,0 +1,252 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeFiles writes files, keyed by slash-separated path, under dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readFile returns the contents of a slash-separated path under dir, or ""
+// if it does not exist.
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// countObjects returns the number of stored file objects.
+func countObjects(t *testing.T, s *Store) int {
+	t.Helper()
+	var n int
+	filepath.WalkDir(filepath.Join(s.dir, "objects"), func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			n++
+		}
+		return nil
+	})
+	return n
+}
+
+// setup returns a store and a UserData directory with two worlds, settings
+// and logs.
+func setup(t *testing.T) (*Store, string) {
+	t.Helper()
+	root := t.TempDir()
+	userData := filepath.Join(root, "UserData")
+	writeFiles(t, userData, map[string]string{
+		"Settings.json":           "settings",
+		"Saves/Alpha/level.json":  "alpha",
+		"Saves/Beta/level.json":   "beta",
+		"Saves/Beta/region/0.bin": "alpha",
+		"Logs/client.log":         "log",
+		"Mods/example.jar":        "mod",
+	})
+	return NewStore(filepath.Join(root, "snapshots")), userData
+}
+
+func TestCreate(t *testing.T) {
+	s, userData := setup(t)
+
+	snap, err := s.Create(userData, "", ReasonManual, "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.FileCount != 4 {
+		t.Fatalf("file count = %d, want 4 without logs and mods", snap.FileCount)
+	}
+	if !slices.Equal(snap.Worlds, []string{"Alpha", "Beta"}) {
+		t.Fatalf("worlds = %v", snap.Worlds)
+	}
+	// "alpha" is stored once although two files hold it.
+	if n := countObjects(t, s); n != 3 {
+		t.Fatalf("objects = %d, want 3", n)
+	}
+
+	if snap, err := s.Create(filepath.Join(t.TempDir(), "missing"), "", ReasonManual, "1.0"); snap != nil || err != nil {
+		t.Fatalf("Create of missing dir = %v, %v", snap, err)
+	}
+}
+
+func TestCreateDeduplicates(t *testing.T) {
+	s, userData := setup(t)
+
+	first, err := s.Create(userData, "", ReasonLaunch, "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := s.Create(userData, "", ReasonLaunch, "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != first.ID {
+		t.Fatalf("unchanged UserData got a new snapshot %s", again.ID)
+	}
+
+	writeFiles(t, userData, map[string]string{"Saves/Alpha/level.json": "alpha v2"})
+	changed, err := s.Create(userData, "", ReasonLaunch, "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.ID == first.ID {
+		t.Fatal("changed UserData did not get a new snapshot")
+	}
+	if n := countObjects(t, s); n != 4 {
+		t.Fatalf("objects = %d, want only the changed file added", n)
+	}
+
+	// Another source is compared against its own snapshots.
+	other, err := s.Create(userData, "profiles/alex/UserData", ReasonLaunch, "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.ID == changed.ID || other.Source != "profiles/alex/UserData" {
+		t.Fatalf("other source snapshot = %+v", other)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	s, userData := setup(t)
+
+	var ids []string
+	for i := range 3 {
+		writeFiles(t, userData, map[string]string{"Settings.json": string(rune('a' + i))})
+		snap, err := s.Create(userData, "", ReasonLaunch, "1.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, snap.ID)
+		time.Sleep(10 * time.Millisecond)
+	}
+	other, err := s.Create(userData, "other", ReasonLaunch, "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := s.Prune("", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Fatalf("removed = %d, want 1", removed)
+	}
+	if _, err := s.Get(ids[0]); err != ErrNotFound {
+		t.Fatalf("oldest snapshot still exists: %v", err)
+	}
+	for _, id := range []string{ids[1], ids[2], other.ID} {
+		if _, err := s.Get(id); err != nil {
+			t.Fatalf("snapshot %s was pruned: %v", id, err)
+		}
+	}
+
+	// The contents only the pruned snapshot used are collected.
+	snap, _ := s.Get(ids[0+1])
+	for _, f := range snap.Files {
+		if _, err := os.Stat(s.objectPath(f.Hash)); err != nil {
+			t.Fatalf("object of %s was collected: %v", f.Path, err)
+		}
+	}
+	if n := countObjects(t, s); n != 4 {
+		t.Fatalf("objects = %d, want 4 after collecting the pruned settings", n)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	s, userData := setup(t)
+	snap, err := s.Create(userData, "", ReasonManual, "1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFiles(t, userData, map[string]string{
+		"Settings.json":          "changed",
+		"Saves/Alpha/level.json": "changed",
+		"Saves/Alpha/extra.json": "new",
+		"Logs/client.log":        "newer log",
+	})
+	os.RemoveAll(filepath.Join(userData, "Saves", "Beta"))
+
+	// Restoring one world leaves everything else alone.
+	if err := s.Restore(snap.ID, userData, "Alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, userData, "Saves/Alpha/level.json"); got != "alpha" {
+		t.Fatalf("Alpha level = %q", got)
+	}
+	if got := readFile(t, userData, "Saves/Alpha/extra.json"); got != "" {
+		t.Fatal("file added to Alpha after the snapshot was kept")
+	}
+	if got := readFile(t, userData, "Settings.json"); got != "changed" {
+		t.Fatalf("settings = %q, want untouched by a world restore", got)
+	}
+
+	if err := s.Restore(snap.ID, userData, ""); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"Settings.json":           "settings",
+		"Saves/Beta/level.json":   "beta",
+		"Saves/Beta/region/0.bin": "alpha",
+		"Logs/client.log":         "newer log",
+		"Mods/example.jar":        "mod",
+	} {
+		if got := readFile(t, userData, name); got != want {
+			t.Fatalf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	if err := s.Restore(snap.ID, userData, "Gamma"); err == nil {
+		t.Fatal("restored a world that is not in the snapshot")
+	}
+}
+
+func TestListFilter(t *testing.T) {
+	s, userData := setup(t)
+	if _, err := s.Create(userData, "", ReasonManual, "1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create(userData, "other", ReasonManual, "2.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	shared := ""
+	for _, tt := range []struct {
+		filter Filter
+		want   int
+	}{
+		{Filter{}, 2},
+		{Filter{Source: &shared}, 1},
+		{Filter{GameVersion: "2.0"}, 1},
+	} {
+		list, err := s.List(tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != tt.want {
+			t.Fatalf("List(%+v) = %d snapshots, want %d", tt.filter, len(list), tt.want)
+		}
+		for _, snap := range list {
+			if snap.Files != nil {
+				t.Fatal("List returned file lists")
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package worlds
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"hytale-launcher/internal/extract"
+)
+
+// Paths inside a world archive.
+const (
+	infoName     = "world.json"
+	worldDirName = "world"
+)
+
+// Info is the metadata stored in a world archive.
+type Info struct {
+	// Name is the world's name when it was exported.
+	Name string `json:"name"`
+
+	// Source is where the world was exported from ("client" or "server").
+	Source string `json:"source"`
+
+	// GameVersion is the game version installed when the world was exported.
+	GameVersion string `json:"game_version,omitempty"`
+
+	// ExportedAt is when the world was exported.
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// Export writes a world to a portable .zip archive at dest.
+func Export(w World, gameVersion, dest string) error {
+	tmp := dest + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create world archive: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	info := Info{
+		Name:        w.Name,
+		Source:      w.Target,
+		GameVersion: gameVersion,
+		ExportedAt:  time.Now().UTC(),
+	}
+	if err := writeArchive(f, w.Path, info); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// writeArchive writes the world info and files as a zip archive.
+func writeArchive(out io.Writer, dir string, info Info) error {
+	zw := zip.NewWriter(out)
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	iw, err := zw.Create(infoName)
+	if err != nil {
+		return err
+	}
+	if _, err := iw.Write(data); err != nil {
+		return err
+	}
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(worldDirName, filepath.ToSlash(rel))
+		header.Method = zip.Deflate
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(w, in)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive world: %w", err)
+	}
+
+	return zw.Close()
+}
+
+// ReadInfo returns the metadata of a world archive without extracting it.
+func ReadInfo(archivePath string) (*Info, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open world archive: %w", err)
+	}
+	defer r.Close()
+
+	f, err := r.Open(infoName)
+	if err != nil {
+		return nil, errors.New("archive is not a world export: missing " + infoName)
+	}
+	defer f.Close()
+
+	var info Info
+	if err := json.NewDecoder(f).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to parse world info: %w", err)
+	}
+	return &info, nil
+}
+
+// Import extracts a world archive into root. An empty name uses the name
+// stored in the archive. It returns the name used after applying the
+// collision policy.
+func Import(archivePath, root, name, policy string) (string, error) {
+	info, err := ReadInfo(archivePath)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = info.Name
+	}
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", err
+	}
+	staging, err := os.MkdirTemp(root, ".import-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(staging)
+
+	extracted := filepath.Join(staging, "archive")
+	if err := extract.Archive(archivePath, extracted, nil, nil); err != nil {
+		return "", fmt.Errorf("failed to extract world: %w", err)
+	}
+
+	world := filepath.Join(extracted, worldDirName)
+	if fi, err := os.Stat(world); err != nil || !fi.IsDir() {
+		return "", errors.New("world archive contains no world files")
+	}
+	return place(world, root, name, policy)
+}
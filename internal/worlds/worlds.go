@@ -0,0 +1,254 @@
+// Package worlds lists, copies, exports and imports game worlds between the
+// client's UserData and the local server directory.
+package worlds
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Locations a world can live in.
+const (
+	TargetClient = "client"
+	TargetServer = "server"
+)
+
+// Collision policies decide what happens when a world name is already taken.
+const (
+	// CollisionFail refuses the import.
+	CollisionFail = "fail"
+
+	// CollisionRename imports under a free name such as "World (2)".
+	CollisionRename = "rename"
+
+	// CollisionOverwrite replaces the existing world.
+	CollisionOverwrite = "overwrite"
+)
+
+// maxNameLength is the longest accepted world name.
+const maxNameLength = 64
+
+// ErrExists is returned when a world name is taken and the policy is CollisionFail.
+var ErrExists = errors.New("a world with this name already exists")
+
+// World describes a world directory.
+type World struct {
+	// Name is the world's directory name.
+	Name string `json:"name"`
+
+	// Target is where the world lives ("client" or "server").
+	Target string `json:"target"`
+
+	// Profile is the player profile whose own user directory holds a client
+	// world. Empty for the shared UserData directory and the server.
+	Profile string `json:"profile,omitempty"`
+
+	// Path is the world's directory.
+	Path string `json:"path"`
+
+	// Size is the total size of the world's files in bytes.
+	Size int64 `json:"size"`
+
+	// ModifiedAt is the newest modification time of the world's files.
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// ValidateName checks that name can be used as a world directory name.
+func ValidateName(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return errors.New("world name is required")
+	case len(name) > maxNameLength:
+		return fmt.Errorf("world name must be at most %d characters", maxNameLength)
+	case name == "." || name == ".." || strings.HasPrefix(name, "."):
+		return errors.New("world name cannot start with a dot")
+	case strings.ContainsAny(name, `/\:*?"<>|`):
+		return errors.New(`world name cannot contain any of / \ : * ? " < > |`)
+	}
+	return nil
+}
+
+// List returns the worlds in root for target, sorted by name. A missing root
+// has no worlds.
+func List(target, root string) ([]World, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worlds: %w", err)
+	}
+
+	var worlds []World
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		w := World{Name: e.Name(), Target: target, Path: filepath.Join(root, e.Name())}
+		if err := measure(&w); err != nil {
+			return nil, fmt.Errorf("failed to read world %s: %w", w.Name, err)
+		}
+		worlds = append(worlds, w)
+	}
+
+	sort.Slice(worlds, func(i, j int) bool {
+		return strings.ToLower(worlds[i].Name) < strings.ToLower(worlds[j].Name)
+	})
+	return worlds, nil
+}
+
+// measure fills in a world's size and modification time.
+func measure(w *World) error {
+	return filepath.WalkDir(w.Path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		w.Size += info.Size()
+		if info.ModTime().After(w.ModifiedAt) {
+			w.ModifiedAt = info.ModTime()
+		}
+		return nil
+	})
+}
+
+// freeName returns name, or the first "name (N)" not present in root.
+func freeName(root, name string) string {
+	candidate := name
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(root, candidate)); errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s (%d)", name, i)
+	}
+}
+
+// place moves the world directory staged at src into root under name,
+// applying the collision policy. An empty policy means CollisionFail.
+// It returns the name used.
+func place(src, root, name, policy string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+	switch policy {
+	case "", CollisionFail, CollisionRename, CollisionOverwrite:
+	default:
+		return "", fmt.Errorf("unknown collision policy %q", policy)
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", err
+	}
+
+	dst := filepath.Join(root, name)
+	_, err := os.Stat(dst)
+	exists := err == nil
+
+	switch {
+	case !exists:
+	case policy == CollisionRename:
+		name = freeName(root, name)
+		dst = filepath.Join(root, name)
+	case policy == CollisionOverwrite:
+		old := filepath.Join(root, "."+name+".replaced")
+		os.RemoveAll(old)
+		if err := os.Rename(dst, old); err != nil {
+			return "", fmt.Errorf("failed to move existing world aside: %w", err)
+		}
+		if err := os.Rename(src, dst); err != nil {
+			os.Rename(old, dst)
+			return "", fmt.Errorf("failed to move world into place: %w", err)
+		}
+		if err := os.RemoveAll(old); err != nil {
+			return name, fmt.Errorf("world replaced, but the old copy could not be removed: %w", err)
+		}
+		return name, nil
+	default:
+		return "", ErrExists
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		return "", fmt.Errorf("failed to move world into place: %w", err)
+	}
+	return name, nil
+}
+
+// Copy copies the world at src into root under name, applying the collision
+// policy. It returns the name used.
+func Copy(src, root, name, policy string) (string, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", err
+	}
+
+	staging, err := os.MkdirTemp(root, ".import-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(staging)
+
+	staged := filepath.Join(staging, "world")
+	if err := copyDir(src, staged); err != nil {
+		return "", fmt.Errorf("failed to copy world: %w", err)
+	}
+	return place(staged, root, name, policy)
+}
+
+// copyDir copies the directory tree at src to dst.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(p, target)
+	})
+}
+
+// copyFile copies a regular file, keeping its modification time.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
@@ -0,0 +1,224 @@
+package worlds
+
+import (
+	"archive/zip"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeWorld creates a world directory named name under root holding files.
+func writeWorld(t *testing.T, root, name string, files map[string]string) string {
+	t.Helper()
+
+	dir := filepath.Join(root, name)
+	for rel, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// readWorld returns the files of the world directory at dir.
+func readWorld(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// checkClean fails if root holds anything but the named worlds, such as
+// staging directories or worlds moved aside.
+func checkClean(t *testing.T, root string, names ...string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	if strings.Join(got, ",") != strings.Join(names, ",") {
+		t.Errorf("%s holds %v, want %v", root, got, names)
+	}
+}
+
+var (
+	original    = map[string]string{"level.dat": "original", "region/r.0.0": "land"}
+	replacement = map[string]string{"level.dat": "replacement"}
+)
+
+func TestCopyCollisionPolicies(t *testing.T) {
+	tests := []struct {
+		policy   string
+		wantName string
+		wantErr  error
+		want     map[string]string
+		worlds   []string
+	}{
+		{"", "", ErrExists, original, []string{"World"}},
+		{CollisionFail, "", ErrExists, original, []string{"World"}},
+		{CollisionRename, "World (3)", nil, original, []string{"World", "World (2)", "World (3)"}},
+		{CollisionOverwrite, "World", nil, replacement, []string{"World"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			src := writeWorld(t, t.TempDir(), "Source", replacement)
+			root := t.TempDir()
+			existing := writeWorld(t, root, "World", original)
+			if tt.policy == CollisionRename {
+				writeWorld(t, root, "World (2)", original)
+			}
+
+			name, err := Copy(src, root, "World", tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Copy = %q, %v, want %v", name, err, tt.wantErr)
+			}
+			if name != tt.wantName {
+				t.Errorf("name = %q, want %q", name, tt.wantName)
+			}
+
+			if got := readWorld(t, existing); !maps.Equal(got, tt.want) {
+				t.Errorf("World = %v, want %v", got, tt.want)
+			}
+			if tt.policy == CollisionRename {
+				if got := readWorld(t, filepath.Join(root, name)); !maps.Equal(got, replacement) {
+					t.Errorf("%s = %v, want the copy", name, got)
+				}
+			}
+			checkClean(t, root, tt.worlds...)
+
+			// The source is left alone.
+			if got := readWorld(t, src); !maps.Equal(got, replacement) {
+				t.Errorf("source = %v", got)
+			}
+		})
+	}
+}
+
+func TestCopyRejectsBadInput(t *testing.T) {
+	src := writeWorld(t, t.TempDir(), "Source", replacement)
+	root := t.TempDir()
+
+	if _, err := Copy(src, root, "../escape", CollisionFail); err == nil {
+		t.Error("copied under an invalid name")
+	}
+	if _, err := Copy(src, root, "World", "merge"); err == nil {
+		t.Error("copied with an unknown policy")
+	}
+	if _, err := Copy(filepath.Join(src, "missing"), root, "World", CollisionFail); err == nil {
+		t.Error("copied a missing world")
+	}
+	checkClean(t, root)
+}
+
+func TestOverwriteRestoresWorldOnFailure(t *testing.T) {
+	root := t.TempDir()
+	existing := writeWorld(t, root, "World", original)
+
+	// The staged world is gone by the time it is moved into place.
+	if _, err := place(filepath.Join(t.TempDir(), "missing"), root, "World", CollisionOverwrite); err == nil {
+		t.Fatal("place succeeded without a staged world")
+	}
+
+	if got := readWorld(t, existing); !maps.Equal(got, original) {
+		t.Errorf("World = %v, want it restored", got)
+	}
+	checkClean(t, root, "World")
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := writeWorld(t, t.TempDir(), "Adventure", original)
+
+	worlds, err := List(TargetClient, filepath.Dir(src))
+	if err != nil || len(worlds) != 1 {
+		t.Fatalf("List = %v, %v", worlds, err)
+	}
+	if w := worlds[0]; w.Size != int64(len("original")+len("land")) {
+		t.Errorf("size = %d", w.Size)
+	}
+
+	archive := filepath.Join(t.TempDir(), "adventure.zip")
+	if err := Export(worlds[0], "1.2.3", archive); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	info, err := ReadInfo(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "Adventure" || info.Source != TargetClient || info.GameVersion != "1.2.3" {
+		t.Errorf("info = %+v", info)
+	}
+
+	root := t.TempDir()
+	name, err := Import(archive, root, "", CollisionFail)
+	if err != nil || name != "Adventure" {
+		t.Fatalf("Import = %q, %v", name, err)
+	}
+	imported := filepath.Join(root, name)
+	if got := readWorld(t, imported); !maps.Equal(got, original) {
+		t.Errorf("imported world = %v, want %v", got, original)
+	}
+
+	// A second import needs a policy for the taken name.
+	if _, err := Import(archive, root, "", CollisionFail); !errors.Is(err, ErrExists) {
+		t.Errorf("second Import = %v, want ErrExists", err)
+	}
+	if name, err := Import(archive, root, "", CollisionRename); err != nil || name != "Adventure (2)" {
+		t.Errorf("renamed Import = %q, %v", name, err)
+	}
+	if name, err := Import(archive, root, "Copy", CollisionFail); err != nil || name != "Copy" {
+		t.Errorf("named Import = %q, %v", name, err)
+	}
+	checkClean(t, root, "Adventure", "Adventure (2)", "Copy")
+}
+
+func TestImportRejectsArchivesWithoutWorld(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "empty.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(infoName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`{"name": "Empty", "source": "client"}`))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	root := t.TempDir()
+	if _, err := Import(archive, root, "", CollisionFail); err == nil {
+		t.Fatal("imported an archive without world files")
+	}
+	checkClean(t, root)
+}
@@ -16,13 +16,21 @@ import (
 
 	"hytale-launcher/internal/app"
 	"hytale-launcher/internal/build"
+	"hytale-launcher/internal/hytale"
+	"hytale-launcher/internal/keyring"
 	"hytale-launcher/internal/logging"
+	"hytale-launcher/internal/relocate"
 )
 
 //go:embed frontend/dist
 var assets embed.FS
 
 func main() {
+	// Finish or roll back a storage relocation from the previous run. This
+	// must happen before anything resolves the storage directory, which
+	// logging does.
+	relocateErr := relocate.Recover()
+
 	// Initialize logging
 	logging.Init()
 
@@ -33,6 +41,16 @@ func main() {
 		"arch", build.Arch(),
 	)
 
+	if relocateErr != nil {
+		slog.Error("failed to recover storage relocation", "error", relocateErr)
+	}
+
+	// In portable mode, keep secrets in an encrypted file next to the data
+	// instead of the OS keyring of whichever machine the launcher runs on.
+	if hytale.Portable() {
+		keyring.UseFile(hytale.InStorageDir("secrets.json"))
+	}
+
 	// Create the application instance
 	application := app.New()
 
@@ -51,6 +69,8 @@ func main() {
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
 		OnStartup:        application.Startup,
 		OnDomReady:       application.DomReady,
+		OnBeforeClose:    application.BeforeClose,
+		OnShutdown:       application.Shutdown,
 		Bind: []interface{}{
 			application,
 		},